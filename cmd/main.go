@@ -2,10 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/IBM/sarama"
 	"github.com/gin-gonic/gin"
@@ -18,6 +23,7 @@ import (
 	"github.com/Gopher0727/ChatRoom/internal/api"
 	"github.com/Gopher0727/ChatRoom/internal/handler"
 	"github.com/Gopher0727/ChatRoom/internal/model"
+	"github.com/Gopher0727/ChatRoom/internal/pkg/blob"
 	"github.com/Gopher0727/ChatRoom/internal/pkg/gateway"
 	grpcSrv "github.com/Gopher0727/ChatRoom/internal/pkg/grpc"
 	"github.com/Gopher0727/ChatRoom/internal/pkg/kafka"
@@ -57,21 +63,28 @@ func main() {
 		&model.User{},
 		&model.Guild{},
 		&model.GuildMember{},
+		&model.Channel{},
+		&model.DMConversation{},
+		&model.DMParticipant{},
+		&model.Pin{},
 		&model.Message{},
+		&model.Reaction{},
+		&model.Mention{},
+		&model.Attachment{},
+		&model.ReadState{},
 	); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
+	// 全文检索索引无法通过结构体标签声明
+	if err := repository.MigrateMessageSearch(db); err != nil {
+		log.Fatalf("Failed to migrate message search index: %v", err)
+	}
 
 	// 初始化 Redis
 	redisClient, err := redis.NewClient(&cfg.Redis)
 	if err != nil {
 		log.Fatalf("redis 初始化失败: %v", err)
 	}
-	defer func() {
-		if err := redisClient.Close(); err != nil {
-			log.Printf("关闭 Redis 连接失败: %v", err)
-		}
-	}()
 
 	// 初始化 Snowflake
 	sfConfig := snowflake.Config{
@@ -88,55 +101,100 @@ func main() {
 	if err != nil {
 		log.Printf("Failed to init kafka producer: %v", err)
 	}
-	defer func() {
-		if kafkaProducer != nil {
-			kafkaProducer.Close()
-		}
-	}()
+
+	// 初始化附件存储
+	blobStore, err := blob.NewLocalStore(cfg.Attachment.StorageDir)
+	if err != nil {
+		log.Fatalf("Failed to init blob store: %v", err)
+	}
 
 	// 初始化仓储层
 	userRepo := repository.NewUserRepository(db)
 	guildRepo := repository.NewGuildRepository(db)
+	channelRepo := repository.NewChannelRepository(db)
 	messageRepo := repository.NewMessageRepository(db)
+	dmRepo := repository.NewDMRepository(db)
+	pinRepo := repository.NewPinRepository(db)
+	reactionRepo := repository.NewReactionRepository(db)
+	mentionRepo := repository.NewMentionRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	readStateRepo := repository.NewReadStateRepository(db)
 
 	// 初始化 Token Manager
 	tokenManager := jwt.NewTokenManager(cfg.JWT.Secret, cfg.JWT.ExpireHours, cfg.JWT.RefreshHours)
 
+	// 在线状态随 Gateway 连接更新，连接管理器先于其他服务创建
+	// 在线状态与会话数一起在心跳检查时续期，节点宕机后随 TTL 过期
+	presenceService := service.NewPresenceService(userRepo, guildRepo, redisClient, time.Duration(cfg.Websocket.HeartbeatInterval*2)*time.Second)
+
+	// Node ID generation (simple for now)
+	// TODO
+	nodeID := fmt.Sprintf("node-%d", cfg.Snowflake.WorkerID)
+
+	// 其他节点通过该地址推送事件，未配置时使用主机名
+	grpcAddress := fmt.Sprintf(":%d", cfg.GRPC.Port)
+	advertiseAddress := cfg.GRPC.AdvertiseAddress
+	if advertiseAddress == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			log.Fatalf("Failed to get hostname: %v", err)
+		}
+		advertiseAddress = fmt.Sprintf("%s:%d", hostname, cfg.GRPC.Port)
+	}
+
+	// 初始化 Gateway 连接管理器 (WebSocket)
+	ctx := context.Background()
+	connManager := gateway.NewConnectionManager(ctx, &cfg.Websocket, redisClient, presenceService, nodeID, advertiseAddress)
+
+	// 初始化 Gateway 节点路由：私信和用户级事件只推送到用户所在的节点，本节点的用户直接投递
+	gatewayPool := grpcSrv.NewGatewayPool(redisClient)
+	gatewayRouter := grpcSrv.NewGatewayRouter(redisClient, gatewayPool, connManager, nodeID)
+
 	// 初始化服务层
 	authService := service.NewAuthService(userRepo, tokenManager)
 	guildService := service.NewGuildService(guildRepo, userRepo)
-	messageService := service.NewMessageService(messageRepo, userRepo, guildService, sfGen, redisClient)
+	channelService := service.NewChannelService(channelRepo, guildRepo, guildService)
+	messageService := service.NewMessageService(messageRepo, userRepo, reactionRepo, channelRepo, dmRepo, mentionRepo, attachmentRepo, guildService, sfGen, redisClient, gatewayRouter)
+	reactionService := service.NewReactionService(reactionRepo, messageRepo, dmRepo, guildService, redisClient, gatewayRouter)
+	dmService := service.NewDMService(dmRepo, userRepo)
+	pinService := service.NewPinService(pinRepo, messageRepo, guildRepo, userRepo, reactionRepo, attachmentRepo, guildService, redisClient)
+	mentionService := service.NewMentionService(mentionRepo, userRepo, reactionRepo, attachmentRepo)
+	attachmentService := service.NewAttachmentService(attachmentRepo, messageRepo, dmRepo, guildService, blobStore, &cfg.Attachment)
+	readStateService := service.NewReadStateService(readStateRepo, mentionRepo, channelRepo, guildService, redisClient, gatewayRouter)
 
 	// 初始化处理器
 	authHandler := handler.NewAuthHandler(authService)
-	guildHandler := handler.NewGuildHandler(guildService)
+	guildHandler := handler.NewGuildHandler(guildService, readStateService)
+	channelHandler := handler.NewChannelHandler(channelService)
 	messageHandler := handler.NewMessageHandler(messageService)
+	reactionHandler := handler.NewReactionHandler(reactionService)
+	dmHandler := handler.NewDMHandler(dmService, messageService)
+	pinHandler := handler.NewPinHandler(pinService)
+	mentionHandler := handler.NewMentionHandler(mentionService)
+	attachmentHandler := handler.NewAttachmentHandler(attachmentService, int64(cfg.Attachment.MaxSizeMB)<<20)
 
-	// Node ID generation (simple for now)
-	// TODO
-	nodeID := fmt.Sprintf("node-%d", cfg.Snowflake.WorkerID)
+	// 初始化 Gateway 消息处理
+	gwMessageHandler := gateway.NewMessageHandler(ctx, connManager, kafkaProducer, redisClient, readStateService, presenceService, guildService, messageService, tokenManager, channelService, cfg)
 
-	// 初始化 Gateway (WebSocket)
-	ctx := context.Background()
-	connManager := gateway.NewConnectionManager(ctx, &cfg.Websocket, redisClient, nodeID)
-	gwMessageHandler := gateway.NewMessageHandler(ctx, connManager, kafkaProducer, redisClient, cfg)
+	// 已读位置实时写入 Redis，定期落库到 PostgreSQL，退出前再落库一次
+	flusherCtx, stopFlusher := context.WithCancel(ctx)
+	flusherDone := readStateService.StartFlusher(flusherCtx, 10*time.Second)
 
-	// Start Gateway Subscriber (Subscribe to all guilds using pattern)
-	if err := gwMessageHandler.StartSubscriber("guild:*"); err != nil {
+	// Start Gateway Subscriber (Subscribe to the guilds of local connections only)
+	if err := gwMessageHandler.StartGuildSubscriber(); err != nil {
 		log.Printf("Failed to start gateway subscriber: %v", err)
 	}
 
 	// 初始化 gRPC Server
-	grpcAddress := fmt.Sprintf(":%d", cfg.GRPC.Port)
 	baseGrpcServer, err := grpcSrv.NewServer(grpcAddress)
 	if err != nil {
 		log.Fatalf("Failed to init grpc server: %v", err)
 	}
 
-	gatewayServer := grpcSrv.NewGatewayServer(connManager, nodeID, grpcAddress)
+	gatewayServer := grpcSrv.NewGatewayServer(connManager, gwMessageHandler, gwMessageHandler, redisClient, nodeID, advertiseAddress)
 	guildServer := grpcSrv.NewGuildServer(guildRepo)
-	messageServer := grpcSrv.NewMessageServer(messageService)
-	userServer := grpcSrv.NewUserServer(userRepo)
+	messageServer := grpcSrv.NewMessageServer(messageService, reactionService, pinService)
+	userServer := grpcSrv.NewUserServer(userRepo, presenceService)
 
 	// Register Services
 	s := baseGrpcServer.GetServer()
@@ -159,8 +217,26 @@ func main() {
 			return fmt.Errorf("failed to unmarshal message: %w", err)
 		}
 
+		// 上行消息只携带附件 ID，元数据由 Service 加载
+		attachmentIDs := make([]string, len(wsMsg.Attachments))
+		for i, attachment := range wsMsg.Attachments {
+			attachmentIDs[i] = attachment.Id
+		}
+
 		// 调用 Service 处理消息 (持久化 + 推送 Redis)
-		_, err := messageService.SendMessage(ctx, wsMsg.UserId, wsMsg.GuildId, wsMsg.Content)
+		_, err := messageService.SendMessage(ctx, &service.SendMessageRequest{
+			UserID:    wsMsg.UserId,
+			GuildID:   wsMsg.GuildId,
+			ChannelID: wsMsg.ChannelId,
+			Content:   wsMsg.Content,
+			ReplyToID: wsMsg.ReplyToId,
+
+			AttachmentIDs:  attachmentIDs,
+			ConversationID: wsMsg.ConversationId,
+
+			// 客户端重试时 nonce 相同，Service 据此去重
+			Nonce: wsMsg.Nonce,
+		})
 		if err != nil {
 			log.Printf("Error processing message from kafka: %v", err)
 			return err
@@ -176,7 +252,6 @@ func main() {
 		if err := consumer.Start(ctx); err != nil {
 			log.Printf("Failed to start kafka consumer: %v", err)
 		}
-		defer consumer.Stop()
 	}
 
 	// 配置并创建 Gin 引擎
@@ -190,7 +265,7 @@ func main() {
 	r.StaticFile("/index.html", "./web/index.html")
 
 	// 设置 API 路由
-	api.RegisterRoutes(r, tokenManager, authHandler, guildHandler, messageHandler)
+	api.RegisterRoutes(r, tokenManager, authHandler, guildHandler, channelHandler, messageHandler, reactionHandler, dmHandler, pinHandler, mentionHandler, attachmentHandler)
 
 	// 设置 WebSocket 路由
 	upgrader := websocket.Upgrader{
@@ -199,46 +274,120 @@ func main() {
 		CheckOrigin: func(r *http.Request) bool {
 			return true // Allow all origins for demo
 		},
+		// 客户端可通过子协议选择帧编码
+		Subprotocols: gateway.Subprotocols,
+		// permessage-deflate，仅在客户端支持时启用
+		EnableCompression: cfg.Websocket.EnableCompression,
 	}
 
+	// 认证在连接建立后的 IDENTIFY 帧中完成，token 不出现在 URL 中
+	// 帧编码由 encoding 查询参数（protobuf / json）或子协议协商，默认 protobuf
 	r.GET("/ws", func(c *gin.Context) {
-		token := c.Query("token")
-		if token == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token required"})
+		// 排空中的节点不再接受新连接，客户端重试时由负载均衡分配到其他节点
+		if gwMessageHandler.Draining() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "gateway is draining"})
 			return
 		}
 
-		// Validate token
-		claims, err := tokenManager.ParseToken(token)
+		encoding, err := gateway.NegotiateEncoding(c.Request)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Upgrade connection
 		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 		if err != nil {
 			log.Printf("Failed to upgrade websocket: %v", err)
 			return
 		}
-
-		guildID := c.Query("guild_id") // Optional
-
-		// Add connection to manager
-		connection, err := connManager.AddConnection(claims.UserID, guildID, conn)
-		if err != nil {
-			log.Printf("Failed to add connection: %v", err)
-			conn.Close()
-			return
+		if cfg.Websocket.EnableCompression && cfg.Websocket.CompressionLevel != 0 {
+			if err := conn.SetCompressionLevel(cfg.Websocket.CompressionLevel); err != nil {
+				log.Printf("Invalid websocket compression level: %v", err)
+			}
 		}
 
-		// Handle connection
-		go gwMessageHandler.HandleConnection(connection)
+		// HELLO / IDENTIFY / READY handshake, then serve the session
+		gwMessageHandler.Accept(conn, encoding)
 	})
 
+	// 无法使用 WebSocket 的客户端（如被代理拦截）可改用 SSE 或长轮询，帧使用 JSON 编码
+	// 会话注册为虚拟连接，下行消息与 WebSocket 会话一致；上行帧通过 POST 提交，同样投递到 Kafka
+	// token 只通过 Authorization 头传递，EventSource 无法设置请求头，先用 token 换取一次性票据再建立 SSE
+	r.POST("/sse/ticket", gin.WrapF(gwMessageHandler.ServeTicket))
+	r.GET("/sse", gin.WrapF(gwMessageHandler.ServeSSE))
+	r.POST("/sse", gin.WrapF(gwMessageHandler.ServeSend))
+	r.GET("/poll", gin.WrapF(gwMessageHandler.ServePoll))
+	r.POST("/poll", gin.WrapF(gwMessageHandler.ServeSend))
+
 	// 启动服务器
-	log.Printf("正在启动服务器，监听端口 :%d\n", cfg.Server.Port)
-	if err := r.Run(":" + strconv.FormatInt(int64(cfg.Server.Port), 10)); err != nil {
-		log.Fatalf("启动服务器失败: %v", err)
+	httpServer := &http.Server{
+		Addr:    ":" + strconv.FormatInt(int64(cfg.Server.Port), 10),
+		Handler: r,
+	}
+	go func() {
+		log.Printf("正在启动服务器，监听端口 :%d\n", cfg.Server.Port)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("启动服务器失败: %v", err)
+		}
+	}()
+
+	// 收到 SIGTERM / SIGINT 后排空节点再退出
+	sigCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	<-sigCtx.Done()
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout)*time.Second)
+	defer cancel()
+
+	// 1. 停止接受新连接，分批通知客户端重连到其他节点（也可能已由 Drain RPC 触发）
+	log.Println("正在排空 Gateway 连接")
+	if err := gwMessageHandler.Drain(shutdownCtx, 0); err != nil {
+		log.Printf("排空 Gateway 连接未完成: %v", err)
+	}
+
+	// 2. 等待已接收的上行消息写入 Kafka，再停止消费并提交位点
+	if kafkaProducer != nil {
+		if err := kafkaProducer.Flush(shutdownCtx); err != nil {
+			log.Printf("等待 Kafka 写入完成失败: %v", err)
+		}
+	}
+	if consumer != nil {
+		if err := consumer.Stop(); err != nil {
+			log.Printf("停止 Kafka Consumer 失败: %v", err)
+		}
+	}
+
+	// 3. 依次关闭 HTTP、gRPC，落库已读位置，再关闭 Redis 和 Kafka
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("关闭 HTTP 服务器失败: %v", err)
+	}
+	grpcStopped := make(chan struct{})
+	go func() {
+		baseGrpcServer.Stop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		// 超时后强制关闭仍在进行的 RPC
+		baseGrpcServer.GetServer().Stop()
+	}
+	gwMessageHandler.Shutdown()
+	connManager.Shutdown()
+	if err := gatewayPool.Close(); err != nil {
+		log.Printf("关闭 Gateway 客户端失败: %v", err)
+	}
+	// 连接和请求都已结束，已读位置不再变化，落库后再关闭 Redis
+	stopFlusher()
+	<-flusherDone
+	if err := redisClient.Close(); err != nil {
+		log.Printf("关闭 Redis 连接失败: %v", err)
+	}
+	if kafkaProducer != nil {
+		if err := kafkaProducer.Close(); err != nil {
+			log.Printf("关闭 Kafka Producer 失败: %v", err)
+		}
 	}
+	log.Println("服务器已退出")
 }
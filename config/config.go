@@ -18,11 +18,13 @@ type Config struct {
 	GRPC       GRPCConfig       `mapstructure:"grpc"`
 	WorkerPool WorkerPoolConfig `mapstructure:"worker_pool"`
 	Logging    LoggingConfig    `mapstructure:"logging"`
+	Attachment AttachmentConfig `mapstructure:"attachment"`
 }
 
 type ServerConfig struct {
-	Port int    `mapstructure:"port"`
-	Mode string `mapstructure:"mode"`
+	Port            int    `mapstructure:"port"`
+	Mode            string `mapstructure:"mode"`
+	ShutdownTimeout int    `mapstructure:"shutdown_timeout"`
 }
 
 type PostgresConfig struct {
@@ -89,16 +91,24 @@ type RateLimitConfig struct {
 }
 
 type WebsocketConfig struct {
-	ReadBufferSize    int `mapstructure:"read_buffer_size"`
-	WriteBufferSize   int `mapstructure:"write_buffer_size"`
-	HeartbeatInterval int `mapstructure:"heartbeat_interval"`
-	ConnectionTimeout int `mapstructure:"connection_timeout"`
+	ReadBufferSize     int    `mapstructure:"read_buffer_size"`
+	WriteBufferSize    int    `mapstructure:"write_buffer_size"`
+	HeartbeatInterval  int    `mapstructure:"heartbeat_interval"`
+	ConnectionTimeout  int    `mapstructure:"connection_timeout"`
+	TypingInterval     int    `mapstructure:"typing_interval"`
+	TypingTTL          int    `mapstructure:"typing_ttl"`
+	EnableCompression  bool   `mapstructure:"enable_compression"`
+	CompressionLevel   int    `mapstructure:"compression_level"`
+	SendQueueSize      int    `mapstructure:"send_queue_size"`
+	SlowConsumerPolicy string `mapstructure:"slow_consumer_policy"`
+	DrainWindow        int    `mapstructure:"drain_window"`
 }
 
 type GRPCConfig struct {
-	Port              int `mapstructure:"port"`
-	MaxConnectionIdle int `mapstructure:"max_connection_idle"`
-	MaxConnectionAge  int `mapstructure:"max_connection_age"`
+	Port              int    `mapstructure:"port"`
+	MaxConnectionIdle int    `mapstructure:"max_connection_idle"`
+	MaxConnectionAge  int    `mapstructure:"max_connection_age"`
+	AdvertiseAddress  string `mapstructure:"advertise_address"`
 }
 
 type WorkerPoolConfig struct {
@@ -113,6 +123,14 @@ type LoggingConfig struct {
 	FilePath string `mapstructure:"file_path"`
 }
 
+type AttachmentConfig struct {
+	StorageDir       string   `mapstructure:"storage_dir"`
+	MaxSizeMB        int      `mapstructure:"max_size_mb"`
+	AllowedTypes     []string `mapstructure:"allowed_types"`
+	SigningSecret    string   `mapstructure:"signing_secret"`
+	URLExpireMinutes int      `mapstructure:"url_expire_minutes"`
+}
+
 func LoadConfig(path string) (*Config, error) {
 	v := viper.New()
 
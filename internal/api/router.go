@@ -14,7 +14,13 @@ func RegisterRoutes(
 	tokenManager *jwt.TokenManager,
 	authHandler *handler.AuthHandler,
 	guildHandler *handler.GuildHandler,
+	channelHandler *handler.ChannelHandler,
 	messageHandler *handler.MessageHandler,
+	reactionHandler *handler.ReactionHandler,
+	dmHandler *handler.DMHandler,
+	pinHandler *handler.PinHandler,
+	mentionHandler *handler.MentionHandler,
+	attachmentHandler *handler.AttachmentHandler,
 ) {
 	// Public routes
 	api := r.Group("/api/v1")
@@ -24,6 +30,9 @@ func RegisterRoutes(
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
 		}
+
+		// Signed attachment downloads carry their own authorization
+		api.GET("/attachments/:id/download", attachmentHandler.Download)
 	}
 
 	// Protected routes
@@ -37,6 +46,18 @@ func RegisterRoutes(
 			guilds.POST("/join", guildHandler.JoinGuild)
 			guilds.GET("", guildHandler.GetUserGuilds)
 			guilds.GET("/:id/members", guildHandler.GetGuildMembers)
+			guilds.GET("/:id/channels", channelHandler.ListChannels)
+			guilds.POST("/:id/channels", channelHandler.CreateChannel)
+			guilds.PUT("/:id/channels/order", channelHandler.ReorderChannels)
+			guilds.GET("/:id/pins", pinHandler.GetPinnedMessages)
+			guilds.GET("/:id/messages/search", messageHandler.SearchMessages)
+		}
+
+		// Channel routes
+		channels := protected.Group("/channels")
+		{
+			channels.PATCH("/:id", channelHandler.RenameChannel)
+			channels.DELETE("/:id", channelHandler.DeleteChannel)
 		}
 
 		// Message routes
@@ -44,6 +65,36 @@ func RegisterRoutes(
 		{
 			messages.POST("", messageHandler.SendMessage)
 			messages.GET("", messageHandler.GetMessages)
+			messages.GET("/:id/thread", messageHandler.GetThread)
+			messages.PUT("/:id", messageHandler.EditMessage)
+			messages.DELETE("/:id", messageHandler.DeleteMessage)
+			messages.PUT("/:id/reactions/:emoji", reactionHandler.AddReaction)
+			messages.DELETE("/:id/reactions/:emoji", reactionHandler.RemoveReaction)
+			messages.PUT("/:id/pin", pinHandler.PinMessage)
+			messages.DELETE("/:id/pin", pinHandler.UnpinMessage)
+		}
+
+		// Direct message routes
+		dms := protected.Group("/dms")
+		{
+			dms.GET("", dmHandler.ListConversations)
+			dms.POST("", dmHandler.OpenDirectConversation)
+			dms.POST("/groups", dmHandler.CreateGroupConversation)
+			dms.GET("/:id/messages", dmHandler.GetMessages)
+			dms.POST("/:id/messages", dmHandler.SendMessage)
+		}
+
+		// Attachment routes
+		attachments := protected.Group("/attachments")
+		{
+			attachments.POST("", attachmentHandler.Upload)
+			attachments.GET("/:id", attachmentHandler.GetDownloadURL)
+		}
+
+		// User routes
+		users := protected.Group("/users")
+		{
+			users.GET("/me/mentions", mentionHandler.GetMentions)
 		}
 	}
 }
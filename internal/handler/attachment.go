@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Gopher0727/ChatRoom/internal/service"
+)
+
+type AttachmentHandler struct {
+	attachmentService service.IAttachmentService
+	maxUploadBytes    int64
+}
+
+func NewAttachmentHandler(attachmentService service.IAttachmentService, maxUploadBytes int64) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+		maxUploadBytes:    maxUploadBytes,
+	}
+}
+
+// Upload handles a multipart upload of a single file in the "file" field
+func (h *AttachmentHandler) Upload(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Leave some room for the multipart envelope, the service enforces the exact file size
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadBytes+1<<20)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": service.ErrAttachmentTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		return
+	}
+	if header.Size > h.maxUploadBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": service.ErrAttachmentTooLarge.Error()})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.attachmentService.Upload(c.Request.Context(), userID, header.Filename, file)
+	if err != nil {
+		switch err {
+		case service.ErrAttachmentTooLarge:
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case service.ErrUnsupportedAttachmentType:
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		case service.ErrEmptyAttachment:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload attachment"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// GetDownloadURL issues a signed, time-limited download URL for an attachment
+func (h *AttachmentHandler) GetDownloadURL(c *gin.Context) {
+	attachmentID := c.Param("id")
+	if attachmentID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Attachment ID is required"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	signed, err := h.attachmentService.SignDownload(c.Request.Context(), userID, attachmentID)
+	if err != nil {
+		switch err {
+		case service.ErrAttachmentNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrUserNotInGuild, service.ErrNotParticipant:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign download URL"})
+		}
+		return
+	}
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(signed.Expires, 10))
+	query.Set("signature", signed.Signature)
+
+	c.JSON(http.StatusOK, gin.H{
+		"url":        fmt.Sprintf("/api/v1/attachments/%s/download?%s", url.PathEscape(signed.AttachmentID), query.Encode()),
+		"expires_at": signed.Expires,
+	})
+}
+
+// Download streams an attachment to the holder of a valid signed URL, no session is required
+func (h *AttachmentHandler) Download(c *gin.Context) {
+	attachmentID := c.Param("id")
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if attachmentID == "" || err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid download URL"})
+		return
+	}
+
+	attachment, content, err := h.attachmentService.OpenDownload(c.Request.Context(), attachmentID, expires, c.Query("signature"))
+	if err != nil {
+		switch err {
+		case service.ErrInvalidDownloadSignature:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case service.ErrAttachmentNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to download attachment"})
+		}
+		return
+	}
+	defer content.Close()
+
+	// Only images are rendered inline, everything else is downloaded
+	disposition := "attachment"
+	if strings.HasPrefix(attachment.ContentType, "image/") {
+		disposition = "inline"
+	}
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Filename}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
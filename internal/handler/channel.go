@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Gopher0727/ChatRoom/internal/service"
+)
+
+type ChannelHandler struct {
+	channelService service.IChannelService
+}
+
+func NewChannelHandler(channelService service.IChannelService) *ChannelHandler {
+	return &ChannelHandler{
+		channelService: channelService,
+	}
+}
+
+// CreateChannel handles creating a channel in a guild
+func (h *ChannelHandler) CreateChannel(c *gin.Context) {
+	guildID := c.Param("id")
+	if guildID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Guild ID is required"})
+		return
+	}
+
+	var req service.CreateChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	channel, err := h.channelService.CreateChannel(c.Request.Context(), userID, guildID, req.Name)
+	if err != nil {
+		switch err {
+		case service.ErrGuildNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrNotGuildOwner:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case service.ErrTooManyChannels:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create channel"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, channel)
+}
+
+// ListChannels retrieves the channels of a guild in display order
+func (h *ChannelHandler) ListChannels(c *gin.Context) {
+	guildID := c.Param("id")
+	if guildID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Guild ID is required"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	channels, err := h.channelService.ListChannels(c.Request.Context(), userID, guildID)
+	if err != nil {
+		if err == service.ErrUserNotInGuild {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve channels"})
+		return
+	}
+
+	c.JSON(http.StatusOK, channels)
+}
+
+// ReorderChannels handles rewriting the display order of a guild's channels
+func (h *ChannelHandler) ReorderChannels(c *gin.Context) {
+	guildID := c.Param("id")
+	if guildID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Guild ID is required"})
+		return
+	}
+
+	var req service.ReorderChannelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	channels, err := h.channelService.ReorderChannels(c.Request.Context(), userID, guildID, req.ChannelIDs)
+	if err != nil {
+		switch err {
+		case service.ErrGuildNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrNotGuildOwner:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case service.ErrInvalidChannelOrder:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder channels"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, channels)
+}
+
+// RenameChannel handles renaming a channel
+func (h *ChannelHandler) RenameChannel(c *gin.Context) {
+	channelID := c.Param("id")
+	if channelID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Channel ID is required"})
+		return
+	}
+
+	var req service.RenameChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	channel, err := h.channelService.RenameChannel(c.Request.Context(), userID, channelID, req.Name)
+	if err != nil {
+		switch err {
+		case service.ErrChannelNotFound, service.ErrGuildNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrNotGuildOwner:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rename channel"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, channel)
+}
+
+// DeleteChannel handles deleting a channel
+func (h *ChannelHandler) DeleteChannel(c *gin.Context) {
+	channelID := c.Param("id")
+	if channelID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Channel ID is required"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	err := h.channelService.DeleteChannel(c.Request.Context(), userID, channelID)
+	if err != nil {
+		switch err {
+		case service.ErrChannelNotFound, service.ErrGuildNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrNotGuildOwner:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete channel"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Channel deleted successfully"})
+}
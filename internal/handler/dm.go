@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Gopher0727/ChatRoom/internal/service"
+)
+
+type DMHandler struct {
+	dmService      service.IDMService
+	messageService service.IMessageService
+}
+
+func NewDMHandler(dmService service.IDMService, messageService service.IMessageService) *DMHandler {
+	return &DMHandler{
+		dmService:      dmService,
+		messageService: messageService,
+	}
+}
+
+// OpenDirectConversation handles opening (or reusing) a 1:1 conversation with another user
+func (h *DMHandler) OpenDirectConversation(c *gin.Context) {
+	var req service.OpenDirectConversationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	conversation, err := h.dmService.OpenDirectConversation(c.Request.Context(), userID, req.UserID)
+	if err != nil {
+		if err == service.ErrInvalidParticipants {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open conversation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, conversation)
+}
+
+// CreateGroupConversation handles creating a group DM
+func (h *DMHandler) CreateGroupConversation(c *gin.Context) {
+	var req service.CreateGroupConversationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	conversation, err := h.dmService.CreateGroupConversation(c.Request.Context(), userID, req.Name, req.UserIDs)
+	if err != nil {
+		if err == service.ErrInvalidParticipants {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create conversation"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, conversation)
+}
+
+// ListConversations retrieves the authenticated user's conversations
+func (h *DMHandler) ListConversations(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	conversations, err := h.dmService.ListConversations(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve conversations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, conversations)
+}
+
+// SendMessage handles sending a message in a conversation
+func (h *DMHandler) SendMessage(c *gin.Context) {
+	conversationID := c.Param("id")
+	if conversationID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Conversation ID is required"})
+		return
+	}
+
+	var req service.SendDirectMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	msg, err := h.messageService.SendMessage(c.Request.Context(), &service.SendMessageRequest{
+		UserID:         userID,
+		ConversationID: conversationID,
+		Content:        req.Content,
+		ReplyToID:      req.ReplyToID,
+		AttachmentIDs:  req.AttachmentIDs,
+		Nonce:          req.Nonce,
+	})
+	if err != nil {
+		switch err {
+		case service.ErrNotParticipant:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case service.ErrInvalidMessageContent, service.ErrInvalidReplyTarget, service.ErrInvalidAttachment, service.ErrInvalidNonce:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrMessageInFlight:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, msg)
+}
+
+// GetMessages retrieves messages of a conversation
+func (h *DMHandler) GetMessages(c *gin.Context) {
+	conversationID := c.Param("id")
+	if conversationID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Conversation ID is required"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var query historyQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.messageService.GetMessagesWithUser(c.Request.Context(), &service.GetMessagesRequest{
+		UserID:         userID,
+		ConversationID: conversationID,
+		LastSeqID:      query.LastSeqID,
+		BeforeSeqID:    query.Before,
+		AfterSeqID:     query.After,
+		AroundSeqID:    query.Around,
+		Limit:          query.Limit,
+	})
+	if err != nil {
+		switch err {
+		case service.ErrNotParticipant:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case service.ErrInvalidHistoryCursor:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve messages"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
@@ -9,12 +9,14 @@ import (
 )
 
 type GuildHandler struct {
-	guildService service.IGuildService
+	guildService     service.IGuildService
+	readStateService service.IReadStateService
 }
 
-func NewGuildHandler(guildService service.IGuildService) *GuildHandler {
+func NewGuildHandler(guildService service.IGuildService, readStateService service.IReadStateService) *GuildHandler {
 	return &GuildHandler{
-		guildService: guildService,
+		guildService:     guildService,
+		readStateService: readStateService,
 	}
 }
 
@@ -73,7 +75,7 @@ func (h *GuildHandler) JoinGuild(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Joined guild successfully"})
 }
 
-// GetUserGuilds retrieves guilds for the authenticated user
+// GetUserGuilds retrieves guilds for the authenticated user with their unread and mention counts
 func (h *GuildHandler) GetUserGuilds(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
@@ -81,7 +83,7 @@ func (h *GuildHandler) GetUserGuilds(c *gin.Context) {
 		return
 	}
 
-	guilds, err := h.guildService.GetUserGuilds(c.Request.Context(), userID)
+	guilds, err := h.readStateService.GetUserGuilds(c.Request.Context(), userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve guilds"})
 		return
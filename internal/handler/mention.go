@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Gopher0727/ChatRoom/internal/service"
+)
+
+type MentionHandler struct {
+	mentionService service.IMentionService
+}
+
+func NewMentionHandler(mentionService service.IMentionService) *MentionHandler {
+	return &MentionHandler{
+		mentionService: mentionService,
+	}
+}
+
+// GetMentions retrieves the messages that mentioned the authenticated user, newest first
+func (h *MentionHandler) GetMentions(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	beforeStr := c.Query("before")
+	var before int64
+	var err error
+	if beforeStr != "" {
+		before, err = strconv.ParseInt(beforeStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid before"})
+			return
+		}
+	}
+
+	limitStr := c.Query("limit")
+	limit := 50
+	if limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+	}
+
+	page, err := h.mentionService.GetMentions(c.Request.Context(), userID, before, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve mentions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
@@ -19,6 +19,16 @@ func NewMessageHandler(messageService service.IMessageService) *MessageHandler {
 	}
 }
 
+// historyQuery holds the pagination parameters of timeline endpoints
+// before, after and around are seq_id cursors, last_seq_id is the legacy name of after
+type historyQuery struct {
+	LastSeqID int64 `form:"last_seq_id"`
+	Before    int64 `form:"before"`
+	After     int64 `form:"after"`
+	Around    int64 `form:"around"`
+	Limit     int   `form:"limit"`
+}
+
 // SendMessage handles sending a message to a guild
 func (h *MessageHandler) SendMessage(c *gin.Context) {
 	var req service.SendMessageRequest
@@ -37,13 +47,17 @@ func (h *MessageHandler) SendMessage(c *gin.Context) {
 	// Or just override it
 	req.UserID = userID
 
-	msg, err := h.messageService.SendMessage(c.Request.Context(), req.UserID, req.GuildID, req.Content)
+	msg, err := h.messageService.SendMessage(c.Request.Context(), &req)
 	if err != nil {
 		switch err {
 		case service.ErrUserNotInGuild:
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-		case service.ErrInvalidMessageContent:
+		case service.ErrChannelNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrInvalidMessageContent, service.ErrInvalidReplyTarget, service.ErrInvalidAttachment, service.ErrInvalidNonce:
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrMessageInFlight:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
 		}
@@ -61,13 +75,100 @@ func (h *MessageHandler) GetMessages(c *gin.Context) {
 		return
 	}
 
-	lastSeqIDStr := c.Query("last_seq_id")
-	var lastSeqID int64
+	var query historyQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.messageService.GetMessagesWithUser(c.Request.Context(), &service.GetMessagesRequest{
+		UserID:      c.GetString("user_id"),
+		GuildID:     guildID,
+		ChannelID:   c.Query("channel_id"),
+		LastSeqID:   query.LastSeqID,
+		BeforeSeqID: query.Before,
+		AfterSeqID:  query.After,
+		AroundSeqID: query.Around,
+		Limit:       query.Limit,
+	})
+	if err != nil {
+		switch err {
+		case service.ErrChannelNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrUserNotInGuild:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case service.ErrInvalidHistoryCursor:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve messages"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// SearchMessages runs a full-text search over the messages of a guild
+func (h *MessageHandler) SearchMessages(c *gin.Context) {
+	guildID := c.Param("id")
+	if guildID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Guild ID is required"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req service.SearchMessagesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = userID
+	req.GuildID = guildID
+
+	page, err := h.messageService.SearchMessages(c.Request.Context(), &req)
+	if err != nil {
+		switch err {
+		case service.ErrChannelNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrUserNotInGuild:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case service.ErrInvalidSearchQuery:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search messages"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetThread retrieves a thread of replies, starting from its root message
+func (h *MessageHandler) GetThread(c *gin.Context) {
+	messageID := c.Param("id")
+	if messageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Message ID is required"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	afterSeqIDStr := c.Query("after_seq_id")
+	var afterSeqID int64
 	var err error
-	if lastSeqIDStr != "" {
-		lastSeqID, err = strconv.ParseInt(lastSeqIDStr, 10, 64)
+	if afterSeqIDStr != "" {
+		afterSeqID, err = strconv.ParseInt(afterSeqIDStr, 10, 64)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid last_seq_id"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid after_seq_id"})
 			return
 		}
 	}
@@ -82,14 +183,86 @@ func (h *MessageHandler) GetMessages(c *gin.Context) {
 		}
 	}
 
-	messages, hasMore, err := h.messageService.GetMessagesWithUser(c.Request.Context(), guildID, lastSeqID, limit)
+	thread, err := h.messageService.GetThread(c.Request.Context(), userID, messageID, afterSeqID, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve messages"})
+		switch err {
+		case service.ErrMessageNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrUserNotInGuild, service.ErrNotParticipant:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve thread"})
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"messages": messages,
-		"has_more": hasMore,
-	})
+	c.JSON(http.StatusOK, thread)
+}
+
+// EditMessage handles editing a message sent by the authenticated user
+func (h *MessageHandler) EditMessage(c *gin.Context) {
+	messageID := c.Param("id")
+	if messageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Message ID is required"})
+		return
+	}
+
+	var req service.EditMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	msg, err := h.messageService.EditMessage(c.Request.Context(), userID, messageID, req.Content)
+	if err != nil {
+		switch err {
+		case service.ErrMessageNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrNotMessageAuthor:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case service.ErrInvalidMessageContent:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to edit message"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, msg)
+}
+
+// DeleteMessage handles deleting a message sent by the authenticated user
+func (h *MessageHandler) DeleteMessage(c *gin.Context) {
+	messageID := c.Param("id")
+	if messageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Message ID is required"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	err := h.messageService.DeleteMessage(c.Request.Context(), userID, messageID)
+	if err != nil {
+		switch err {
+		case service.ErrMessageNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrNotMessageAuthor:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete message"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Message deleted successfully"})
 }
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Gopher0727/ChatRoom/internal/service"
+)
+
+type PinHandler struct {
+	pinService service.IPinService
+}
+
+func NewPinHandler(pinService service.IPinService) *PinHandler {
+	return &PinHandler{
+		pinService: pinService,
+	}
+}
+
+// PinMessage handles pinning a message in its guild
+func (h *PinHandler) PinMessage(c *gin.Context) {
+	messageID := c.Param("id")
+	if messageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Message ID is required"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.pinService.PinMessage(c.Request.Context(), userID, messageID); err != nil {
+		switch err {
+		case service.ErrMessageNotFound, service.ErrGuildNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrNotGuildOwner:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case service.ErrCannotPin:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrPinLimitReached:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to pin message"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// UnpinMessage handles unpinning a message
+func (h *PinHandler) UnpinMessage(c *gin.Context) {
+	messageID := c.Param("id")
+	if messageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Message ID is required"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.pinService.UnpinMessage(c.Request.Context(), userID, messageID); err != nil {
+		switch err {
+		case service.ErrMessageNotFound, service.ErrGuildNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrNotGuildOwner:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case service.ErrCannotPin:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unpin message"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetPinnedMessages retrieves the pinned messages of a guild
+func (h *PinHandler) GetPinnedMessages(c *gin.Context) {
+	guildID := c.Param("id")
+	if guildID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Guild ID is required"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	messages, err := h.pinService.GetPinnedMessages(c.Request.Context(), userID, guildID)
+	if err != nil {
+		if err == service.ErrUserNotInGuild {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pinned messages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, messages)
+}
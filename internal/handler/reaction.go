@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Gopher0727/ChatRoom/internal/service"
+)
+
+type ReactionHandler struct {
+	reactionService service.IReactionService
+}
+
+func NewReactionHandler(reactionService service.IReactionService) *ReactionHandler {
+	return &ReactionHandler{
+		reactionService: reactionService,
+	}
+}
+
+// AddReaction handles reacting to a message with an emoji
+func (h *ReactionHandler) AddReaction(c *gin.Context) {
+	h.handleReaction(c, h.reactionService.AddReaction, "Failed to add reaction")
+}
+
+// RemoveReaction handles removing the authenticated user's reaction from a message
+func (h *ReactionHandler) RemoveReaction(c *gin.Context) {
+	h.handleReaction(c, h.reactionService.RemoveReaction, "Failed to remove reaction")
+}
+
+// handleReaction extracts the route parameters and maps service errors for both reaction endpoints
+func (h *ReactionHandler) handleReaction(
+	c *gin.Context,
+	apply func(ctx context.Context, userID, messageID, emoji string) error,
+	failure string,
+) {
+	messageID := c.Param("id")
+	if messageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Message ID is required"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := apply(c.Request.Context(), userID, messageID, c.Param("emoji")); err != nil {
+		switch err {
+		case service.ErrMessageNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrUserNotInGuild, service.ErrNotParticipant:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case service.ErrInvalidEmoji:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": failure})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
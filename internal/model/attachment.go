@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// Attachment 消息附件元数据，文件内容保存在 BlobStore 中
+// 上传后 MessageID 为空，随消息发送时绑定到消息
+type Attachment struct {
+	ID          string `gorm:"primaryKey;type:varchar(64)" json:"id"`
+	MessageID   string `gorm:"index;not null;default:'';type:varchar(64)" json:"message_id,omitempty"`
+	UploaderID  string `gorm:"index;not null;type:varchar(64)" json:"uploader_id"`
+	Filename    string `gorm:"not null;type:varchar(255)" json:"filename"`
+	ContentType string `gorm:"not null;type:varchar(127)" json:"content_type"`
+	Size        int64  `gorm:"not null" json:"size"`
+	StorageKey  string `gorm:"not null;type:varchar(255)" json:"-"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+func (Attachment) TableName() string {
+	return "attachments"
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Channel 频道模型，Guild 内的文本频道，成员资格仍以 Guild 为边界
+type Channel struct {
+	ID       string `gorm:"primaryKey;type:varchar(64)" json:"id"`
+	GuildID  string `gorm:"index;not null;type:varchar(64)" json:"guild_id"`
+	Name     string `gorm:"not null;type:varchar(100)" json:"name"`
+	Position int    `gorm:"not null;default:0" json:"position"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+func (Channel) TableName() string {
+	return "channels"
+}
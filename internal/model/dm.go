@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// DMConversation 私信会话，包括一对一私信和小型群组私信，成员资格独立于 Guild
+type DMConversation struct {
+	ID      string `gorm:"primaryKey;type:varchar(64)" json:"id"`
+	Name    string `gorm:"type:varchar(100)" json:"name,omitempty"`
+	OwnerID string `gorm:"not null;type:varchar(64)" json:"owner_id"`
+	IsGroup bool   `gorm:"not null;default:false" json:"is_group"`
+
+	// DirectKey 一对一会话的唯一键（两个用户 ID 排序后拼接），群组私信为 NULL
+	DirectKey *string `gorm:"uniqueIndex;type:varchar(140)" json:"-"`
+
+	// ParticipantIDs 会话成员，由服务层填充
+	ParticipantIDs []string `gorm:"-" json:"participant_ids,omitempty"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+func (DMConversation) TableName() string {
+	return "dm_conversations"
+}
+
+// DMParticipant 私信会话成员
+type DMParticipant struct {
+	ConversationID string `gorm:"primaryKey;type:varchar(64)" json:"conversation_id"`
+	UserID         string `gorm:"primaryKey;index;type:varchar(64)" json:"user_id"`
+
+	JoinedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"joined_at"`
+}
+
+func (DMParticipant) TableName() string {
+	return "dm_participants"
+}
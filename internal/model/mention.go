@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// Mention 消息对用户的提及，<@user_id>、@everyone、@here 解析后按用户展开存储
+type Mention struct {
+	MessageID string `gorm:"primaryKey;type:varchar(64)" json:"message_id"`
+	UserID    string `gorm:"primaryKey;index:idx_mentions_user_created,priority:1;type:varchar(64)" json:"user_id"`
+
+	// GuildID / ConversationID 冗余消息的作用域，便于按来源筛选
+	GuildID        string `gorm:"type:varchar(64)" json:"guild_id,omitempty"`
+	ConversationID string `gorm:"type:varchar(64)" json:"conversation_id,omitempty"`
+
+	// CreatedAt 与消息创建时间一致，用作收件箱分页游标
+	CreatedAt time.Time `gorm:"not null;index:idx_mentions_user_created,priority:2" json:"created_at"`
+}
+
+func (Mention) TableName() string {
+	return "mentions"
+}
+
+// MentionPosition 提及所在的时间线位置，用于计算未读提及数
+type MentionPosition struct {
+	GuildID   string
+	ChannelID string
+	SeqID     int64
+}
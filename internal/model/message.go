@@ -2,6 +2,8 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Message 消息模型
@@ -12,7 +14,31 @@ type Message struct {
 	Content string `gorm:"type:text;not null" json:"content"`
 	SeqID   int64  `gorm:"index;not null" json:"seq_id"`
 
-	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	// ChannelID is the channel inside the guild, empty for the guild's default timeline
+	// SeqID is allocated per channel (or per guild for the default timeline)
+	ChannelID string `gorm:"index;not null;default:'';type:varchar(64)" json:"channel_id,omitempty"`
+	// ConversationID is the direct message conversation, GuildID is empty for DMs
+	ConversationID string `gorm:"index;not null;default:'';type:varchar(64)" json:"conversation_id,omitempty"`
+
+	// ReplyToID is the message being replied to (quote-reply)
+	ReplyToID string `gorm:"index;type:varchar(64)" json:"reply_to_id,omitempty"`
+	// ThreadRootID is the first message of the thread this message belongs to
+	ThreadRootID string `gorm:"index;type:varchar(64)" json:"thread_root_id,omitempty"`
+
+	// Resolved mentions, filled when the message is sent and not persisted on the message row
+	MentionUserIDs  []string `gorm:"-" json:"mention_user_ids,omitempty"`
+	MentionEveryone bool     `gorm:"-" json:"mention_everyone,omitempty"`
+	MentionHere     bool     `gorm:"-" json:"mention_here,omitempty"`
+
+	// Attachments are stored in their own table and loaded with the message when needed
+	Attachments []*Attachment `gorm:"-" json:"attachments,omitempty"`
+
+	// Nonce is the client supplied idempotency key of the send, echoed back and not persisted
+	Nonce string `gorm:"-" json:"nonce,omitempty"`
+
+	CreatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	EditedAt  *time.Time     `json:"edited_at,omitempty"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (Message) TableName() string {
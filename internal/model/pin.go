@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Pin 置顶消息，每个 Guild 独立维护
+type Pin struct {
+	GuildID   string `gorm:"primaryKey;type:varchar(64)" json:"guild_id"`
+	MessageID string `gorm:"primaryKey;type:varchar(64)" json:"message_id"`
+	PinnedBy  string `gorm:"not null;type:varchar(64)" json:"pinned_by"`
+
+	PinnedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"pinned_at"`
+}
+
+func (Pin) TableName() string {
+	return "pins"
+}
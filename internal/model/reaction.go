@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+)
+
+// Reaction 消息表情回应，每个用户对同一消息的同一表情只记录一次
+type Reaction struct {
+	MessageID string `gorm:"primaryKey;type:varchar(64)" json:"message_id"`
+	UserID    string `gorm:"primaryKey;type:varchar(64)" json:"user_id"`
+	Emoji     string `gorm:"primaryKey;type:varchar(64)" json:"emoji"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+func (Reaction) TableName() string {
+	return "reactions"
+}
+
+// ReactionCount 某条消息上某个表情的聚合计数
+type ReactionCount struct {
+	MessageID string `json:"-"`
+	Emoji     string `json:"emoji"`
+	Count     int64  `json:"count"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// ReadState 用户在某条时间线上的已读位置，ChannelID 为空表示 Guild 默认时间线
+// 实时位置保存在 Redis 中，这里是定期落库的副本
+type ReadState struct {
+	UserID        string `gorm:"primaryKey;type:varchar(64)" json:"user_id"`
+	GuildID       string `gorm:"primaryKey;type:varchar(64)" json:"guild_id"`
+	ChannelID     string `gorm:"primaryKey;default:'';type:varchar(64)" json:"channel_id"`
+	LastReadSeqID int64  `gorm:"not null;default:0" json:"last_read_seq_id"`
+
+	UpdatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+func (ReadState) TableName() string {
+	return "read_states"
+}
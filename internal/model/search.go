@@ -0,0 +1,10 @@
+package model
+
+// MessageSearchHit 全文检索命中的消息
+type MessageSearchHit struct {
+	Message
+	// Rank 相关度得分，越大越相关
+	Rank float64 `json:"rank"`
+	// Snippet 命中片段，关键词由 \x02 和 \x03 包围，由业务层转换为高亮标记
+	Snippet string `json:"snippet"`
+}
@@ -11,8 +11,11 @@ type User struct {
 	Email        string `gorm:"uniqueIndex;not null;type:varchar(255)" json:"email"`
 	PasswordHash string `gorm:"not null;type:varchar(255)" json:"-"`
 	AvatarURL    string `json:"avatar_url"`
-	Status       string `gorm:"default:offline" json:"status"` // online, offline
-	HubID        string `gorm:"index;type:varchar(64)" json:"hub_id"`
+	Status       string `gorm:"default:offline" json:"status"` // 对外可见的在线状态：online, idle, dnd, offline
+	// 用户选择的状态：online, idle, dnd, invisible，隐身时对外显示为 offline
+	PreferredStatus string `gorm:"default:online;type:varchar(16)" json:"-"`
+	CustomStatus    string `gorm:"type:varchar(128)" json:"custom_status"`
+	HubID           string `gorm:"index;type:varchar(64)" json:"hub_id"`
 
 	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
@@ -0,0 +1,25 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var (
+	// ErrNotFound 对象不存在
+	ErrNotFound = errors.New("blob not found")
+	// ErrInvalidKey 对象键非法（为空或试图逃逸存储根目录）
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// BlobStore 二进制对象存储
+// 本地文件系统与 S3 兼容存储实现同一接口，业务层只通过对象键访问文件
+type BlobStore interface {
+	// Put 写入对象，已存在时覆盖，返回写入的字节数
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Open 打开对象用于读取，调用方负责关闭
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete 删除对象，对象不存在时不报错
+	Delete(ctx context.Context, key string) error
+}
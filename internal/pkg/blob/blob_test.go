@@ -0,0 +1,80 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLocalStore_PutOpenDelete tests a full object lifecycle on the local filesystem.
+func TestLocalStore_PutOpenDelete(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	n, err := store.Put(ctx, "user_1/file_1", strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+
+	r, err := store.Open(ctx, "user_1/file_1")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "hello", string(data))
+
+	require.NoError(t, store.Delete(ctx, "user_1/file_1"))
+	_, err = store.Open(ctx, "user_1/file_1")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Deleting a missing object is not an error
+	assert.NoError(t, store.Delete(ctx, "user_1/file_1"))
+}
+
+// TestLocalStore_InvalidKey tests that keys cannot escape the storage root.
+func TestLocalStore_InvalidKey(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "../escape", "/etc/passwd", "a/../../escape"} {
+		_, err := store.Put(ctx, key, strings.NewReader("x"))
+		assert.ErrorIs(t, err, ErrInvalidKey, "key %q", key)
+		_, err = store.Open(ctx, key)
+		assert.ErrorIs(t, err, ErrInvalidKey, "key %q", key)
+	}
+}
+
+// TestLocalStore_CanceledPut tests that a canceled context aborts the write.
+func TestLocalStore_CanceledPut(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = store.Put(ctx, "file", strings.NewReader("hello"))
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = store.Open(context.Background(), "file")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// TestSigner tests signing and verification of download links.
+func TestSigner(t *testing.T) {
+	signer := NewSigner("secret")
+	now := time.Unix(1_700_000_000, 0)
+	expires := now.Add(time.Minute).Unix()
+
+	sig := signer.Sign("att_1", expires)
+	assert.True(t, signer.Verify("att_1", expires, sig, now))
+
+	assert.False(t, signer.Verify("att_2", expires, sig, now), "signature is bound to the ID")
+	assert.False(t, signer.Verify("att_1", expires+1, sig, now), "signature is bound to the expiry")
+	assert.False(t, signer.Verify("att_1", expires, sig, now.Add(2*time.Minute)), "expired signature")
+	assert.False(t, NewSigner("other").Verify("att_1", expires, sig, now), "signature is bound to the secret")
+}
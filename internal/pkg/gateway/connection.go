@@ -2,27 +2,72 @@ package gateway
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+
+	chat "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
 )
 
 // Connection represents a WebSocket connection with heartbeat management.
 // It wraps the underlying WebSocket connection and provides methods for
 // sending/receiving messages and managing connection lifecycle.
+// A virtual connection has no WebSocket, its frames are served over Server-Sent Events
+// or long polling from the same send queue, see ServeSSE and ServePoll.
 type Connection struct {
+	// SessionID uniquely identifies this connection, a user can hold several sessions
+	SessionID string
+
 	// UserID is the unique identifier of the connected user
 	UserID string
 
-	// GuildID is the guild this connection is associated with
+	// Device is a client supplied label for the session, e.g. "web" or "ios"
+	Device string
+
+	// GuildID is the guild this connection was opened for, it is always subscribed.
+	// Empty means the connection was opened without a guild.
 	GuildID string
 
-	// Conn is the underlying WebSocket connection
+	// ChannelID is the channel this connection targets inside GuildID.
+	// Empty means the connection receives events from every channel of the guild.
+	ChannelID string
+
+	// guilds is the set of guilds the connection receives events from, including GuildID
+	guilds map[string]struct{}
+
+	// guildsMu protects guilds
+	guildsMu sync.RWMutex
+
+	// capabilities are the optional events the client opted into, set before the session is indexed
+	capabilities map[string]struct{}
+
+	// Encoding is the serialization of the frames, negotiated when the WebSocket connected
+	Encoding Encoding
+
+	// Conn is the underlying WebSocket connection, nil for virtual connections
 	Conn *websocket.Conn
 
-	// Send is a buffered channel for outbound messages
-	Send chan []byte
+	// Send is a buffered channel for outbound frames, serialized by the write pump.
+	// It is never closed, readers stop once the connection context is done.
+	Send chan *Frame
+
+	// slowConsumer is the policy applied when Send is full
+	slowConsumer SlowConsumerPolicy
+
+	// dropped counts the frames of this connection lost because Send was full
+	dropped atomic.Uint64
+
+	// counters aggregates the losses of every connection of the manager, nil for unmanaged connections
+	counters *sendCounters
+
+	// slow is set once the connection is being closed as a slow consumer
+	slow atomic.Bool
+
+	// polling is set while a long poll request waits on a virtual connection
+	polling atomic.Bool
 
 	// mu protects concurrent writes to the WebSocket connection
 	mu sync.Mutex
@@ -44,26 +89,110 @@ type Connection struct {
 
 	// closedMu protects the closed flag
 	closedMu sync.RWMutex
+
+	// resuming is set while the session replays the messages it missed,
+	// live frames are held back meanwhile so that they follow the replay
+	resuming bool
+
+	// held queues the live frames received while resuming
+	held []heldFrame
+
+	// heldOverflow is set when live frames had to be dropped while resuming
+	heldOverflow bool
+
+	// resumeMu protects resuming, held and heldOverflow
+	resumeMu sync.Mutex
+}
+
+// heldFrame is a live frame held back while a session is resuming.
+type heldFrame struct {
+	frame *Frame
+
+	// messageID is set for new message events, which are dropped when the replay already sent them
+	messageID string
+}
+
+// maxHeldFrames caps the live frames held back while a session is resuming.
+const maxHeldFrames = 1024
+
+// defaultSendQueueSize is the capacity of Send when none is configured.
+const defaultSendQueueSize = 256
+
+// SlowConsumerPolicy decides what happens to a frame when the send queue of a connection is full.
+type SlowConsumerPolicy int
+
+const (
+	// SlowConsumerDisconnect closes the connection with a resumable close code, the client
+	// reconnects with its resume positions and gets the missed messages replayed
+	SlowConsumerDisconnect SlowConsumerPolicy = iota
+
+	// SlowConsumerDropOldest discards the oldest queued frame to make room for the new one
+	SlowConsumerDropOldest
+
+	// SlowConsumerDropNewest discards the new frame
+	SlowConsumerDropNewest
+)
+
+// ParseSlowConsumerPolicy parses a policy name: "disconnect" (the default when empty),
+// "drop_oldest" or "drop_newest".
+func ParseSlowConsumerPolicy(name string) (SlowConsumerPolicy, error) {
+	switch name {
+	case "", "disconnect":
+		return SlowConsumerDisconnect, nil
+	case "drop_oldest":
+		return SlowConsumerDropOldest, nil
+	case "drop_newest":
+		return SlowConsumerDropNewest, nil
+	default:
+		return SlowConsumerDisconnect, fmt.Errorf("unknown slow consumer policy %q", name)
+	}
+}
+
+// sendCounters counts the frames lost to slow consumers, shared by the connections of a manager.
+type sendCounters struct {
+	dropped     atomic.Uint64
+	disconnects atomic.Uint64
+}
+
+// SendStats reports the frames lost to slow consumers.
+type SendStats struct {
+	// DroppedFrames is the number of frames discarded because a send queue was full
+	DroppedFrames uint64
+
+	// SlowConsumerDisconnects is the number of connections closed because their send queue was full
+	SlowConsumerDisconnects uint64
 }
 
 // NewConnection creates a new Connection instance.
 //
 // Parameters:
 //   - ctx: Parent context for the connection
+//   - sessionID: The session identifier
 //   - userID: The user identifier
+//   - device: The device label (optional)
 //   - guildID: The guild identifier
-//   - conn: The WebSocket connection
+//   - channelID: The channel identifier (optional)
+//   - conn: The WebSocket connection, nil for a virtual connection
 //
 // Returns:
 //   - *Connection: The initialized connection
-func NewConnection(ctx context.Context, userID, guildID string, conn *websocket.Conn) *Connection {
+func NewConnection(ctx context.Context, sessionID, userID, device, guildID, channelID string, conn *websocket.Conn) *Connection {
 	connCtx, cancel := context.WithCancel(ctx)
 
+	guilds := make(map[string]struct{})
+	if guildID != "" {
+		guilds[guildID] = struct{}{}
+	}
+
 	return &Connection{
+		SessionID:     sessionID,
 		UserID:        userID,
+		Device:        device,
 		GuildID:       guildID,
+		ChannelID:     channelID,
+		guilds:        guilds,
 		Conn:          conn,
-		Send:          make(chan []byte, 256),
+		Send:          make(chan *Frame, defaultSendQueueSize),
 		lastHeartbeat: time.Now(),
 		ctx:           connCtx,
 		cancel:        cancel,
@@ -91,6 +220,22 @@ func (c *Connection) WriteMessage(messageType int, data []byte) error {
 	return c.Conn.WriteMessage(messageType, data)
 }
 
+// WriteFrame serializes a frame in the connection's encoding and writes it to the WebSocket connection.
+// It is thread-safe like WriteMessage.
+//
+// Parameters:
+//   - frame: The frame to send
+//
+// Returns:
+//   - error: Any error encountered during serialization or writing
+func (c *Connection) WriteFrame(frame *Frame) error {
+	data, err := frame.Encode(c.Encoding)
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(c.Encoding.messageType(), data)
+}
+
 // ReadMessage reads a message from the WebSocket connection.
 //
 // Returns:
@@ -103,16 +248,19 @@ func (c *Connection) ReadMessage() (int, []byte, error) {
 
 // Close closes the WebSocket connection and cleans up resources.
 // It performs the following cleanup steps:
-// 1. Marks the connection as closed (idempotent)
-// 2. Cancels the connection context to stop goroutines
-// 3. Closes the send channel to prevent further writes
-// 4. Closes the underlying WebSocket connection
+// 1. Cancels the connection context to stop goroutines and unblock writers waiting on Send
+// 2. Marks the connection as closed (idempotent), nothing is queued afterwards
+// 3. Closes the underlying WebSocket connection
 //
+// Send is left open, frames queued before Close can still be taken, see pending.
 // It is safe to call multiple times.
 //
 // Returns:
 //   - error: Any error from closing the WebSocket connection
 func (c *Connection) Close() error {
+	// Canceled before taking the lock, so that a writer blocked on Send releases it
+	c.cancel()
+
 	c.closedMu.Lock()
 	defer c.closedMu.Unlock()
 
@@ -121,21 +269,315 @@ func (c *Connection) Close() error {
 		return nil
 	}
 
-	// Mark as closed first to prevent new operations
+	// Writers hold closedMu while sending, once set no frame is queued anymore
 	c.closed = true
 
-	// Cancel context to signal goroutines to stop
-	c.cancel()
-
-	// Close send channel to unblock any writers
-	// This must be done after marking closed to prevent panic
-	close(c.Send)
+	// Virtual connections end their stream or poll once the context is done
+	if c.Conn == nil {
+		return nil
+	}
 
 	// Close the underlying WebSocket connection
 	// This releases network resources
 	return c.Conn.Close()
 }
 
+// Virtual reports whether the connection is served over Server-Sent Events or long polling.
+func (c *Connection) Virtual() bool {
+	return c.Conn == nil
+}
+
+// configureSendQueue sizes the send queue and sets the slow consumer policy.
+// It must be called before the connection is served.
+func (c *Connection) configureSendQueue(size int, policy SlowConsumerPolicy, counters *sendCounters) {
+	if size <= 0 {
+		size = defaultSendQueueSize
+	}
+	c.Send = make(chan *Frame, size)
+	c.slowConsumer = policy
+	c.counters = counters
+}
+
+// Enqueue queues an outbound frame for the write pump without blocking, so that a slow
+// client never stalls the fan-out. When the queue is full the slow consumer policy applies.
+// While the session is resuming the frame is held back until the replay is done.
+//
+// Parameters:
+//   - frame: The frame to send
+//   - messageID: The message ID for new message events, empty for any other event
+//
+// Returns:
+//   - bool: true if the frame was queued or held, false if it was dropped
+func (c *Connection) Enqueue(frame *Frame, messageID string) bool {
+	c.resumeMu.Lock()
+	if c.resuming {
+		defer c.resumeMu.Unlock()
+		if len(c.held) >= maxHeldFrames {
+			c.heldOverflow = true
+			return false
+		}
+		c.held = append(c.held, heldFrame{frame: frame, messageID: messageID})
+		return true
+	}
+	c.resumeMu.Unlock()
+
+	return c.offer(frame)
+}
+
+// offer queues a frame without blocking, applying the slow consumer policy when the queue is full.
+func (c *Connection) offer(frame *Frame) bool {
+	// Holding closedMu keeps the send from racing with Close, nothing is queued once closed
+	c.closedMu.RLock()
+	defer c.closedMu.RUnlock()
+
+	if c.closed {
+		return false
+	}
+
+	for {
+		select {
+		case c.Send <- frame:
+			return true
+		default:
+		}
+
+		switch c.slowConsumer {
+		case SlowConsumerDropOldest:
+			select {
+			case <-c.Send:
+				c.recordDrop()
+			default:
+				// The write pump made room meanwhile
+			}
+		case SlowConsumerDropNewest:
+			c.recordDrop()
+			return false
+		default:
+			c.recordDrop()
+			if c.slow.CompareAndSwap(false, true) {
+				if c.counters != nil {
+					c.counters.disconnects.Add(1)
+				}
+				go c.closeResumable("send queue overflow, resume again")
+			}
+			return false
+		}
+	}
+}
+
+// recordDrop counts a frame lost because the send queue was full.
+func (c *Connection) recordDrop() {
+	c.dropped.Add(1)
+	if c.counters != nil {
+		c.counters.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of frames of this connection lost because its send queue was full.
+func (c *Connection) Dropped() uint64 {
+	return c.dropped.Load()
+}
+
+// closeResumable closes the connection with ERROR_RESUME_REQUIRED, telling the client to reconnect and resume.
+func (c *Connection) closeResumable(reason string) {
+	c.closeWithCode(chat.GatewayErrorCode_ERROR_RESUME_REQUIRED, reason)
+}
+
+// closeWithCode sends a close frame with the gateway error code as close code and closes the connection.
+// Gateway error codes are in the 4000-4999 range WebSocket reserves for applications.
+// Virtual connections get the code in a last ERROR frame instead, when their queue has room.
+func (c *Connection) closeWithCode(code chat.GatewayErrorCode, reason string) {
+	if c.Conn == nil {
+		c.closedMu.RLock()
+		if !c.closed {
+			select {
+			case c.Send <- newErrorFrame(code, reason):
+			default:
+			}
+		}
+		c.closedMu.RUnlock()
+		c.Close()
+		return
+	}
+
+	closeMsg := websocket.FormatCloseMessage(int(code), reason)
+	// WriteControl is safe to call concurrently with the write pump
+	c.Conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second))
+	c.Close()
+}
+
+// push queues a frame for the write pump, bypassing the frames held while resuming.
+// It waits up to the timeout for room in the queue, Close cancels the wait.
+func (c *Connection) push(frame *Frame, timeout time.Duration) bool {
+	// Holding closedMu keeps the send from racing with Close, as in offer
+	c.closedMu.RLock()
+	defer c.closedMu.RUnlock()
+
+	if c.closed {
+		return false
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case c.Send <- frame:
+		return true
+	case <-c.ctx.Done():
+		return false
+	case <-timer.C:
+		return false
+	}
+}
+
+// pending takes the frames left in the queue of a closed connection, such as the last ERROR
+// frame of a virtual connection. Nothing is queued once the connection is closed.
+func (c *Connection) pending() []*Frame {
+	var frames []*Frame
+	for {
+		select {
+		case frame := <-c.Send:
+			frames = append(frames, frame)
+		default:
+			return frames
+		}
+	}
+}
+
+// Resuming reports whether the session is waiting for or replaying missed messages.
+func (c *Connection) Resuming() bool {
+	c.resumeMu.Lock()
+	defer c.resumeMu.Unlock()
+
+	return c.resuming
+}
+
+// hold starts holding back live frames until the session is resumed.
+func (c *Connection) hold() {
+	c.resumeMu.Lock()
+	defer c.resumeMu.Unlock()
+
+	c.resuming = true
+}
+
+// release queues the held frames and switches the session to live delivery.
+// New message events the replay already sent are dropped, so that the client gets
+// every message exactly once around the switchover. The held frames are queued without
+// blocking and outside resumeMu, the slow consumer policy applies as for live frames.
+// The session keeps holding until no frame is left, so that frames enqueued meanwhile
+// wait for the held ones, keeping their order.
+//
+// Parameters:
+//   - replayed: The IDs of the messages sent by the replay
+//
+// Returns:
+//   - bool: false if live frames were dropped while resuming, the session then has a gap
+func (c *Connection) release(replayed map[string]struct{}) bool {
+	complete := true
+	for {
+		c.resumeMu.Lock()
+		held := c.held
+		c.held = nil
+		if c.heldOverflow {
+			complete = false
+			c.heldOverflow = false
+		}
+		// Switched to live delivery once nothing is held, frames of a closed session are discarded
+		if len(held) == 0 || c.IsClosed() {
+			c.resuming = false
+			c.resumeMu.Unlock()
+			return complete
+		}
+		c.resumeMu.Unlock()
+
+		for _, held := range held {
+			if _, ok := replayed[held.messageID]; ok && held.messageID != "" {
+				continue
+			}
+			c.offer(held.frame)
+		}
+	}
+}
+
+// Wants reports whether a downstream event of a subscribed guild should be delivered.
+// In the guild the connection was opened for, channel-scoped events only reach connections
+// targeting that channel or the whole guild, events without a channel (the guild's default
+// timeline) reach every connection. Guilds subscribed later deliver events of every channel.
+func (c *Connection) Wants(guildID, channelID string) bool {
+	if guildID != c.GuildID {
+		return true
+	}
+	return c.ChannelID == "" || channelID == "" || c.ChannelID == channelID
+}
+
+// HasCapability reports whether the client opted into an optional event.
+func (c *Connection) HasCapability(capability string) bool {
+	_, ok := c.capabilities[capability]
+	return ok
+}
+
+// Capabilities returns the optional events the client opted into.
+func (c *Connection) Capabilities() []string {
+	capabilities := make([]string, 0, len(c.capabilities))
+	for capability := range c.capabilities {
+		capabilities = append(capabilities, capability)
+	}
+	return capabilities
+}
+
+// setCapabilities records the optional events the client opted into.
+func (c *Connection) setCapabilities(capabilities []string) {
+	c.capabilities = make(map[string]struct{}, len(capabilities))
+	for _, capability := range capabilities {
+		c.capabilities[capability] = struct{}{}
+	}
+}
+
+// Subscribed reports whether the connection receives events from a guild.
+func (c *Connection) Subscribed(guildID string) bool {
+	c.guildsMu.RLock()
+	defer c.guildsMu.RUnlock()
+
+	_, ok := c.guilds[guildID]
+	return ok
+}
+
+// Guilds returns the guilds the connection is subscribed to.
+func (c *Connection) Guilds() []string {
+	c.guildsMu.RLock()
+	defer c.guildsMu.RUnlock()
+
+	guildIDs := make([]string, 0, len(c.guilds))
+	for guildID := range c.guilds {
+		guildIDs = append(guildIDs, guildID)
+	}
+	return guildIDs
+}
+
+// subscribe adds a guild to the connection's subscriptions, reporting whether it was added.
+func (c *Connection) subscribe(guildID string) bool {
+	c.guildsMu.Lock()
+	defer c.guildsMu.Unlock()
+
+	if _, ok := c.guilds[guildID]; ok {
+		return false
+	}
+	c.guilds[guildID] = struct{}{}
+	return true
+}
+
+// unsubscribe removes a guild from the connection's subscriptions, reporting whether it was removed.
+func (c *Connection) unsubscribe(guildID string) bool {
+	c.guildsMu.Lock()
+	defer c.guildsMu.Unlock()
+
+	if _, ok := c.guilds[guildID]; !ok {
+		return false
+	}
+	delete(c.guilds, guildID)
+	return true
+}
+
 // IsClosed returns whether the connection has been closed.
 func (c *Connection) IsClosed() bool {
 	c.closedMu.RLock()
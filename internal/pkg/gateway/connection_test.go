@@ -0,0 +1,151 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	chat "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
+)
+
+// TestConnection_Resume tests that live frames are held while resuming and released without replayed messages.
+func TestConnection_Resume(t *testing.T) {
+	conn := NewConnection(context.Background(), "session_1", "user_1", "web", "guild_1", "", nil)
+	conn.hold()
+	assert.True(t, conn.Resuming())
+
+	message1 := NewDispatch(&chat.WSMessage{MessageId: "message_1"})
+	edit1 := NewDispatch(&chat.WSMessage{MessageId: "message_1", Type: chat.MessageType_MESSAGE_EDIT})
+	message2 := NewDispatch(&chat.WSMessage{MessageId: "message_2"})
+
+	assert.True(t, conn.Enqueue(message1, "message_1"))
+	assert.True(t, conn.Enqueue(edit1, ""))
+	assert.True(t, conn.Enqueue(message2, "message_2"))
+	assert.Empty(t, conn.Send, "frames are held while resuming")
+
+	// The replay sent message_1, the live copy is dropped
+	assert.True(t, conn.release(map[string]struct{}{"message_1": {}}))
+	assert.False(t, conn.Resuming())
+	assert.Same(t, edit1, <-conn.Send)
+	assert.Same(t, message2, <-conn.Send)
+
+	// Once live, frames are queued directly
+	message3 := NewDispatch(&chat.WSMessage{MessageId: "message_3"})
+	assert.True(t, conn.Enqueue(message3, "message_3"))
+	assert.Same(t, message3, <-conn.Send)
+}
+
+// TestConnection_ResumeOverflow tests that a resume reports the frames dropped while holding.
+func TestConnection_ResumeOverflow(t *testing.T) {
+	conn := NewConnection(context.Background(), "session_1", "user_1", "web", "guild_1", "", nil)
+	conn.hold()
+
+	event := NewDispatch(&chat.WSMessage{Type: chat.MessageType_TYPING})
+	for range maxHeldFrames {
+		assert.True(t, conn.Enqueue(event, ""))
+	}
+	assert.False(t, conn.Enqueue(event, ""))
+
+	// Drain the queue while the held frames are released
+	go func() {
+		for range conn.Send {
+		}
+	}()
+	assert.False(t, conn.release(nil))
+	assert.False(t, conn.Resuming())
+}
+
+// TestConnection_ReleaseFullQueue tests that releasing held frames never blocks on a full queue.
+func TestConnection_ReleaseFullQueue(t *testing.T) {
+	conn := NewConnection(context.Background(), "session_1", "user_1", "web", "guild_1", "", nil)
+	conn.configureSendQueue(1, SlowConsumerDropNewest, nil)
+	conn.hold()
+
+	frames := make([]*Frame, 3)
+	for i := range frames {
+		frames[i] = NewDispatch(&chat.WSMessage{SeqId: int64(i + 1)})
+		assert.True(t, conn.Enqueue(frames[i], ""))
+	}
+
+	// The frames that don't fit are dropped by the policy, as live frames would be
+	assert.True(t, conn.release(nil))
+	assert.False(t, conn.Resuming())
+	assert.Equal(t, []*Frame{frames[0]}, conn.pending())
+	assert.Equal(t, uint64(2), conn.Dropped())
+}
+
+// TestConnection_PushClose tests that Close unblocks a push waiting on a full queue and keeps queued frames.
+func TestConnection_PushClose(t *testing.T) {
+	conn := NewConnection(context.Background(), "session_1", "user_1", "web", "guild_1", "", nil)
+	conn.configureSendQueue(1, SlowConsumerDropNewest, nil)
+
+	frame := NewDispatch(&chat.WSMessage{MessageId: "message_1"})
+	assert.True(t, conn.push(frame, time.Second))
+
+	done := make(chan bool)
+	go func() {
+		done <- conn.push(NewDispatch(&chat.WSMessage{MessageId: "message_2"}), time.Minute)
+	}()
+
+	conn.Close()
+	assert.False(t, <-done)
+	assert.False(t, conn.push(frame, time.Second))
+	assert.False(t, conn.Enqueue(frame, ""))
+	assert.Equal(t, []*Frame{frame}, conn.pending())
+}
+
+// TestConnection_SlowConsumer tests the policies applied when the send queue is full.
+func TestConnection_SlowConsumer(t *testing.T) {
+	frames := make([]*Frame, 3)
+	for i := range frames {
+		frames[i] = NewDispatch(&chat.WSMessage{SeqId: int64(i + 1)})
+	}
+
+	t.Run("drop oldest", func(t *testing.T) {
+		var counters sendCounters
+		conn := NewConnection(context.Background(), "session_1", "user_1", "web", "guild_1", "", nil)
+		conn.configureSendQueue(2, SlowConsumerDropOldest, &counters)
+
+		for _, frame := range frames {
+			assert.True(t, conn.Enqueue(frame, ""))
+		}
+		assert.Same(t, frames[1], <-conn.Send)
+		assert.Same(t, frames[2], <-conn.Send)
+		assert.Equal(t, uint64(1), conn.Dropped())
+		assert.Equal(t, uint64(1), counters.dropped.Load())
+	})
+
+	t.Run("drop newest", func(t *testing.T) {
+		var counters sendCounters
+		conn := NewConnection(context.Background(), "session_1", "user_1", "web", "guild_1", "", nil)
+		conn.configureSendQueue(2, SlowConsumerDropNewest, &counters)
+
+		assert.True(t, conn.Enqueue(frames[0], ""))
+		assert.True(t, conn.Enqueue(frames[1], ""))
+		assert.False(t, conn.Enqueue(frames[2], ""))
+		assert.Same(t, frames[0], <-conn.Send)
+		assert.Same(t, frames[1], <-conn.Send)
+		assert.Equal(t, uint64(1), conn.Dropped())
+		assert.Equal(t, uint64(1), counters.dropped.Load())
+		assert.Zero(t, counters.disconnects.Load())
+	})
+}
+
+// TestParseSlowConsumerPolicy tests parsing the configured policy.
+func TestParseSlowConsumerPolicy(t *testing.T) {
+	for name, expected := range map[string]SlowConsumerPolicy{
+		"":            SlowConsumerDisconnect,
+		"disconnect":  SlowConsumerDisconnect,
+		"drop_oldest": SlowConsumerDropOldest,
+		"drop_newest": SlowConsumerDropNewest,
+	} {
+		policy, err := ParseSlowConsumerPolicy(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, policy)
+	}
+
+	_, err := ParseSlowConsumerPolicy("block")
+	assert.Error(t, err)
+}
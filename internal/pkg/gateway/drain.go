@@ -0,0 +1,102 @@
+package gateway
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	chat "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
+)
+
+// drainPollInterval is how often a drain checks whether the sessions are gone.
+const drainPollInterval = 100 * time.Millisecond
+
+// Draining reports whether the gateway is draining, new sessions are then refused.
+func (h *MessageHandler) Draining() bool {
+	return h.draining.Load()
+}
+
+// StartDrain puts the gateway in drain mode so that the node can be shut down without dropping clients.
+// New sessions are refused, and every session is closed with ERROR_NODE_DRAINING at a random time
+// within the window, so that the clients reconnect to other nodes and resume without all arriving
+// at once. Upstream frames a session already read are produced to Kafka before its read loop ends.
+// Calling it again returns the drain already in progress.
+//
+// Parameters:
+//   - window: The duration over which the sessions are closed, the configured drain window when zero
+//
+// Returns:
+//   - <-chan struct{}: Closed once every session of the node is gone
+func (h *MessageHandler) StartDrain(window time.Duration) <-chan struct{} {
+	h.drainOnce.Do(func() {
+		if window <= 0 {
+			window = time.Duration(h.config.Websocket.DrainWindow) * time.Second
+		}
+		h.draining.Store(true)
+		go h.drain(window)
+	})
+	return h.drained
+}
+
+// Drain starts draining the gateway and waits until every session is gone.
+//
+// Parameters:
+//   - ctx: Context bounding the wait, the drain goes on when it is done
+//   - window: The duration over which the sessions are closed, the configured drain window when zero
+//
+// Returns:
+//   - error: The context error if the drain did not complete in time
+func (h *MessageHandler) Drain(ctx context.Context, window time.Duration) error {
+	select {
+	case <-h.StartDrain(window):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// drain closes every session with jitter and waits until they are all removed.
+func (h *MessageHandler) drain(window time.Duration) {
+	defer close(h.drained)
+
+	// Sessions added after the snapshot see the drain flag and are refused
+	connections := h.connManager.GetAllConnections()
+	log.Printf("Draining %d sessions over %v", len(connections), window)
+
+	var wg sync.WaitGroup
+	for _, conn := range connections {
+		delay := time.Duration(0)
+		if window > 0 {
+			delay = time.Duration(rand.Int64N(int64(window)))
+		}
+
+		wg.Add(1)
+		time.AfterFunc(delay, func() {
+			defer wg.Done()
+			conn.closeWithCode(chat.GatewayErrorCode_ERROR_NODE_DRAINING, "node is draining, reconnect and resume")
+		})
+	}
+	wg.Wait()
+
+	// Guild streams of internal subscribers are closed at once, they resume on another node
+	for _, conn := range h.connManager.getStreams() {
+		conn.closeWithCode(chat.GatewayErrorCode_ERROR_NODE_DRAINING, "node is draining, subscribe again and resume")
+	}
+
+	// A session is removed once its read loop returned, upstream frames are produced inside that loop,
+	// so no produce of a session is in flight anymore once it is gone. Closed virtual sessions are not
+	// waited for, a closed session refuses upstream frames and is removed by its next poll or expiry.
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for h.connManager.activeCount() > 0 {
+		select {
+		case <-h.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+
+	log.Printf("Gateway drained")
+}
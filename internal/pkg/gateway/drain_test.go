@@ -0,0 +1,34 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chat "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
+)
+
+// TestDrain_VirtualSession tests that a drain does not wait for a closed virtual session
+// that is never polled again, and that its last frame is the drain ERROR.
+func TestDrain_VirtualSession(t *testing.T) {
+	h := newStreamTestHandler(context.Background())
+	conn := NewConnection(context.Background(), "session_1", "user_1", "", "", "", nil)
+	h.connManager.connections[conn.SessionID] = conn
+
+	select {
+	case <-h.StartDrain(time.Millisecond):
+	case <-time.After(time.Second):
+		t.Fatal("drain waited for a closed virtual session")
+	}
+
+	assert.True(t, conn.IsClosed())
+	assert.Equal(t, 0, h.connManager.activeCount())
+	assert.Equal(t, 1, h.connManager.ConnectionCount())
+
+	frames := conn.pending()
+	require.Len(t, frames, 1)
+	assert.Equal(t, chat.GatewayErrorCode_ERROR_NODE_DRAINING, frames[0].frame.Error.Code)
+}
@@ -0,0 +1,453 @@
+package gateway
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	redislib "github.com/redis/go-redis/v9"
+
+	chat "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
+)
+
+// Fallback transports for clients behind proxies that break WebSockets.
+// A session opened over Server-Sent Events or long polling is a virtual connection in the
+// connection manager, so downstream events reach it like any WebSocket session. Frames use
+// the JSON encoding, and upstream frames are POSTed and processed like WebSocket frames.
+//
+// The session is identified by the query parameters guild_id, channel_id, device, and guild_ids and
+// capabilities (comma separated), READY carries its session ID. Polls and POSTs authenticate with an
+// Authorization: Bearer header. EventSource cannot set headers, so a Server-Sent Events stream is
+// opened with a single-use ticket issued by ServeTicket instead, keeping tokens out of URLs.
+
+const (
+	// maxPollFrames caps the frames returned by a single poll
+	maxPollFrames = 100
+
+	// maxUpstreamFrameSize caps the body of a POSTed upstream frame
+	maxUpstreamFrameSize = 64 << 10
+
+	// fallbackWriteTimeout bounds each write to a Server-Sent Events stream
+	fallbackWriteTimeout = 10 * time.Second
+
+	// sseTicketTTL is how long a ticket can be used to open a Server-Sent Events stream
+	sseTicketTTL = 30 * time.Second
+)
+
+// ticketResponse is the body of a ticket request.
+type ticketResponse struct {
+	Ticket string `json:"ticket"`
+
+	// ExpiresIn is the number of seconds the ticket can be used in
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+// pollResponse is the body of a poll, the frames are JSON encoded gateway frames.
+type pollResponse struct {
+	Frames []json.RawMessage `json:"frames"`
+}
+
+// ServeTicket issues a ticket to open a Server-Sent Events stream with, see ServeSSE.
+// The ticket stands for the token of the request, it can be used once within sseTicketTTL.
+//
+// Parameters:
+//   - w: The response writer, 201 with the ticket
+//   - r: The request carrying the token in the Authorization header
+func (h *MessageHandler) ServeTicket(w http.ResponseWriter, r *http.Request) {
+	token := requestToken(r)
+	if _, err := h.tokens.ParseToken(token); err != nil {
+		writeHTTPError(w, newGatewayError(chat.GatewayErrorCode_ERROR_AUTHENTICATION_FAILED, "invalid token"))
+		return
+	}
+
+	ticket, err := newTicket()
+	if err != nil {
+		log.Printf("Failed to generate SSE ticket: %v", err)
+		writeHTTPError(w, err)
+		return
+	}
+	if err := h.redisClient.Set(r.Context(), sseTicketKey(ticket), token, sseTicketTTL); err != nil {
+		log.Printf("Failed to store SSE ticket: %v", err)
+		writeHTTPError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ticketResponse{
+		Ticket:    ticket,
+		ExpiresIn: int64(sseTicketTTL / time.Second),
+	})
+}
+
+// ServeSSE serves a session over Server-Sent Events, each frame being sent as a data event.
+// The stream is opened with the ticket query parameter, see ServeTicket.
+// The session lives as long as the stream, the gateway sends a comment as keepalive
+// every heartbeat interval. Upstream frames are POSTed with the session ID, see ServeSend.
+//
+// Parameters:
+//   - w: The response writer, it must support flushing
+//   - r: The request identifying the session
+func (h *MessageHandler) ServeSSE(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+
+	token, err := h.redeemTicket(r.Context(), r.URL.Query().Get("ticket"))
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	conn, err := h.openVirtual(r, token)
+	if err != nil {
+		log.Printf("Failed to open SSE session from %s: %v", r.RemoteAddr, err)
+		writeHTTPError(w, err)
+		return
+	}
+	defer h.handleDisconnect(conn)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Keep reverse proxies such as nginx from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		log.Printf("SSE is not supported for session %s: %v", conn.SessionID, err)
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(h.config.Websocket.HeartbeatInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-conn.Context().Done():
+			// Closed by the gateway, the frames still queued such as the last ERROR are sent first
+			for _, frame := range conn.pending() {
+				if !writeSSE(rc, w, conn, frame) {
+					return
+				}
+			}
+			return
+		case frame := <-conn.Send:
+			if !writeSSE(rc, w, conn, frame) {
+				return
+			}
+		case <-ticker.C:
+			if !writeSSEPayload(rc, w, conn, []byte(": keepalive\n\n")) {
+				return
+			}
+		}
+	}
+}
+
+// writeSSE writes a frame as a data event, reporting whether the stream is still usable.
+func writeSSE(rc *http.ResponseController, w http.ResponseWriter, conn *Connection, frame *Frame) bool {
+	data, err := frame.Encode(conn.Encoding)
+	if err != nil {
+		log.Printf("Error encoding frame for session %s of user %s: %v", conn.SessionID, conn.UserID, err)
+		return true
+	}
+	return writeSSEPayload(rc, w, conn, fmt.Appendf(nil, "data: %s\n\n", data))
+}
+
+// writeSSEPayload writes and flushes raw event stream data, reporting whether the stream is still usable.
+func writeSSEPayload(rc *http.ResponseController, w http.ResponseWriter, conn *Connection, payload []byte) bool {
+	rc.SetWriteDeadline(time.Now().Add(fallbackWriteTimeout))
+	if _, err := w.Write(payload); err != nil {
+		log.Printf("Error writing SSE to session %s of user %s: %v", conn.SessionID, conn.UserID, err)
+		return false
+	}
+	if err := rc.Flush(); err != nil {
+		log.Printf("Error flushing SSE to session %s of user %s: %v", conn.SessionID, conn.UserID, err)
+		return false
+	}
+	// The stream is alive as long as writes go through
+	conn.UpdateHeartbeat()
+	return true
+}
+
+// ServePoll serves a session over long polling.
+// Without a session_id query parameter a session is opened and the response carries READY.
+// With one, the request waits up to the heartbeat interval for frames and returns those queued.
+// A session that is not polled within twice the heartbeat interval is removed, and polls
+// then fail with ERROR_SESSION_NOT_FOUND so that the client opens a new session. A session closed
+// by the gateway is removed by the poll returning its last frames.
+// Only one poll can wait on a session at a time.
+//
+// Parameters:
+//   - w: The response writer
+//   - r: The request identifying the session
+func (h *MessageHandler) ServePoll(w http.ResponseWriter, r *http.Request) {
+	var conn *Connection
+	var err error
+	wait := time.Duration(h.config.Websocket.HeartbeatInterval) * time.Second
+	if r.URL.Query().Get("session_id") == "" {
+		conn, err = h.openVirtual(r, requestToken(r))
+		// READY is already queued
+		wait = 0
+	} else {
+		conn, err = h.virtualSession(r)
+	}
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	if !conn.polling.CompareAndSwap(false, true) {
+		writeHTTPError(w, newGatewayError(chat.GatewayErrorCode_ERROR_INVALID_REQUEST, "another poll is waiting on session %s", conn.SessionID))
+		return
+	}
+	defer conn.polling.Store(false)
+
+	// A session closed by the gateway is not kept alive by polls
+	if !conn.IsClosed() {
+		conn.UpdateHeartbeat()
+	}
+	frames, open := collectFrames(r.Context(), conn, wait)
+	if open {
+		conn.UpdateHeartbeat()
+	} else {
+		// Closed by the gateway, e.g. draining or a slow consumer, the session is removed
+		// once its last frames such as the ERROR are written
+		defer h.connManager.RemoveConnection(conn.SessionID)
+	}
+
+	if len(frames) == 0 && !open {
+		writeHTTPError(w, newGatewayError(chat.GatewayErrorCode_ERROR_SESSION_NOT_FOUND, "session %s is closed", conn.SessionID))
+		return
+	}
+
+	response := pollResponse{Frames: make([]json.RawMessage, 0, len(frames))}
+	for _, frame := range frames {
+		data, err := frame.Encode(conn.Encoding)
+		if err != nil {
+			log.Printf("Error encoding frame for session %s of user %s: %v", conn.SessionID, conn.UserID, err)
+			continue
+		}
+		response.Frames = append(response.Frames, data)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error writing poll to session %s of user %s: %v", conn.SessionID, conn.UserID, err)
+	}
+}
+
+// ServeSend processes an upstream frame POSTed to a Server-Sent Events or long polling session.
+// The body is a JSON encoded gateway frame, handled like a frame read from a WebSocket:
+// DISPATCH events are validated and produced to Kafka, HEARTBEAT is acknowledged downstream.
+//
+// Parameters:
+//   - w: The response writer, 202 when the frame is accepted
+//   - r: The request carrying the session_id query parameter and the frame
+func (h *MessageHandler) ServeSend(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.virtualSession(r)
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+	// Nothing is produced for a session closed by the gateway, a drain no longer waits for it
+	if conn.IsClosed() {
+		writeHTTPError(w, newGatewayError(chat.GatewayErrorCode_ERROR_SESSION_NOT_FOUND, "session %s is closed", conn.SessionID))
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxUpstreamFrameSize))
+	if err != nil {
+		writeHTTPError(w, newGatewayError(chat.GatewayErrorCode_ERROR_DECODE, "failed to read frame: %v", err))
+		return
+	}
+
+	if err := h.handleFrame(conn, websocket.TextMessage, data); err != nil {
+		log.Printf("Error handling upstream message from user %s: %v", conn.UserID, err)
+		writeHTTPError(w, err)
+		return
+	}
+	conn.UpdateHeartbeat()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// openVirtual identifies a request with the given token and registers a virtual connection with READY queued.
+func (h *MessageHandler) openVirtual(r *http.Request, token string) (*Connection, error) {
+	if h.Draining() {
+		return nil, newGatewayError(chat.GatewayErrorCode_ERROR_NODE_DRAINING, "node is draining, connect to another node")
+	}
+
+	query := r.URL.Query()
+	identify := &chat.Identify{
+		Token:     token,
+		Device:    query.Get("device"),
+		GuildId:   query.Get("guild_id"),
+		ChannelId: query.Get("channel_id"),
+	}
+	if capabilities := query.Get("capabilities"); capabilities != "" {
+		identify.Capabilities = strings.Split(capabilities, ",")
+	}
+	if guildIDs := query.Get("guild_ids"); guildIDs != "" {
+		identify.GuildIds = strings.Split(guildIDs, ",")
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	identity, err := h.identify(ctx, identify)
+	if err != nil {
+		return nil, err
+	}
+	identity.Encoding = EncodingJSON
+
+	conn, err := h.connManager.AddConnection(identity, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Checked once the session is indexed, as in Accept
+	if h.Draining() {
+		h.connManager.RemoveConnection(conn.SessionID)
+		return nil, newGatewayError(chat.GatewayErrorCode_ERROR_NODE_DRAINING, "node is draining, connect to another node")
+	}
+
+	if err := h.sendReady(conn); err != nil {
+		h.connManager.RemoveConnection(conn.SessionID)
+		return nil, err
+	}
+	// READY is queued, live events may follow it
+	h.finishResume(conn, nil)
+	return conn, nil
+}
+
+// virtualSession resolves the virtual connection of the session_id query parameter,
+// which must belong to the user of the request token.
+func (h *MessageHandler) virtualSession(r *http.Request) (*Connection, error) {
+	claims, err := h.tokens.ParseToken(requestToken(r))
+	if err != nil {
+		return nil, newGatewayError(chat.GatewayErrorCode_ERROR_AUTHENTICATION_FAILED, "invalid token")
+	}
+
+	sessionID := r.URL.Query().Get("session_id")
+	conn, ok := h.connManager.GetConnection(sessionID)
+	if !ok || !conn.Virtual() || conn.UserID != claims.UserID {
+		return nil, newGatewayError(chat.GatewayErrorCode_ERROR_SESSION_NOT_FOUND, "session %q not found", sessionID)
+	}
+	return conn, nil
+}
+
+// collectFrames waits up to the given duration for a first frame, then takes the frames already queued.
+//
+// Returns:
+//   - []*Frame: The frames, at most maxPollFrames
+//   - bool: false once the connection is closed and its queue drained
+func collectFrames(ctx context.Context, conn *Connection, wait time.Duration) ([]*Frame, bool) {
+	var frames []*Frame
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case frame := <-conn.Send:
+		frames = append(frames, frame)
+	case <-conn.Context().Done():
+		// Closed, the frames still queued such as the last ERROR are returned below
+	case <-timer.C:
+		// A zero wait still returns the frames already queued
+	case <-ctx.Done():
+		return nil, true
+	}
+
+	for len(frames) < maxPollFrames {
+		// Checked before the queue, nothing is queued once the connection is closed
+		closed := conn.IsClosed()
+		select {
+		case frame := <-conn.Send:
+			frames = append(frames, frame)
+		default:
+			return frames, !closed
+		}
+	}
+	return frames, true
+}
+
+// requestToken extracts the token of a fallback request from the Authorization header.
+// Tokens are never read from the URL, where they would end up in access logs.
+func requestToken(r *http.Request) string {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return token
+}
+
+// redeemTicket exchanges a Server-Sent Events ticket for the token it was issued for.
+// The ticket is deleted, it cannot be used again.
+func (h *MessageHandler) redeemTicket(ctx context.Context, ticket string) (string, error) {
+	if ticket == "" {
+		return "", newGatewayError(chat.GatewayErrorCode_ERROR_AUTHENTICATION_FAILED, "ticket required")
+	}
+	token, err := h.redisClient.GetDel(ctx, sseTicketKey(ticket))
+	if errors.Is(err, redislib.Nil) {
+		return "", newGatewayError(chat.GatewayErrorCode_ERROR_AUTHENTICATION_FAILED, "invalid or expired ticket")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to redeem ticket: %w", err)
+	}
+	return token, nil
+}
+
+// newTicket generates an unguessable Server-Sent Events ticket.
+func newTicket() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// sseTicketKey returns the Redis key of a Server-Sent Events ticket.
+func sseTicketKey(ticket string) string {
+	return fmt.Sprintf("gateway:sse_ticket:%s", ticket)
+}
+
+// writeHTTPError reports an error to a fallback request with the gateway error code in the body.
+func writeHTTPError(w http.ResponseWriter, err error) {
+	code, message := chat.GatewayErrorCode_ERROR_UNKNOWN, "internal error"
+	var gwErr *gatewayError
+	if errors.As(err, &gwErr) {
+		code, message = gwErr.code, gwErr.message
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus(code))
+	json.NewEncoder(w).Encode(map[string]any{
+		"code":  int32(code),
+		"error": message,
+	})
+}
+
+// httpStatus maps a gateway error code to an HTTP status.
+func httpStatus(code chat.GatewayErrorCode) int {
+	switch code {
+	case chat.GatewayErrorCode_ERROR_AUTHENTICATION_FAILED, chat.GatewayErrorCode_ERROR_NOT_AUTHENTICATED:
+		return http.StatusUnauthorized
+	case chat.GatewayErrorCode_ERROR_FORBIDDEN:
+		return http.StatusForbidden
+	case chat.GatewayErrorCode_ERROR_NOT_FOUND, chat.GatewayErrorCode_ERROR_SESSION_NOT_FOUND:
+		return http.StatusNotFound
+	case chat.GatewayErrorCode_ERROR_NODE_DRAINING:
+		return http.StatusServiceUnavailable
+	case chat.GatewayErrorCode_ERROR_UNKNOWN:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
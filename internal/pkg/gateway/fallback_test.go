@@ -0,0 +1,70 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	chat "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
+)
+
+// TestCollectFrames tests that a poll returns the queued frames and reports closed sessions.
+func TestCollectFrames(t *testing.T) {
+	conn := NewConnection(context.Background(), "session_1", "user_1", "web", "guild_1", "", nil)
+	assert.True(t, conn.Virtual())
+
+	// Nothing queued, the poll times out empty
+	frames, open := collectFrames(context.Background(), conn, 10*time.Millisecond)
+	assert.Empty(t, frames)
+	assert.True(t, open)
+
+	event1 := NewDispatch(&chat.WSMessage{MessageId: "message_1"})
+	event2 := NewDispatch(&chat.WSMessage{MessageId: "message_2"})
+	assert.True(t, conn.Enqueue(event1, "message_1"))
+	assert.True(t, conn.Enqueue(event2, "message_2"))
+
+	frames, open = collectFrames(context.Background(), conn, time.Second)
+	assert.Equal(t, []*Frame{event1, event2}, frames)
+	assert.True(t, open)
+
+	// A closed session delivers its last ERROR frame, then reports the end
+	conn.closeWithCode(chat.GatewayErrorCode_ERROR_NODE_DRAINING, "node is draining")
+	assert.True(t, conn.IsClosed())
+
+	frames, open = collectFrames(context.Background(), conn, time.Second)
+	assert.Len(t, frames, 1)
+	assert.Equal(t, chat.GatewayErrorCode_ERROR_NODE_DRAINING, frames[0].frame.Error.Code)
+	assert.False(t, open)
+
+	frames, open = collectFrames(context.Background(), conn, time.Second)
+	assert.Empty(t, frames)
+	assert.False(t, open)
+}
+
+// TestRequestToken tests that fallback requests authenticate by header only.
+func TestRequestToken(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/poll?token=query", nil)
+	assert.Empty(t, requestToken(r), "tokens are not read from the URL")
+
+	r.Header.Set("Authorization", "Bearer header")
+	assert.Equal(t, "header", requestToken(r))
+
+	assert.Empty(t, requestToken(httptest.NewRequest(http.MethodGet, "/poll", nil)))
+}
+
+// TestNewTicket tests that tickets are URL safe and unique.
+func TestNewTicket(t *testing.T) {
+	ticket1, err := newTicket()
+	assert.NoError(t, err)
+	ticket2, err := newTicket()
+	assert.NoError(t, err)
+
+	assert.Len(t, ticket1, 43)
+	assert.NotEqual(t, ticket1, ticket2)
+	assert.Equal(t, url.QueryEscape(ticket1), ticket1)
+}
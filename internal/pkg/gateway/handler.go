@@ -2,9 +2,11 @@ package gateway
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -15,6 +17,35 @@ import (
 	"github.com/Gopher0727/ChatRoom/internal/pkg/kafka"
 	chat "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
 	redis "github.com/Gopher0727/ChatRoom/internal/pkg/redis"
+	"github.com/Gopher0727/ChatRoom/internal/service"
+)
+
+// ReadStateTracker records read positions acknowledged by clients
+// and provides the unread state sent in READY.
+type ReadStateTracker interface {
+	Ack(ctx context.Context, userID, guildID, channelID string, seqID int64) error
+	GetUserGuilds(ctx context.Context, userID string) ([]*service.GuildWithReadState, error)
+}
+
+// GuildMembership checks guild membership before a connection subscribes to a guild.
+type GuildMembership interface {
+	IsMember(ctx context.Context, userID string, guildID string) (bool, error)
+}
+
+// MessageHistory loads the messages a resuming session missed.
+type MessageHistory interface {
+	GetMessagesWithUser(ctx context.Context, req *service.GetMessagesRequest) (*service.HistoryPage, error)
+}
+
+const (
+	// maxResumePositions caps the timelines a single IDENTIFY can resume
+	maxResumePositions = 100
+
+	// maxResumeReplay caps the messages replayed per timeline, the rest is left to the history API
+	maxResumeReplay = 1000
+
+	// resumePageSize is the number of messages loaded per history query while replaying
+	resumePageSize = 100
 )
 
 // MessageHandler handles Websocket message processing for the gateway.
@@ -23,9 +54,20 @@ type MessageHandler struct {
 	connManager   *ConnectionManager
 	kafkaProducer *kafka.Producer
 	redisClient   redis.RedisClient
+	readStates    ReadStateTracker
+	presence      PresenceTracker
+	guilds        GuildMembership
+	history       MessageHistory
+	tokens        TokenParser
+	channels      ChannelResolver
 	config        *config.Config
 	ctx           context.Context
 	cancel        context.CancelFunc
+
+	// draining is set once the gateway refuses new sessions, see StartDrain
+	draining  atomic.Bool
+	drainOnce sync.Once
+	drained   chan struct{}
 }
 
 // NewMessageHandler creates a new MessageHandler instance.
@@ -35,6 +77,12 @@ type MessageHandler struct {
 //   - connManager: Connection manager for Websocket connections
 //   - kafkaProducer: Kafka producer for upstream messages
 //   - redisClient: Redis client for Pub/Sub
+//   - readStates: Tracker for read positions acknowledged by clients
+//   - presence: Tracker for statuses set by clients
+//   - guilds: Membership check for guild subscriptions
+//   - history: Message history used to replay missed messages on resume
+//   - tokens: Token validation for IDENTIFY
+//   - channels: Channel lookup for sessions opened for a channel
 //   - cfg: Application configuration
 //
 // Returns:
@@ -44,6 +92,12 @@ func NewMessageHandler(
 	connManager *ConnectionManager,
 	kafkaProducer *kafka.Producer,
 	redisClient redis.RedisClient,
+	readStates ReadStateTracker,
+	presence PresenceTracker,
+	guilds GuildMembership,
+	history MessageHistory,
+	tokens TokenParser,
+	channels ChannelResolver,
 	cfg *config.Config,
 ) *MessageHandler {
 	handlerCtx, cancel := context.WithCancel(ctx)
@@ -52,9 +106,16 @@ func NewMessageHandler(
 		connManager:   connManager,
 		kafkaProducer: kafkaProducer,
 		redisClient:   redisClient,
+		readStates:    readStates,
+		presence:      presence,
+		guilds:        guilds,
+		history:       history,
+		tokens:        tokens,
+		channels:      channels,
 		config:        cfg,
 		ctx:           handlerCtx,
 		cancel:        cancel,
+		drained:       make(chan struct{}),
 	}
 }
 
@@ -106,9 +167,9 @@ func (h *MessageHandler) readPump(conn *Connection) {
 		// Handle different message types
 		switch messageType {
 		case websocket.TextMessage, websocket.BinaryMessage:
-			if err := h.handleUpstreamMessage(conn, data); err != nil {
+			if err := h.handleFrame(conn, messageType, data); err != nil {
 				log.Printf("Error handling upstream message from user %s: %v", conn.UserID, err)
-				h.sendError(conn, fmt.Sprintf("Failed to process message: %v", err))
+				h.sendError(conn, err)
 			}
 		case websocket.PingMessage:
 			// Ping is handled automatically by the library
@@ -129,17 +190,12 @@ func (h *MessageHandler) writePump(conn *Connection) {
 		select {
 		case <-conn.Context().Done():
 			return
-		case message, ok := <-conn.Send:
-			if !ok {
-				// Channel closed
-				conn.WriteMessage(websocket.CloseMessage, []byte{})
-				return
-			}
-
+		case frame := <-conn.Send:
 			// Set write deadline
 			conn.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 
-			if err := conn.WriteMessage(websocket.BinaryMessage, message); err != nil {
+			// Serialized in the encoding negotiated by the connection
+			if err := conn.WriteFrame(frame); err != nil {
 				log.Printf("Error writing message to user %s: %v", conn.UserID, err)
 				return
 			}
@@ -155,39 +211,96 @@ func (h *MessageHandler) writePump(conn *Connection) {
 	}
 }
 
-// handleUpstreamMessage processes an upstream message from the client.
+// handleFrame processes an upstream frame of an identified session.
+//
+// Parameters:
+//   - conn: The connection that sent the frame
+//   - messageType: The WebSocket message type, it must match the negotiated encoding
+//   - data: The raw frame data
+//
+// Returns:
+//   - error: Any error encountered during processing, a *gatewayError carries its code
+func (h *MessageHandler) handleFrame(conn *Connection, messageType int, data []byte) error {
+	frame, err := decodeFrame(data, conn.Encoding, messageType)
+	if err != nil {
+		return err
+	}
+
+	switch frame.Op {
+	case chat.GatewayOpcode_DISPATCH:
+		if frame.Event == nil {
+			return newGatewayError(chat.GatewayErrorCode_ERROR_INVALID_REQUEST, "DISPATCH frame without event")
+		}
+		return h.handleUpstreamMessage(conn, frame.Event)
+	case chat.GatewayOpcode_HEARTBEAT:
+		conn.UpdateHeartbeat()
+		if !conn.Virtual() {
+			conn.Conn.SetReadDeadline(time.Now().Add(time.Duration(h.config.Websocket.ConnectionTimeout) * time.Second))
+		}
+
+		ack := newFrame(&chat.GatewayFrame{Op: chat.GatewayOpcode_HEARTBEAT_ACK})
+		if !conn.push(ack, 1*time.Second) {
+			log.Printf("Timeout sending heartbeat ack to session %s of user %s", conn.SessionID, conn.UserID)
+		}
+		return nil
+	case chat.GatewayOpcode_IDENTIFY:
+		return newGatewayError(chat.GatewayErrorCode_ERROR_ALREADY_AUTHENTICATED, "session is already identified")
+	default:
+		return newGatewayError(chat.GatewayErrorCode_ERROR_UNKNOWN_OPCODE, "unknown opcode %s", frame.Op)
+	}
+}
+
+// handleUpstreamMessage processes an upstream event from the client.
 // It validates the message and sends it to Kafka for processing.
 //
 // Parameters:
 //   - conn: The connection that sent the message
-//   - data: The raw message data
+//   - wsMsg: The event carried by a DISPATCH frame
 //
 // Returns:
 //   - error: Any error encountered during processing
-func (h *MessageHandler) handleUpstreamMessage(conn *Connection, data []byte) error {
-	// Parse the message
-	var wsMsg chat.WSMessage
-	if err := proto.Unmarshal(data, &wsMsg); err != nil {
-		// Try JSON as fallback
-		if jsonErr := json.Unmarshal(data, &wsMsg); jsonErr != nil {
-			return fmt.Errorf("failed to parse message: protobuf error: %w, json error: %v", err, jsonErr)
-		}
+func (h *MessageHandler) handleUpstreamMessage(conn *Connection, wsMsg *chat.WSMessage) error {
+	// Read acknowledgements, typing indicators, status changes and control frames
+	// are handled here and never reach Kafka
+	switch wsMsg.Type {
+	case chat.MessageType_ACK:
+		return h.handleAck(conn, wsMsg)
+	case chat.MessageType_TYPING:
+		return h.handleTyping(conn, wsMsg)
+	case chat.MessageType_PRESENCE_UPDATE:
+		return h.handlePresenceUpdate(conn, wsMsg)
+	case chat.MessageType_GUILD_SUBSCRIBE, chat.MessageType_GUILD_UNSUBSCRIBE:
+		return h.handleGuildSubscription(conn, wsMsg)
 	}
 
 	// Validate message
-	if err := h.validateMessage(&wsMsg, conn); err != nil {
-		return fmt.Errorf("message validation failed: %w", err)
+	if err := h.validateMessage(wsMsg, conn); err != nil {
+		return newGatewayError(chat.GatewayErrorCode_ERROR_INVALID_REQUEST, "message validation failed: %v", err)
 	}
 
 	// Set user ID from connection (prevent spoofing)
 	wsMsg.UserId = conn.UserID
-	if conn.GuildID != "" {
-		wsMsg.GuildId = conn.GuildID
+	if wsMsg.ConversationId != "" {
+		// Direct messages are not scoped to the guild the connection is attached to
+		wsMsg.GuildId = ""
+		wsMsg.ChannelId = ""
+	} else {
+		if wsMsg.GuildId == "" {
+			wsMsg.GuildId = conn.GuildID
+		}
+		if wsMsg.GuildId == conn.GuildID && conn.ChannelID != "" {
+			wsMsg.ChannelId = conn.ChannelID
+		}
 	}
 	wsMsg.Timestamp = time.Now().UnixMilli()
 
+	// The thread is resolved from ReplyToId by the message service
+	wsMsg.ThreadRootId = ""
+	// Recipients are resolved from the conversation by the message service
+	wsMsg.RecipientIds = nil
+
 	// Serialize message
-	msgData, err := proto.Marshal(&wsMsg)
+	msgData, err := proto.Marshal(wsMsg)
 	if err != nil {
 		return fmt.Errorf("failed to serialize message: %w", err)
 	}
@@ -195,6 +308,9 @@ func (h *MessageHandler) handleUpstreamMessage(conn *Connection, data []byte) er
 	// Send to Kafka
 	topic := h.config.Kafka.Topics.Message
 	key := []byte(wsMsg.GuildId) // Use guild ID as key for partitioning
+	if wsMsg.ConversationId != "" {
+		key = []byte(wsMsg.ConversationId)
+	}
 
 	_, _, err = h.kafkaProducer.Produce(h.ctx, topic, key, msgData)
 	if err != nil {
@@ -205,97 +321,402 @@ func (h *MessageHandler) handleUpstreamMessage(conn *Connection, data []byte) er
 	return nil
 }
 
-// validateMessage validates an upstream message.
+// handleAck records the read position acknowledged by the client.
+// The guild and channel default to the ones the connection is attached to.
 //
 // Parameters:
-//   - msg: The message to validate
-//   - conn: The connection that sent the message
+//   - conn: The connection that sent the acknowledgement
+//   - msg: The acknowledgement, SeqId is the last read message
 //
 // Returns:
-//   - error: Validation error if the message is invalid
-func (h *MessageHandler) validateMessage(msg *chat.WSMessage, conn *Connection) error {
-	if msg.Content == "" {
-		return fmt.Errorf("message content cannot be empty")
+//   - error: Any error encountered during processing
+func (h *MessageHandler) handleAck(conn *Connection, msg *chat.WSMessage) error {
+	if msg.ConversationId != "" {
+		return fmt.Errorf("read state is not tracked for direct messages")
 	}
 
-	if len(msg.Content) > 2000 {
-		return fmt.Errorf("message content exceeds maximum length of 2000 characters")
+	guildID := msg.GuildId
+	if guildID == "" {
+		guildID = conn.GuildID
+	}
+	if guildID == "" {
+		return fmt.Errorf("guild ID is required")
 	}
 
-	// Ensure user is sending to their connected guild
-	// If conn.GuildID is empty, we assume it's a global connection and allow sending to any guild
-	if conn.GuildID != "" && msg.GuildId != "" && msg.GuildId != conn.GuildID {
-		return fmt.Errorf("cannot send message to guild %s, connected to guild %s", msg.GuildId, conn.GuildID)
+	channelID := msg.ChannelId
+	if channelID == "" && guildID == conn.GuildID {
+		channelID = conn.ChannelID
 	}
 
+	ctx, cancel := context.WithTimeout(h.ctx, 5*time.Second)
+	defer cancel()
+
+	if err := h.readStates.Ack(ctx, conn.UserID, guildID, channelID, msg.SeqId); err != nil {
+		return fmt.Errorf("failed to ack: %w", err)
+	}
 	return nil
 }
 
-// sendError sends an error message to the client.
+// handleTyping fans a typing indicator out to the guild through Redis Pub/Sub.
+// Indicators are ephemeral: they are never persisted nor assigned a seq ID, and are
+// dropped silently when the user sent one for the same timeline within the throttle interval.
 //
 // Parameters:
-//   - conn: The connection to send the error to
-//   - errorMsg: The error message
-func (h *MessageHandler) sendError(conn *Connection, errorMsg string) {
-	errMsg := &chat.WSMessage{
-		Type:      chat.MessageType_SYSTEM,
-		Content:   errorMsg,
-		Timestamp: time.Now().Unix(),
+//   - conn: The connection that sent the indicator
+//   - msg: The typing indicator
+//
+// Returns:
+//   - error: Any error encountered during processing
+func (h *MessageHandler) handleTyping(conn *Connection, msg *chat.WSMessage) error {
+	if msg.ConversationId != "" {
+		return fmt.Errorf("typing indicators are not supported for direct messages")
+	}
+	guildID := msg.GuildId
+	if guildID == "" {
+		guildID = conn.GuildID
+	}
+	if guildID == "" {
+		return fmt.Errorf("guild ID is required")
+	}
+	// Membership was checked when the guild was subscribed, indicators skip the services
+	if !conn.Subscribed(guildID) {
+		return fmt.Errorf("cannot send typing indicator to guild %s, connection is not subscribed to it", guildID)
 	}
 
-	data, err := proto.Marshal(errMsg)
-	if err != nil {
-		log.Printf("Failed to marshal error message: %v", err)
-		return
+	channelID := msg.ChannelId
+	if guildID == conn.GuildID && conn.ChannelID != "" {
+		if channelID != "" && channelID != conn.ChannelID {
+			return fmt.Errorf("cannot send typing indicator to channel %s, connected to channel %s", channelID, conn.ChannelID)
+		}
+		channelID = conn.ChannelID
 	}
 
-	select {
-	case conn.Send <- data:
-	case <-time.After(5 * time.Second):
-		log.Printf("Timeout sending error message to user %s", conn.UserID)
+	ctx, cancel := context.WithTimeout(h.ctx, 2*time.Second)
+	defer cancel()
+
+	throttleKey := fmt.Sprintf("typing:%s:%s:%s", conn.UserID, guildID, channelID)
+	ok, err := h.redisClient.SetNX(ctx, throttleKey, 1, h.typingInterval())
+	if err != nil {
+		return fmt.Errorf("failed to throttle typing indicator: %w", err)
+	}
+	if !ok {
+		return nil
 	}
-}
 
-// StartSubscriber starts the Redis Pub/Sub subscriber for downstream messages.
-// It subscribes to guild channels and forwards messages to connected clients.
-func (h *MessageHandler) StartSubscriber(pattern string) error {
-	// Subscribe to pattern
-	pubsub, err := h.redisClient.PSubscribe(h.ctx, pattern)
+	event := &chat.WSMessage{
+		UserId:    conn.UserID,
+		GuildId:   guildID,
+		ChannelId: channelID,
+		Timestamp: time.Now().UnixMilli(),
+		Type:      chat.MessageType_TYPING,
+		TtlMs:     h.typingTTL().Milliseconds(),
+	}
+	data, err := proto.Marshal(event)
 	if err != nil {
-		return fmt.Errorf("failed to subscribe to pattern %s: %w", pattern, err)
+		return fmt.Errorf("failed to serialize typing indicator: %w", err)
 	}
 
-	// Start message receiver
-	go h.receiveMessages(pubsub)
+	channel := guildChannel(guildID)
+	if err := h.redisClient.Publish(ctx, channel, data); err != nil {
+		return fmt.Errorf("failed to publish typing indicator: %w", err)
+	}
+	return nil
+}
 
-	log.Printf("Subscribed to redis pattern: %s", pattern)
+// handlePresenceUpdate sets the status chosen by the user and their custom status text.
+//
+// Parameters:
+//   - conn: The connection that sent the update
+//   - msg: The update, carrying Status and CustomStatus
+//
+// Returns:
+//   - error: Any error encountered during processing
+func (h *MessageHandler) handlePresenceUpdate(conn *Connection, msg *chat.WSMessage) error {
+	ctx, cancel := context.WithTimeout(h.ctx, 5*time.Second)
+	defer cancel()
+
+	if err := h.presence.SetStatus(ctx, conn.UserID, msg.Status, msg.CustomStatus); err != nil {
+		return fmt.Errorf("failed to set status: %w", err)
+	}
 	return nil
 }
 
-// receiveMessages receives messages from Redis Pub/Sub and forwards them to clients.
+// handleGuildSubscription subscribes or unsubscribes the connection to the guilds in GuildIds.
+// Subscribing requires guild membership. The client is answered with a frame of the same
+// type listing every guild the connection is now subscribed to.
 //
 // Parameters:
-//   - pubsub: The Redis Pub/Sub subscription
-func (h *MessageHandler) receiveMessages(pubsub *redislib.PubSub) {
-	defer pubsub.Close()
+//   - conn: The connection that sent the control frame
+//   - msg: The control frame
+//
+// Returns:
+//   - error: Any error encountered during processing, earlier guilds of the frame stay applied
+func (h *MessageHandler) handleGuildSubscription(conn *Connection, msg *chat.WSMessage) error {
+	if len(msg.GuildIds) == 0 {
+		return fmt.Errorf("guild IDs are required")
+	}
+	if len(msg.GuildIds) > maxGuildSubscriptions {
+		return fmt.Errorf("cannot subscribe to more than %d guilds", maxGuildSubscriptions)
+	}
 
-	ch := pubsub.Channel()
+	ctx, cancel := context.WithTimeout(h.ctx, 5*time.Second)
+	defer cancel()
 
-	for {
-		select {
-		case <-h.ctx.Done():
-			return
-		case msg, ok := <-ch:
-			if !ok {
-				log.Println("Pub/Sub channel closed")
-				return
+	for _, guildID := range msg.GuildIds {
+		if msg.Type == chat.MessageType_GUILD_UNSUBSCRIBE {
+			if _, err := h.connManager.UnsubscribeGuild(conn, guildID); err != nil {
+				return err
 			}
+			continue
+		}
+
+		if conn.Subscribed(guildID) {
+			continue
+		}
+		isMember, err := h.guilds.IsMember(ctx, conn.UserID, guildID)
+		if err != nil {
+			return fmt.Errorf("failed to check guild membership: %w", err)
+		}
+		if !isMember {
+			return newGatewayError(chat.GatewayErrorCode_ERROR_FORBIDDEN, "user is not a member of guild %s", guildID)
+		}
+		if _, err := h.connManager.SubscribeGuild(conn, guildID); err != nil {
+			return err
+		}
+	}
 
-			if err := h.handleDownstreamMessage(msg); err != nil {
-				log.Printf("Error handling downstream message: %v", err)
+	reply := &chat.WSMessage{
+		Type:      msg.Type,
+		UserId:    conn.UserID,
+		GuildIds:  conn.Guilds(),
+		Timestamp: time.Now().UnixMilli(),
+	}
+	// Queued like live events, after the frames held while resuming
+	if !conn.Enqueue(NewDispatch(reply), "") {
+		log.Printf("Failed to queue subscription reply to session %s of user %s", conn.SessionID, conn.UserID)
+	}
+	return nil
+}
+
+// handleResume replays the messages a reconnecting session missed, then switches it to live delivery.
+// The client sends in IDENTIFY the last seq_id it received for each guild or channel timeline, the guilds
+// other than the one of the session must be listed in the guild_ids of IDENTIFY. Live events
+// received since the connection was opened are held back during the replay and queued after it, without
+// the messages the replay already sent, so that no message is missed or delivered twice. The guild
+// subscriptions are confirmed by Redis before READY, so live delivery has started when the replay runs.
+// The replay ends with a RESUME event carrying the last replayed seq_id per timeline, sent before any
+// held event. Only new messages are replayed, edits and reactions missed meanwhile are not.
+// A position of a timeline the session is not subscribed to is answered with an ERROR frame and skipped.
+//
+// Parameters:
+//   - conn: The resuming connection
+//   - resumePositions: The last seq_id the client received per timeline
+//
+// Returns:
+//   - error: Any error encountered during processing, the session goes live in any case
+func (h *MessageHandler) handleResume(conn *Connection, resumePositions []*chat.ResumePosition) error {
+	if !conn.Resuming() {
+		return fmt.Errorf("session was not opened for resuming")
+	}
+
+	replayed := make(map[string]struct{})
+	defer func() {
+		h.finishResume(conn, replayed)
+	}()
+
+	if len(resumePositions) > maxResumePositions {
+		return fmt.Errorf("cannot resume more than %d timelines", maxResumePositions)
+	}
+
+	positions := make([]*chat.ResumePosition, 0, len(resumePositions))
+	for _, position := range resumePositions {
+		// Without a position there is nothing to resume from, the client loads the history instead
+		if position.SeqId <= 0 {
+			continue
+		}
+		// A position the session does not receive is reported and skipped, the other timelines are still replayed
+		if !conn.Subscribed(position.GuildId) || !conn.Wants(position.GuildId, position.ChannelId) {
+			h.sendError(conn, newGatewayError(chat.GatewayErrorCode_ERROR_INVALID_REQUEST,
+				"cannot resume guild %s channel %s, the session is not subscribed to it", position.GuildId, position.ChannelId))
+			continue
+		}
+
+		replayedPosition, err := h.replay(conn, position, replayed)
+		if err != nil {
+			return err
+		}
+		positions = append(positions, replayedPosition)
+	}
+
+	reply := &chat.WSMessage{
+		Type:            chat.MessageType_RESUME,
+		UserId:          conn.UserID,
+		ResumePositions: positions,
+		Timestamp:       time.Now().UnixMilli(),
+	}
+	if !conn.push(NewDispatch(reply), 5*time.Second) {
+		return fmt.Errorf("failed to send resume reply")
+	}
+
+	log.Printf("Session %s of user %s resumed, replayed %d messages", conn.SessionID, conn.UserID, len(replayed))
+	return nil
+}
+
+// replay sends the messages of a timeline after the client's position, oldest first.
+//
+// Parameters:
+//   - conn: The resuming connection
+//   - position: The last seq_id the client received in the timeline
+//   - replayed: Collects the IDs of the replayed messages
+//
+// Returns:
+//   - *chat.ResumePosition: The last replayed seq_id, HasMore is set when the replay stopped at its limit
+//   - error: Any error encountered during replay
+func (h *MessageHandler) replay(conn *Connection, position *chat.ResumePosition, replayed map[string]struct{}) (*chat.ResumePosition, error) {
+	result := &chat.ResumePosition{
+		GuildId:   position.GuildId,
+		ChannelId: position.ChannelId,
+		SeqId:     position.SeqId,
+	}
+
+	for count := 0; ; {
+		ctx, cancel := context.WithTimeout(conn.Context(), 5*time.Second)
+		page, err := h.history.GetMessagesWithUser(ctx, &service.GetMessagesRequest{
+			UserID:     conn.UserID,
+			GuildID:    position.GuildId,
+			ChannelID:  position.ChannelId,
+			AfterSeqID: result.SeqId,
+			Limit:      resumePageSize,
+		})
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load missed messages of guild %s: %w", position.GuildId, err)
+		}
+
+		for _, message := range page.Messages {
+			if !conn.push(NewDispatch(message.ToWSMessage()), 5*time.Second) {
+				return nil, fmt.Errorf("failed to replay message %s", message.ID)
 			}
+			replayed[message.ID] = struct{}{}
+			result.SeqId = message.SeqID
+		}
+
+		count += len(page.Messages)
+		if !page.HasMoreAfter || len(page.Messages) == 0 {
+			return result, nil
 		}
+		if count >= maxResumeReplay {
+			result.HasMore = true
+			return result, nil
+		}
+	}
+}
+
+// finishResume switches a resuming or new session to live delivery. When live events had to be
+// dropped meanwhile, the session is closed so that the client resumes again from its last position.
+//
+// Parameters:
+//   - conn: The resuming connection
+//   - replayed: The IDs of the messages sent by the replay
+func (h *MessageHandler) finishResume(conn *Connection, replayed map[string]struct{}) {
+	if conn.release(replayed) {
+		return
+	}
+
+	log.Printf("Session %s of user %s dropped events while resuming, closing it", conn.SessionID, conn.UserID)
+	conn.closeResumable("too many events while resuming, resume again")
+}
+
+// typingInterval returns how often a user can send a typing indicator for the same timeline.
+func (h *MessageHandler) typingInterval() time.Duration {
+	if h.config.Websocket.TypingInterval <= 0 {
+		return 3 * time.Second
+	}
+	return time.Duration(h.config.Websocket.TypingInterval) * time.Second
+}
+
+// typingTTL returns how long clients should show a typing indicator.
+func (h *MessageHandler) typingTTL() time.Duration {
+	if h.config.Websocket.TypingTTL <= 0 {
+		return 8 * time.Second
+	}
+	return time.Duration(h.config.Websocket.TypingTTL) * time.Second
+}
+
+// validateMessage validates an upstream message.
+//
+// Parameters:
+//   - msg: The message to validate
+//   - conn: The connection that sent the message
+//
+// Returns:
+//   - error: Validation error if the message is invalid
+func (h *MessageHandler) validateMessage(msg *chat.WSMessage, conn *Connection) error {
+	// Edits and deletions go through the API so that authorship can be checked
+	if msg.Type != chat.MessageType_TEXT {
+		return fmt.Errorf("unsupported upstream message type %s", msg.Type)
+	}
+
+	// Attachments are uploaded through the API beforehand, the message only references them
+	if msg.Content == "" && len(msg.Attachments) == 0 {
+		return fmt.Errorf("message content cannot be empty")
 	}
+
+	if len(msg.Content) > 2000 {
+		return fmt.Errorf("message content exceeds maximum length of 2000 characters")
+	}
+
+	if len(msg.Nonce) > 64 {
+		return fmt.Errorf("nonce exceeds maximum length of 64 characters")
+	}
+
+	// Direct messages can be sent from any connection, participation is checked by the message service
+	if msg.ConversationId != "" {
+		return nil
+	}
+
+	// Ensure user is sending to a guild the connection is subscribed to
+	// If conn.GuildID is empty, we assume it's a global connection and allow sending to any guild
+	if conn.GuildID != "" && msg.GuildId != "" && !conn.Subscribed(msg.GuildId) {
+		return fmt.Errorf("cannot send message to guild %s, connection is not subscribed to it", msg.GuildId)
+	}
+
+	// Likewise a channel-targeted connection can only send to its channel of the guild it was opened for
+	targetsOwnGuild := msg.GuildId == "" || msg.GuildId == conn.GuildID
+	if targetsOwnGuild && conn.ChannelID != "" && msg.ChannelId != "" && msg.ChannelId != conn.ChannelID {
+		return fmt.Errorf("cannot send message to channel %s, connected to channel %s", msg.ChannelId, conn.ChannelID)
+	}
+
+	return nil
+}
+
+// sendError sends an ERROR frame to the client.
+// Protocol errors keep their code, any other error is reported as ERROR_UNKNOWN.
+//
+// Parameters:
+//   - conn: The connection to send the error to
+//   - err: The error
+func (h *MessageHandler) sendError(conn *Connection, err error) {
+	code, message := chat.GatewayErrorCode_ERROR_UNKNOWN, fmt.Sprintf("Failed to process message: %v", err)
+	var gwErr *gatewayError
+	if errors.As(err, &gwErr) {
+		code, message = gwErr.code, gwErr.message
+	}
+
+	// Serialized by the write pump in the encoding of the connection
+	if !conn.push(newErrorFrame(code, message), 5*time.Second) {
+		log.Printf("Timeout sending error message to user %s", conn.UserID)
+	}
+}
+
+// StartGuildSubscriber starts receiving downstream messages of guild channels.
+// Rather than every guild, this node only subscribes to the guilds its connections are subscribed to,
+// the connection manager subscribes and unsubscribes guild channels as connections come and go.
+func (h *MessageHandler) StartGuildSubscriber() error {
+	if err := h.connManager.subscriber.start(h.ctx, h.handleDownstreamMessage); err != nil {
+		return fmt.Errorf("failed to start guild subscriber: %w", err)
+	}
+	return nil
 }
 
 // handleDownstreamMessage processes a downstream message from Redis Pub/Sub.
@@ -313,6 +734,11 @@ func (h *MessageHandler) handleDownstreamMessage(msg *redislib.Message) error {
 		return fmt.Errorf("failed to unmarshal message: %w", err)
 	}
 
+	// Ephemeral events that expired while in flight are not worth delivering
+	if wsMsg.TtlMs > 0 && time.Now().UnixMilli() > wsMsg.Timestamp+wsMsg.TtlMs {
+		return nil
+	}
+
 	// Extract guild ID from channel name (format: "guild:{guildID}")
 	guildID := wsMsg.GuildId
 
@@ -324,11 +750,8 @@ func (h *MessageHandler) handleDownstreamMessage(msg *redislib.Message) error {
 		return nil
 	}
 
-	// Serialize message once
-	msgData, err := proto.Marshal(&wsMsg)
-	if err != nil {
-		return fmt.Errorf("failed to serialize message: %w", err)
-	}
+	// Serialized at most once per encoding in use
+	frame := NewDispatch(&wsMsg)
 
 	// Push message to all connections
 	successCount := 0
@@ -338,11 +761,25 @@ func (h *MessageHandler) handleDownstreamMessage(msg *redislib.Message) error {
 		// 	continue
 		// }
 
-		select {
-		case conn.Send <- msgData:
+		// Skip connections watching another channel of the guild
+		if !conn.Wants(guildID, wsMsg.ChannelId) {
+			continue
+		}
+
+		// Users don't need to see their own typing indicator
+		if wsMsg.Type == chat.MessageType_TYPING && conn.UserID == wsMsg.UserId {
+			continue
+		}
+
+		// Skip optional events the client did not opt into
+		if !acceptsEvent(conn, wsMsg.Type) {
+			continue
+		}
+
+		if conn.Enqueue(frame, replayKey(&wsMsg)) {
 			successCount++
-		case <-time.After(1 * time.Second):
-			log.Printf("Timeout sending message to user %s", conn.UserID)
+		} else {
+			log.Printf("Send queue of session %s of user %s is full", conn.SessionID, conn.UserID)
 		}
 	}
 
@@ -350,10 +787,19 @@ func (h *MessageHandler) handleDownstreamMessage(msg *redislib.Message) error {
 	return nil
 }
 
+// replayKey returns the message ID of new message events, which a resume replay may also send.
+func replayKey(wsMsg *chat.WSMessage) string {
+	if wsMsg.Type != chat.MessageType_TEXT {
+		return ""
+	}
+	return wsMsg.MessageId
+}
+
 // handleDisconnect handles connection disconnection and cleanup.
+// Only the disconnected session is cleaned up, other sessions of the user stay connected.
 // It performs comprehensive cleanup including:
 // 1. Resource cleanup - closes connection and removes from manager
-// 2. Online status update - removes user from Redis online status
+// 2. Online status update - updates the user's session count in Redis
 // 3. Detailed logging - logs all disconnect events and errors
 //
 // Parameters:
@@ -362,40 +808,39 @@ func (h *MessageHandler) handleDisconnect(conn *Connection) {
 	startTime := time.Now()
 
 	// Log initial disconnect event with context
-	log.Printf("[DISCONNECT] User %s disconnecting from guild %s (connection established: %v ago)",
-		conn.UserID, conn.GuildID, time.Since(conn.GetLastHeartbeat()))
+	log.Printf("[DISCONNECT] User %s disconnecting session %s from guild %s (last heartbeat: %v ago)",
+		conn.UserID, conn.SessionID, conn.GuildID, time.Since(conn.GetLastHeartbeat()))
 
 	// Step 1: Remove connection from manager
 	// This will also handle Redis online status cleanup
-	if err := h.connManager.RemoveConnection(conn.UserID); err != nil {
-		log.Printf("[DISCONNECT ERROR] Failed to remove connection for user %s: %v", conn.UserID, err)
+	if err := h.connManager.RemoveConnection(conn.SessionID); err != nil {
+		log.Printf("[DISCONNECT ERROR] Failed to remove session %s of user %s: %v", conn.SessionID, conn.UserID, err)
 	} else {
-		log.Printf("[DISCONNECT] Successfully removed user %s from connection manager", conn.UserID)
+		log.Printf("[DISCONNECT] Successfully removed session %s of user %s from connection manager", conn.SessionID, conn.UserID)
 	}
 
 	// Step 2: Close the Websocket connection
-	// This releases network resources and closes the send channel
+	// This releases network resources and stops the write pump
 	if err := conn.Close(); err != nil {
 		log.Printf("[DISCONNECT ERROR] Failed to close Websocket for user %s: %v", conn.UserID, err)
 	} else {
 		log.Printf("[DISCONNECT] Successfully closed Websocket for user %s", conn.UserID)
 	}
 
-	// Step 3: Verify online status was removed from Redis
+	// Step 3: Verify the session count in Redis matches the sessions left on this node
 	// This is a verification step to ensure cleanup was successful
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
-	if isOnline, err := h.redisClient.IsUserOnline(ctx, conn.UserID); err != nil {
-		log.Printf("[DISCONNECT WARNING] Could not verify online status removal for user %s: %v", conn.UserID, err)
-	} else if isOnline {
-		log.Printf("[DISCONNECT WARNING] User %s still appears online in Redis after disconnect", conn.UserID)
-		// Attempt to force remove
-		if err := h.redisClient.RemoveUserOnline(ctx, conn.UserID); err != nil {
-			log.Printf("[DISCONNECT ERROR] Failed to force remove online status for user %s: %v", conn.UserID, err)
-		}
+	remaining := len(h.connManager.GetUserConnections(conn.UserID))
+	if sessions, err := h.redisClient.GetUserSessions(ctx, conn.UserID); err != nil {
+		log.Printf("[DISCONNECT WARNING] Could not verify online status of user %s: %v", conn.UserID, err)
+	} else if sessions[h.connManager.nodeID] != remaining {
+		log.Printf("[DISCONNECT WARNING] User %s has %d sessions on this node but Redis records %d, it will be corrected on the next heartbeat round",
+			conn.UserID, remaining, sessions[h.connManager.nodeID])
 	} else {
-		log.Printf("[DISCONNECT] Verified user %s online status removed from Redis", conn.UserID)
+		log.Printf("[DISCONNECT] Verified user %s has %d sessions left on this node and %d nodes overall",
+			conn.UserID, remaining, len(sessions))
 	}
 
 	// Log completion with timing
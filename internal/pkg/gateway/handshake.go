@@ -0,0 +1,318 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/Gopher0727/ChatRoom/internal/model"
+	chat "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
+	"github.com/Gopher0727/ChatRoom/internal/service"
+	"github.com/Gopher0727/ChatRoom/middleware/jwt"
+)
+
+// TokenParser validates the token sent in IDENTIFY.
+type TokenParser interface {
+	ParseToken(tokenString string) (*jwt.Claims, error)
+}
+
+// ChannelResolver resolves the channel a session is opened for, checking that the user can access it.
+type ChannelResolver interface {
+	GetChannel(ctx context.Context, userID, channelID string) (*model.Channel, error)
+}
+
+const (
+	// maxDeviceLength caps the device label of a session
+	maxDeviceLength = 64
+
+	// maxCloseReasonLength caps the reason of a close frame, which must fit in a control frame
+	maxCloseReasonLength = 120
+)
+
+// Identity describes an identified session, as resolved from IDENTIFY.
+type Identity struct {
+	// UserID is the authenticated user
+	UserID string
+
+	// Device is a client supplied label for the session (optional)
+	Device string
+
+	// GuildID is the guild the session is opened for (optional)
+	GuildID string
+
+	// ChannelID is the channel the session targets inside GuildID (optional)
+	ChannelID string
+
+	// GuildIDs are further guilds the session is subscribed to from the start (optional)
+	GuildIDs []string
+
+	// Capabilities are the optional events the client opted into
+	Capabilities []string
+
+	// Resume holds live events back until the messages the client missed are replayed
+	Resume bool
+
+	// Encoding is the serialization of the frames, negotiated when the WebSocket connected
+	Encoding Encoding
+}
+
+// Accept runs the gateway handshake on an upgraded WebSocket and starts serving the session.
+// The gateway sends HELLO, the client answers with IDENTIFY within the identify timeout, and the
+// gateway registers the session and answers with READY, live events are held back until READY is
+// queued. When IDENTIFY carries resume positions, the messages missed since then are replayed before
+// live delivery starts.
+// A failed handshake is reported in an ERROR frame and closes the WebSocket with the error code.
+//
+// Parameters:
+//   - ws: The upgraded WebSocket connection
+//   - encoding: The encoding negotiated for the frames, see NegotiateEncoding
+func (h *MessageHandler) Accept(ws *websocket.Conn, encoding Encoding) {
+	identity, identify, err := h.handshake(ws, encoding)
+	if err != nil {
+		log.Printf("Gateway handshake from %s failed: %v", ws.RemoteAddr(), err)
+		h.reject(ws, encoding, err)
+		return
+	}
+	identity.Encoding = encoding
+
+	conn, err := h.connManager.AddConnection(identity, ws)
+	if err != nil {
+		log.Printf("Failed to add connection: %v", err)
+		h.reject(ws, encoding, err)
+		return
+	}
+
+	// Checked once the session is indexed, a drain either sees the session or the session sees the drain
+	if h.Draining() {
+		h.reject(ws, encoding, newGatewayError(chat.GatewayErrorCode_ERROR_NODE_DRAINING, "node is draining, connect to another node"))
+		h.connManager.RemoveConnection(conn.SessionID)
+		return
+	}
+
+	if err := h.sendReady(conn); err != nil {
+		log.Printf("Failed to send READY to session %s of user %s: %v", conn.SessionID, conn.UserID, err)
+		h.reject(ws, encoding, err)
+		h.connManager.RemoveConnection(conn.SessionID)
+		return
+	}
+
+	// READY is queued, live events follow it unless the replay goes first
+	if !identity.Resume {
+		h.finishResume(conn, nil)
+	}
+
+	h.HandleConnection(conn)
+
+	if identity.Resume {
+		if err := h.handleResume(conn, identify.ResumePositions); err != nil {
+			log.Printf("Failed to resume session %s of user %s: %v", conn.SessionID, conn.UserID, err)
+			h.sendError(conn, err)
+		}
+	}
+}
+
+// handshake sends HELLO and waits for the client to identify.
+//
+// Returns:
+//   - *Identity: The identified session
+//   - *chat.Identify: The IDENTIFY payload
+//   - error: Any error encountered, a *gatewayError is reported to the client
+func (h *MessageHandler) handshake(ws *websocket.Conn, encoding Encoding) (*Identity, *chat.Identify, error) {
+	identifyTimeout := time.Duration(h.config.Websocket.ConnectionTimeout) * time.Second
+
+	hello := newFrame(&chat.GatewayFrame{
+		Op: chat.GatewayOpcode_HELLO,
+		Hello: &chat.Hello{
+			HeartbeatIntervalMs: (time.Duration(h.config.Websocket.HeartbeatInterval) * time.Second).Milliseconds(),
+			IdentifyTimeoutMs:   identifyTimeout.Milliseconds(),
+		},
+	})
+	ws.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	if err := writeFrame(ws, encoding, hello); err != nil {
+		return nil, nil, fmt.Errorf("failed to send HELLO: %w", err)
+	}
+
+	ws.SetReadDeadline(time.Now().Add(identifyTimeout))
+	messageType, data, err := ws.ReadMessage()
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return nil, nil, newGatewayError(chat.GatewayErrorCode_ERROR_SESSION_TIMEOUT, "IDENTIFY was not received in time")
+		}
+		return nil, nil, fmt.Errorf("failed to read IDENTIFY: %w", err)
+	}
+
+	frame, err := decodeFrame(data, encoding, messageType)
+	if err != nil {
+		return nil, nil, err
+	}
+	if frame.Op != chat.GatewayOpcode_IDENTIFY || frame.Identify == nil {
+		return nil, nil, newGatewayError(chat.GatewayErrorCode_ERROR_NOT_AUTHENTICATED, "the first frame must be IDENTIFY, got %s", frame.Op)
+	}
+	if frame.Version != ProtocolVersion {
+		return nil, nil, newGatewayError(chat.GatewayErrorCode_ERROR_UNSUPPORTED_VERSION, "protocol version %d is not supported, use %d", frame.Version, ProtocolVersion)
+	}
+
+	ctx, cancel := context.WithTimeout(h.ctx, 5*time.Second)
+	defer cancel()
+
+	identity, err := h.identify(ctx, frame.Identify)
+	if err != nil {
+		return nil, nil, err
+	}
+	return identity, frame.Identify, nil
+}
+
+// identify authenticates the token of IDENTIFY and resolves the guild and channel of the session.
+func (h *MessageHandler) identify(ctx context.Context, identify *chat.Identify) (*Identity, error) {
+	if identify.Token == "" {
+		return nil, newGatewayError(chat.GatewayErrorCode_ERROR_AUTHENTICATION_FAILED, "token required")
+	}
+	claims, err := h.tokens.ParseToken(identify.Token)
+	if err != nil {
+		return nil, newGatewayError(chat.GatewayErrorCode_ERROR_AUTHENTICATION_FAILED, "invalid token")
+	}
+
+	if len(identify.Device) > maxDeviceLength {
+		return nil, newGatewayError(chat.GatewayErrorCode_ERROR_INVALID_REQUEST, "device label is too long")
+	}
+	if len(identify.ResumePositions) > maxResumePositions {
+		return nil, newGatewayError(chat.GatewayErrorCode_ERROR_INVALID_REQUEST, "cannot resume more than %d timelines", maxResumePositions)
+	}
+	if len(identify.GuildIds) >= maxGuildSubscriptions {
+		return nil, newGatewayError(chat.GatewayErrorCode_ERROR_INVALID_REQUEST, "cannot subscribe to more than %d guilds", maxGuildSubscriptions)
+	}
+
+	identity := &Identity{
+		UserID:    claims.UserID,
+		Device:    identify.Device,
+		GuildID:   identify.GuildId,
+		ChannelID: identify.ChannelId,
+		Resume:    len(identify.ResumePositions) > 0,
+	}
+
+	// Unknown capabilities are ignored, READY lists the accepted ones
+	for _, capability := range identify.Capabilities {
+		if slices.Contains(identity.Capabilities, capability) {
+			continue
+		}
+		for _, known := range capabilityEvents {
+			if capability == known {
+				identity.Capabilities = append(identity.Capabilities, capability)
+				break
+			}
+		}
+	}
+
+	// Resolve the target channel, the guild stays the membership boundary
+	if identity.ChannelID != "" {
+		channel, err := h.channels.GetChannel(ctx, identity.UserID, identity.ChannelID)
+		if err != nil {
+			switch err {
+			case service.ErrChannelNotFound:
+				return nil, newGatewayError(chat.GatewayErrorCode_ERROR_NOT_FOUND, "%v", err)
+			case service.ErrUserNotInGuild:
+				return nil, newGatewayError(chat.GatewayErrorCode_ERROR_FORBIDDEN, "%v", err)
+			default:
+				return nil, fmt.Errorf("failed to resolve channel: %w", err)
+			}
+		}
+		if identity.GuildID != "" && identity.GuildID != channel.GuildID {
+			return nil, newGatewayError(chat.GatewayErrorCode_ERROR_INVALID_REQUEST, "channel does not belong to guild")
+		}
+		identity.GuildID = channel.GuildID
+	} else if identity.GuildID != "" {
+		// Ephemeral events such as typing indicators skip the services, check membership up front
+		isMember, err := h.guilds.IsMember(ctx, identity.UserID, identity.GuildID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check guild membership: %w", err)
+		}
+		if !isMember {
+			return nil, newGatewayError(chat.GatewayErrorCode_ERROR_FORBIDDEN, "%v", service.ErrUserNotInGuild)
+		}
+	}
+
+	// Further guilds are subscribed before READY, so that resume positions can refer to them
+	for _, guildID := range identify.GuildIds {
+		if guildID == "" || guildID == identity.GuildID || slices.Contains(identity.GuildIDs, guildID) {
+			continue
+		}
+		isMember, err := h.guilds.IsMember(ctx, identity.UserID, guildID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check guild membership: %w", err)
+		}
+		if !isMember {
+			return nil, newGatewayError(chat.GatewayErrorCode_ERROR_FORBIDDEN, "user is not a member of guild %s", guildID)
+		}
+		identity.GuildIDs = append(identity.GuildIDs, guildID)
+	}
+
+	return identity, nil
+}
+
+// sendReady sends READY with the user's guilds, unread state and presence to a new session.
+func (h *MessageHandler) sendReady(conn *Connection) error {
+	ctx, cancel := context.WithTimeout(h.ctx, 5*time.Second)
+	defer cancel()
+
+	guilds, err := h.readStates.GetUserGuilds(ctx, conn.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user guilds: %w", err)
+	}
+	presence, err := h.presence.GetPresence(ctx, conn.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get presence: %w", err)
+	}
+
+	ready := &chat.Ready{
+		SessionId:    conn.SessionID,
+		UserId:       conn.UserID,
+		Status:       presence.Status,
+		CustomStatus: presence.CustomStatus,
+		Capabilities: conn.Capabilities(),
+		GuildIds:     conn.Guilds(),
+	}
+	for _, guild := range guilds {
+		ready.Guilds = append(ready.Guilds, &chat.ReadyGuild{
+			Id:           guild.ID,
+			Name:         guild.Name,
+			UnreadCount:  guild.UnreadCount,
+			MentionCount: guild.MentionCount,
+		})
+	}
+
+	frame := newFrame(&chat.GatewayFrame{
+		Op:    chat.GatewayOpcode_READY,
+		Ready: ready,
+	})
+	if !conn.push(frame, 5*time.Second) {
+		return fmt.Errorf("failed to queue READY")
+	}
+	return nil
+}
+
+// reject reports a handshake failure to the client and closes the WebSocket.
+// Protocol errors use their code as close code, any other error is reported as ERROR_UNKNOWN.
+func (h *MessageHandler) reject(ws *websocket.Conn, encoding Encoding, err error) {
+	code, message := chat.GatewayErrorCode_ERROR_UNKNOWN, "internal error"
+	var gwErr *gatewayError
+	if errors.As(err, &gwErr) {
+		code, message = gwErr.code, gwErr.message
+	}
+
+	ws.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	writeFrame(ws, encoding, newErrorFrame(code, message))
+	// Control frames are limited to 125 bytes, the ERROR frame carries the full message
+	reason := message
+	if len(reason) > maxCloseReasonLength {
+		reason = reason[:maxCloseReasonLength]
+	}
+	ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(int(code), reason))
+	ws.Close()
+}
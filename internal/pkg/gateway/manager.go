@@ -3,34 +3,96 @@ package gateway
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
+	"log"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/Gopher0727/ChatRoom/config"
+	chat "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
 	redis "github.com/Gopher0727/ChatRoom/internal/pkg/redis"
+	"github.com/Gopher0727/ChatRoom/internal/service"
 )
 
+// PresenceTracker keeps user presence in sync with gateway connections.
+type PresenceTracker interface {
+	Connected(ctx context.Context, userID string) error
+	Disconnected(ctx context.Context, userID string) error
+	Refresh(ctx context.Context, userIDs []string) error
+	SetStatus(ctx context.Context, userID, status, customStatus string) error
+	GetPresence(ctx context.Context, userID string) (*service.Presence, error)
+}
+
+// maxGuildSubscriptions caps how many guilds a single connection can subscribe to.
+const maxGuildSubscriptions = 200
+
+// userLockShards is the number of locks serializing the session count updates of users.
+const userLockShards = 64
+
+// userLocks serializes the session count updates of each user, so that the count stored in Redis
+// follows the order of the changes without holding the manager lock over a network round trip.
+type userLocks [userLockShards]sync.Mutex
+
+// get returns the lock responsible for a user.
+func (l *userLocks) get(userID string) *sync.Mutex {
+	h := fnv.New32a()
+	h.Write([]byte(userID))
+	return &l[h.Sum32()%userLockShards]
+}
+
 // ConnectionManager manages all WebSocket connections for the gateway.
+// A user can hold several concurrent sessions, e.g. one per device, each being a Connection.
 // It provides thread-safe operations for adding, removing, and retrieving connections.
 // It also handles heartbeat monitoring to detect and clean up dead connections.
 type ConnectionManager struct {
-	// connections maps userID to their Connection
+	// connections maps sessionID to its Connection
 	connections map[string]*Connection
 
-	// mu protects the connections map
+	// userSessions maps userID to the user's connections on this node, keyed by sessionID
+	userSessions map[string]map[string]*Connection
+
+	// streams maps sessionID to the guild streams of internal subscribers, see StreamGuilds.
+	// They are indexed by guild like connections but are no user sessions.
+	streams map[string]*Connection
+
+	// mu protects the connections, userSessions and streams maps, it is never held over Redis calls
 	mu sync.RWMutex
 
+	// userLocks orders the Redis session count updates of each user
+	userLocks userLocks
+
+	// guilds indexes connections by subscribed guild, it is sharded with its own locks
+	// so that broadcasts never take mu
+	guilds *guildIndex
+
+	// subscriber subscribes this node to the Pub/Sub channels of the guilds in the index
+	subscriber *guildSubscriber
+
 	// config holds the WebSocket configuration
 	config *config.WebsocketConfig
 
+	// slowConsumer is the policy applied to connections whose send queue is full
+	slowConsumer SlowConsumerPolicy
+
+	// sendCounters counts the frames lost to slow consumers on this node
+	sendCounters sendCounters
+
 	// redisClient is used to update user online status
 	redisClient redis.RedisClient
 
+	// presence is notified when users connect and disconnect
+	presence PresenceTracker
+
 	// nodeID is the unique identifier for this gateway node
 	nodeID string
 
+	// address is the gRPC address other nodes use to push events to this node
+	address string
+
 	// ctx is the manager context
 	ctx context.Context
 
@@ -47,22 +109,39 @@ type ConnectionManager struct {
 //   - ctx: Parent context for the manager
 //   - cfg: WebSocket configuration
 //   - redisClient: Redis client for online status management
+//   - presence: Presence tracker notified on connect and disconnect
 //   - nodeID: Unique identifier for this gateway node
+//   - address: gRPC address of this node, registered in the node registry
 //
 // Returns:
 //   - *ConnectionManager: The initialized connection manager
-func NewConnectionManager(ctx context.Context, cfg *config.WebsocketConfig, redisClient redis.RedisClient, nodeID string) *ConnectionManager {
+func NewConnectionManager(ctx context.Context, cfg *config.WebsocketConfig, redisClient redis.RedisClient, presence PresenceTracker, nodeID, address string) *ConnectionManager {
 	managerCtx, cancel := context.WithCancel(ctx)
 
+	slowConsumer, err := ParseSlowConsumerPolicy(cfg.SlowConsumerPolicy)
+	if err != nil {
+		fmt.Printf("Warning: %v, disconnecting slow consumers\n", err)
+	}
+
 	cm := &ConnectionManager{
-		connections: make(map[string]*Connection),
-		config:      cfg,
-		redisClient: redisClient,
-		nodeID:      nodeID,
-		ctx:         managerCtx,
-		cancel:      cancel,
+		connections:  make(map[string]*Connection),
+		userSessions: make(map[string]map[string]*Connection),
+		streams:      make(map[string]*Connection),
+		guilds:       newGuildIndex(),
+		subscriber:   newGuildSubscriber(redisClient),
+		config:       cfg,
+		slowConsumer: slowConsumer,
+		redisClient:  redisClient,
+		presence:     presence,
+		nodeID:       nodeID,
+		address:      address,
+		ctx:          managerCtx,
+		cancel:       cancel,
 	}
 
+	// Register the node so that events for its users can be routed to it
+	cm.registerNode()
+
 	// Start heartbeat monitor
 	cm.wg.Add(1)
 	go cm.monitorHeartbeats()
@@ -70,131 +149,222 @@ func NewConnectionManager(ctx context.Context, cfg *config.WebsocketConfig, redi
 	return cm
 }
 
-// AddConnection adds a new WebSocket connection to the manager.
-// If a connection already exists for the user, it will be closed and replaced.
-// This supports multiple browser windows (Requirement 6.4).
+// AddConnection adds a new WebSocket session to the manager.
+// Existing sessions of the user are kept, so the user can be connected
+// from several devices or browser windows at once (Requirement 6.4).
 //
 // Parameters:
-//   - userID: The user identifier
-//   - guildID: The guild identifier
+//   - identity: The identified user and the guild, channel and capabilities of the session
 //   - conn: The WebSocket connection
 //
 // Returns:
-//   - *Connection: The created connection object
+//   - *Connection: The created connection object, identified by its SessionID, holding live events until released
 //   - error: Any error encountered during addition
-func (cm *ConnectionManager) AddConnection(userID, guildID string, conn *websocket.Conn) (*Connection, error) {
+func (cm *ConnectionManager) AddConnection(identity *Identity, conn *websocket.Conn) (*Connection, error) {
+	userID, guildID := identity.UserID, identity.GuildID
+
+	userLock := cm.userLocks.get(userID)
+	userLock.Lock()
 	cm.mu.Lock()
-	defer cm.mu.Unlock()
 
-	// Close existing connection if present
-	if existingConn, exists := cm.connections[userID]; exists {
-		existingConn.Close()
+	// Create new session
+	connection := NewConnection(cm.ctx, uuid.New().String(), userID, identity.Device, guildID, identity.ChannelID, conn)
+	connection.setCapabilities(identity.Capabilities)
+	connection.Encoding = identity.Encoding
+	connection.configureSendQueue(cm.config.SendQueueSize, cm.slowConsumer, &cm.sendCounters)
+	// Held before the session is indexed, so that no live event gets ahead of READY,
+	// or of the replay when resuming. The handshake releases it once READY is queued.
+	connection.hold()
+	cm.connections[connection.SessionID] = connection
+	if cm.userSessions[userID] == nil {
+		cm.userSessions[userID] = make(map[string]*Connection)
 	}
+	cm.userSessions[userID][connection.SessionID] = connection
+	count := len(cm.userSessions[userID])
+	cm.mu.Unlock()
 
-	// Create new connection
-	connection := NewConnection(cm.ctx, userID, guildID, conn)
-	cm.connections[userID] = connection
-
-	// Update Redis online status
-	// TTL is set to 2x heartbeat interval to allow for network delays
-	ttl := time.Duration(cm.config.HeartbeatInterval*2) * time.Second
-	if err := cm.redisClient.SetUserOnline(cm.ctx, userID, cm.nodeID, ttl); err != nil {
+	// Update Redis online status with the number of sessions on this node
+	// The write happens under the user's lock so that concurrent sessions of a user cannot store a stale count
+	if err := cm.syncUserSessions(cm.ctx, userID, count); err != nil {
 		// Log error but don't fail the connection
 		// This is a degraded mode where online status tracking is unavailable
 		fmt.Printf("Warning: failed to set user %s online in Redis: %v\n", userID, err)
 	}
+	userLock.Unlock()
+
+	// The subscriptions are confirmed before READY, so that a replay sees what live delivery misses
+	var subscribed []<-chan struct{}
+	if guildID != "" {
+		subscribed = append(subscribed, cm.joinGuild(guildID, connection))
+	}
+	for _, extra := range identity.GuildIDs {
+		if connection.subscribe(extra) {
+			subscribed = append(subscribed, cm.joinGuild(extra, connection))
+		}
+	}
+	cm.awaitSubscribed(subscribed)
+
+	// Presence updates touch the database and Pub/Sub, so they run outside the lock
+	ctx, cancel := context.WithTimeout(cm.ctx, 2*time.Second)
+	defer cancel()
+	if err := cm.presence.Connected(ctx, userID); err != nil {
+		fmt.Printf("Warning: failed to update presence for user %s: %v\n", userID, err)
+	}
 
 	return connection, nil
 }
 
-// RemoveConnection removes a WebSocket connection from the manager.
+// RemoveConnection removes a single WebSocket session from the manager.
+// Other sessions of the same user are left untouched.
 // It performs comprehensive cleanup including:
-// 1. Closing the WebSocket connection
-// 2. Removing from the connections map
-// 3. Updating Redis to remove online status
-// 4. Logging all cleanup steps
+// 1. Removing from the connections map
+// 2. Closing the WebSocket connection
+// 3. Removing from the guild index
+// 4. Updating the user's session count in Redis
+// 5. Switching the user's presence to offline once no session is left on any node
+// 6. Logging all cleanup steps
+// Only step 1 runs under the manager lock, the others may wait on the network.
 //
 // Parameters:
-//   - userID: The user identifier
+//   - sessionID: The session identifier
 //
 // Returns:
 //   - error: Any error encountered during removal
-func (cm *ConnectionManager) RemoveConnection(userID string) error {
-	cm.mu.Lock()
-	defer cm.mu.Unlock()
-
-	conn, exists := cm.connections[userID]
+func (cm *ConnectionManager) RemoveConnection(sessionID string) error {
+	conn, exists := cm.GetConnection(sessionID)
 	if !exists {
-		return fmt.Errorf("connection not found for user %s", userID)
+		return fmt.Errorf("connection not found for session %s", sessionID)
+	}
+	userID := conn.UserID
+
+	userLock := cm.userLocks.get(userID)
+	userLock.Lock()
+
+	// Step 1: Remove from connections map, unless a concurrent removal did meanwhile
+	cm.mu.Lock()
+	if _, exists := cm.connections[sessionID]; !exists {
+		cm.mu.Unlock()
+		userLock.Unlock()
+		return fmt.Errorf("connection not found for session %s", sessionID)
 	}
+	delete(cm.connections, sessionID)
+	delete(cm.userSessions[userID], sessionID)
+	count := len(cm.userSessions[userID])
+	if count == 0 {
+		delete(cm.userSessions, userID)
+	}
+	remaining := len(cm.connections)
+	cm.mu.Unlock()
 
 	// Log the removal with connection details
-	fmt.Printf("[MANAGER] Removing connection for user %s (guild: %s)\n", userID, conn.GuildID)
+	fmt.Printf("[MANAGER] Removing session %s of user %s (guild: %s, device: %s)\n", sessionID, userID, conn.GuildID, conn.Device)
 
-	// Step 1: Close the connection to release resources
+	// Step 2: Close the connection to release resources
 	if err := conn.Close(); err != nil {
-		fmt.Printf("[MANAGER ERROR] Error closing connection for user %s: %v\n", userID, err)
+		fmt.Printf("[MANAGER ERROR] Error closing session %s of user %s: %v\n", sessionID, userID, err)
 		// Continue with cleanup even if close fails
 	} else {
-		fmt.Printf("[MANAGER] Connection closed for user %s\n", userID)
+		fmt.Printf("[MANAGER] Session %s of user %s closed\n", sessionID, userID)
 	}
 
-	// Step 2: Remove from connections map
-	delete(cm.connections, userID)
-	fmt.Printf("[MANAGER] User %s removed from connections map (remaining: %d)\n", userID, len(cm.connections))
+	// Step 3: Remove from the guild index, which may unsubscribe guild channels
+	for _, guildID := range conn.Guilds() {
+		cm.leaveGuild(guildID, sessionID)
+	}
+	fmt.Printf("[MANAGER] Session %s removed from connections map (remaining: %d)\n", sessionID, remaining)
 
-	// Step 3: Remove from Redis online status
+	// Step 4: Update the session count in Redis
 	ctx, cancel := context.WithTimeout(cm.ctx, 2*time.Second)
 	defer cancel()
 
-	if err := cm.redisClient.RemoveUserOnline(ctx, userID); err != nil {
-		fmt.Printf("[MANAGER ERROR] Failed to remove user %s online status from Redis: %v\n", userID, err)
+	err := cm.syncUserSessions(ctx, userID, count)
+	userLock.Unlock()
+	if err != nil {
+		fmt.Printf("[MANAGER ERROR] Failed to update sessions of user %s in Redis: %v\n", userID, err)
 		// Return error but cleanup is still partially successful
-		return fmt.Errorf("failed to remove online status: %w", err)
+		return fmt.Errorf("failed to update online status: %w", err)
+	}
+
+	// Step 5: Switch presence to offline once the user has no session on any node,
+	// outside the user's lock as it touches the database and Pub/Sub
+	online, err := cm.redisClient.IsUserOnline(ctx, userID)
+	if err != nil {
+		fmt.Printf("[MANAGER ERROR] Failed to check online status of user %s: %v\n", userID, err)
+	} else if !online {
+		if err := cm.presence.Disconnected(ctx, userID); err != nil {
+			fmt.Printf("[MANAGER ERROR] Failed to update presence for user %s: %v\n", userID, err)
+		}
 	}
 
-	fmt.Printf("[MANAGER] User %s online status removed from Redis\n", userID)
-	fmt.Printf("[MANAGER] Successfully removed connection for user %s\n", userID)
+	fmt.Printf("[MANAGER] Successfully removed session %s of user %s\n", sessionID, userID)
 
 	return nil
 }
 
-// GetConnection retrieves a WebSocket connection by user ID.
+// syncUserSessions stores the number of sessions the user holds on this node in Redis.
+// The caller must hold the user's lock, not cm.mu.
+func (cm *ConnectionManager) syncUserSessions(ctx context.Context, userID string, count int) error {
+	// TTL is set to 2x heartbeat interval to allow for network delays
+	ttl := time.Duration(cm.config.HeartbeatInterval*2) * time.Second
+	return cm.redisClient.SetUserSessions(ctx, userID, cm.nodeID, count, ttl)
+}
+
+// GetConnection retrieves a WebSocket connection by session ID.
 //
 // Parameters:
-//   - userID: The user identifier
+//   - sessionID: The session identifier
 //
 // Returns:
 //   - *Connection: The connection object, or nil if not found
 //   - bool: true if the connection exists, false otherwise
-func (cm *ConnectionManager) GetConnection(userID string) (*Connection, bool) {
+func (cm *ConnectionManager) GetConnection(sessionID string) (*Connection, bool) {
 	cm.mu.RLock()
 	defer cm.mu.RUnlock()
 
-	conn, exists := cm.connections[userID]
+	conn, exists := cm.connections[sessionID]
 	return conn, exists
 }
 
+// GetUserConnections returns all sessions of a user on this node.
+//
+// Parameters:
+//   - userID: The user identifier
+//
+// Returns:
+//   - []*Connection: A slice of the user's connections, empty if the user is not connected
+func (cm *ConnectionManager) GetUserConnections(userID string) []*Connection {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	connections := make([]*Connection, 0, len(cm.userSessions[userID]))
+	for _, conn := range cm.userSessions[userID] {
+		connections = append(connections, conn)
+	}
+
+	return connections
+}
+
 // GetAllConnections returns a snapshot of all current connections.
 // The returned map is a copy and safe to iterate over.
 //
 // Returns:
-//   - map[string]*Connection: A map of userID to Connection
+//   - map[string]*Connection: A map of sessionID to Connection
 func (cm *ConnectionManager) GetAllConnections() map[string]*Connection {
 	cm.mu.RLock()
 	defer cm.mu.RUnlock()
 
 	// Create a copy to avoid holding the lock during iteration
 	snapshot := make(map[string]*Connection, len(cm.connections))
-	for userID, conn := range cm.connections {
-		snapshot[userID] = conn
+	for sessionID, conn := range cm.connections {
+		snapshot[sessionID] = conn
 	}
 
 	return snapshot
 }
 
-// GetConnectionsByGuild returns all connections for a specific guild.
-// This is used for broadcasting messages to all users in a guild.
+// GetConnectionsByGuild returns all connections subscribed to a specific guild.
+// This is used for broadcasting messages to all users in a guild,
+// it only visits the guild's subscribers and does not take the manager lock.
 //
 // Parameters:
 //   - guildID: The guild identifier
@@ -202,26 +372,228 @@ func (cm *ConnectionManager) GetAllConnections() map[string]*Connection {
 // Returns:
 //   - []*Connection: A slice of connections for the guild
 func (cm *ConnectionManager) GetConnectionsByGuild(guildID string) []*Connection {
-	cm.mu.RLock()
-	defer cm.mu.RUnlock()
+	return cm.guilds.connections(guildID)
+}
 
-	var connections []*Connection
-	for _, conn := range cm.connections {
-		if conn.GuildID == guildID {
-			connections = append(connections, conn)
+// SubscribeGuild makes a connection receive the events of another guild.
+// The caller is responsible for checking that the user is a member of the guild.
+//
+// Parameters:
+//   - conn: The connection to subscribe
+//   - guildID: The guild identifier
+//
+// Returns:
+//   - bool: true if the subscription was added, false if it already existed
+//   - error: Any error encountered during subscription
+func (cm *ConnectionManager) SubscribeGuild(conn *Connection, guildID string) (bool, error) {
+	if guildID == "" {
+		return false, fmt.Errorf("guild ID is required")
+	}
+	if conn.IsClosed() {
+		return false, fmt.Errorf("session %s is closed", conn.SessionID)
+	}
+	if conn.Subscribed(guildID) {
+		return false, nil
+	}
+	if len(conn.Guilds()) >= maxGuildSubscriptions {
+		return false, fmt.Errorf("cannot subscribe to more than %d guilds", maxGuildSubscriptions)
+	}
+
+	if !conn.subscribe(guildID) {
+		return false, nil
+	}
+	cm.awaitSubscribed([]<-chan struct{}{cm.joinGuild(guildID, conn)})
+
+	// The connection may have been removed meanwhile, don't leave it in the index
+	if conn.IsClosed() {
+		cm.leaveGuild(guildID, conn.SessionID)
+		return false, fmt.Errorf("session %s is closed", conn.SessionID)
+	}
+	return true, nil
+}
+
+// UnsubscribeGuild stops a connection from receiving the events of a guild.
+// The guild the connection was opened for cannot be unsubscribed.
+//
+// Parameters:
+//   - conn: The connection to unsubscribe
+//   - guildID: The guild identifier
+//
+// Returns:
+//   - bool: true if the subscription was removed, false if it did not exist
+//   - error: Any error encountered during unsubscription
+func (cm *ConnectionManager) UnsubscribeGuild(conn *Connection, guildID string) (bool, error) {
+	if guildID == conn.GuildID {
+		return false, fmt.Errorf("cannot unsubscribe from guild %s the connection was opened for", guildID)
+	}
+	if !conn.unsubscribe(guildID) {
+		return false, nil
+	}
+	cm.leaveGuild(guildID, conn.SessionID)
+	return true, nil
+}
+
+// joinGuild adds a connection to the guild index and makes this node receive the guild's channel.
+// The returned channel is closed once the node receives the guild's channel.
+func (cm *ConnectionManager) joinGuild(guildID string, conn *Connection) <-chan struct{} {
+	if cm.guilds.add(guildID, conn) {
+		return cm.subscriber.acquire(cm.ctx, guildID)
+	}
+	return closedChan
+}
+
+// awaitSubscribed waits until Redis confirmed the given guild subscriptions, up to guildSubscribeTimeout.
+// Messages published to a guild before its subscription is confirmed are not received.
+func (cm *ConnectionManager) awaitSubscribed(subscribed []<-chan struct{}) {
+	timer := time.NewTimer(guildSubscribeTimeout)
+	defer timer.Stop()
+
+	for _, done := range subscribed {
+		select {
+		case <-done:
+		case <-timer.C:
+			log.Printf("Guild subscriptions were not confirmed within %v", guildSubscribeTimeout)
+			return
+		case <-cm.ctx.Done():
+			return
 		}
 	}
+}
 
-	return connections
+// leaveGuild removes a session from the guild index and stops receiving the guild's channel
+// once no local connection is subscribed to it.
+func (cm *ConnectionManager) leaveGuild(guildID, sessionID string) {
+	if cm.guilds.remove(guildID, sessionID) {
+		cm.subscriber.release(cm.ctx, guildID)
+	}
 }
 
-// ConnectionCount returns the total number of active connections.
+// openStream registers a guild stream receiving every event of the given guilds.
+// Unlike a session, it has no user, is not tracked as online and does not need heartbeats.
+//
+// Parameters:
+//   - guildIDs: The guilds to receive the events of
+//   - resume: Whether live events are held back until missed messages are replayed
+//
+// Returns:
+//   - *Connection: The virtual connection of the stream
+func (cm *ConnectionManager) openStream(guildIDs []string, resume bool) *Connection {
+	conn := NewConnection(cm.ctx, uuid.New().String(), "", "stream", "", "", nil)
+	// Internal subscribers receive the optional events too
+	capabilities := make([]string, 0, len(capabilityEvents))
+	for _, capability := range capabilityEvents {
+		capabilities = append(capabilities, capability)
+	}
+	conn.setCapabilities(capabilities)
+	conn.configureSendQueue(cm.config.SendQueueSize, cm.slowConsumer, &cm.sendCounters)
+	if resume {
+		// Held before the stream is indexed, so that no live event gets ahead of the replay
+		conn.hold()
+	}
+
+	cm.mu.Lock()
+	cm.streams[conn.SessionID] = conn
+	cm.mu.Unlock()
+
+	subscribed := make([]<-chan struct{}, 0, len(guildIDs))
+	for _, guildID := range guildIDs {
+		if conn.subscribe(guildID) {
+			subscribed = append(subscribed, cm.joinGuild(guildID, conn))
+		}
+	}
+	cm.awaitSubscribed(subscribed)
+	return conn
+}
+
+// closeStream closes a guild stream and removes it from the guild index.
+func (cm *ConnectionManager) closeStream(conn *Connection) {
+	conn.Close()
+	for _, guildID := range conn.Guilds() {
+		cm.leaveGuild(guildID, conn.SessionID)
+	}
+
+	cm.mu.Lock()
+	delete(cm.streams, conn.SessionID)
+	cm.mu.Unlock()
+}
+
+// getStreams returns a snapshot of the open guild streams.
+func (cm *ConnectionManager) getStreams() []*Connection {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	streams := make([]*Connection, 0, len(cm.streams))
+	for _, conn := range cm.streams {
+		streams = append(streams, conn)
+	}
+	return streams
+}
+
+// ConnectionCount returns the total number of active sessions.
 func (cm *ConnectionManager) ConnectionCount() int {
 	cm.mu.RLock()
 	defer cm.mu.RUnlock()
 	return len(cm.connections)
 }
 
+// activeCount returns the number of sessions a drain waits for. A WebSocket session counts until it is
+// removed, once its read loop returned. A virtual session counts until it is closed, its upstream frames
+// are handled by requests and the client may never poll the closed session again.
+func (cm *ConnectionManager) activeCount() int {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	count := 0
+	for _, conn := range cm.connections {
+		if !conn.Virtual() || !conn.IsClosed() {
+			count++
+		}
+	}
+	return count
+}
+
+// PushToUser delivers an event to every session of a user on this node.
+// It is used for direct messages and per-user events, which are routed to the nodes
+// holding the user's sessions instead of being broadcast to every node.
+//
+// Parameters:
+//   - userID: The user identifier
+//   - event: The event to deliver
+//
+// Returns:
+//   - int: The number of sessions the event was queued for
+func (cm *ConnectionManager) PushToUser(userID string, event *chat.WSMessage) int {
+	// Recipients are only used for routing and are not sent to clients,
+	// the event is copied as the caller may share it with other goroutines
+	if len(event.RecipientIds) > 0 {
+		event = proto.Clone(event).(*chat.WSMessage)
+		event.RecipientIds = nil
+	}
+	frame := NewDispatch(event)
+
+	delivered := 0
+	for _, conn := range cm.GetUserConnections(userID) {
+		// Skip optional events the client did not opt into
+		if !acceptsEvent(conn, event.Type) {
+			continue
+		}
+		if conn.Enqueue(frame, replayKey(event)) {
+			delivered++
+		} else {
+			log.Printf("Send queue of session %s of user %s is full", conn.SessionID, conn.UserID)
+		}
+	}
+	return delivered
+}
+
+// SendStats returns the frames lost to slow consumers on this node since it started.
+func (cm *ConnectionManager) SendStats() SendStats {
+	return SendStats{
+		DroppedFrames:           cm.sendCounters.dropped.Load(),
+		SlowConsumerDisconnects: cm.sendCounters.disconnects.Load(),
+	}
+}
+
 // monitorHeartbeats periodically checks all connections for heartbeat timeouts.
 // Connections that haven't sent a heartbeat within the timeout period are closed.
 func (cm *ConnectionManager) monitorHeartbeats() {
@@ -240,6 +612,7 @@ func (cm *ConnectionManager) monitorHeartbeats() {
 			return
 		case <-ticker.C:
 			cm.checkHeartbeats(timeout)
+			cm.registerNode()
 		}
 	}
 }
@@ -247,31 +620,57 @@ func (cm *ConnectionManager) monitorHeartbeats() {
 // checkHeartbeats checks all connections for heartbeat timeouts and closes dead ones.
 func (cm *ConnectionManager) checkHeartbeats(timeout time.Duration) {
 	cm.mu.RLock()
-	// Collect dead connections and active connections
-	var deadConnections []string
-	var activeConnections []string
-	for userID, conn := range cm.connections {
+	// Collect dead sessions and the number of active sessions per user
+	var deadSessions []string
+	activeSessions := make(map[string]int)
+	for sessionID, conn := range cm.connections {
 		if !conn.IsAlive(timeout) {
-			deadConnections = append(deadConnections, userID)
+			deadSessions = append(deadSessions, sessionID)
 		} else {
-			activeConnections = append(activeConnections, userID)
+			activeSessions[conn.UserID]++
 		}
 	}
 	cm.mu.RUnlock()
 
-	// Remove dead connections (requires write lock)
-	for _, userID := range deadConnections {
-		fmt.Printf("Removing dead connection for user %s (heartbeat timeout)\n", userID)
-		cm.RemoveConnection(userID)
+	// Remove dead sessions (requires write lock)
+	for _, sessionID := range deadSessions {
+		fmt.Printf("Removing dead session %s (heartbeat timeout)\n", sessionID)
+		cm.RemoveConnection(sessionID)
 	}
 
-	// Refresh active connections in Redis
-	// We do this without holding the lock to avoid blocking other operations
+	// Refresh active users in Redis
+	// We do this without holding the lock to avoid blocking other operations,
+	// a count that changed meanwhile is corrected on the next round
 	ttl := time.Duration(cm.config.HeartbeatInterval*2) * time.Second
-	for _, userID := range activeConnections {
-		if err := cm.redisClient.SetUserOnline(cm.ctx, userID, cm.nodeID, ttl); err != nil {
+	userIDs := make([]string, 0, len(activeSessions))
+	for userID, count := range activeSessions {
+		if err := cm.redisClient.SetUserSessions(cm.ctx, userID, cm.nodeID, count, ttl); err != nil {
 			fmt.Printf("Failed to refresh online status for user %s: %v\n", userID, err)
 		}
+		userIDs = append(userIDs, userID)
+	}
+
+	// Presence expires like the session counts when this node stops refreshing it
+	if err := cm.presence.Refresh(cm.ctx, userIDs); err != nil {
+		fmt.Printf("Failed to refresh presence of %d users: %v\n", len(userIDs), err)
+	}
+}
+
+// registerNode registers the node in the registry, or renews its registration.
+// The registration expires after 2x the heartbeat interval if the node stops renewing it.
+func (cm *ConnectionManager) registerNode() {
+	ctx, cancel := context.WithTimeout(cm.ctx, 2*time.Second)
+	defer cancel()
+
+	ttl := time.Duration(cm.config.HeartbeatInterval*2) * time.Second
+	node := &redis.GatewayNode{
+		ID:          cm.nodeID,
+		Address:     cm.address,
+		Connections: cm.ConnectionCount(),
+		Heartbeat:   time.Now(),
+	}
+	if err := cm.redisClient.RegisterGatewayNode(ctx, node, ttl); err != nil {
+		fmt.Printf("Warning: failed to register gateway node %s: %v\n", cm.nodeID, err)
 	}
 }
 
@@ -283,14 +682,36 @@ func (cm *ConnectionManager) Shutdown() error {
 
 	// Close all connections
 	cm.mu.Lock()
-	for userID, conn := range cm.connections {
+	for sessionID, conn := range cm.connections {
 		if err := conn.Close(); err != nil {
-			fmt.Printf("Warning: error closing connection for user %s: %v\n", userID, err)
+			fmt.Printf("Warning: error closing session %s of user %s: %v\n", sessionID, conn.UserID, err)
+		}
+		for _, guildID := range conn.Guilds() {
+			cm.guilds.remove(guildID, sessionID)
+		}
+		delete(cm.connections, sessionID)
+	}
+	clear(cm.userSessions)
+	// Ending the guild streams lets their RPCs return
+	for sessionID, conn := range cm.streams {
+		conn.Close()
+		for _, guildID := range conn.Guilds() {
+			cm.guilds.remove(guildID, sessionID)
 		}
-		delete(cm.connections, userID)
+		delete(cm.streams, sessionID)
 	}
 	cm.mu.Unlock()
 
+	// Stop receiving guild channels
+	cm.subscriber.close()
+
+	// Unregister the node, the manager context is canceled by now
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := cm.redisClient.RemoveGatewayNode(ctx, cm.nodeID); err != nil {
+		fmt.Printf("Warning: failed to unregister gateway node %s: %v\n", cm.nodeID, err)
+	}
+
 	// Wait for background goroutines to finish
 	cm.wg.Wait()
 
@@ -0,0 +1,265 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	chat "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
+)
+
+// ProtocolVersion is the version of the gateway protocol spoken on the WebSocket.
+// Every frame is a chat.GatewayFrame envelope carrying an opcode and its payload.
+const ProtocolVersion = 1
+
+// Optional events a client opts into with the capabilities of IDENTIFY.
+const (
+	// CapabilityTyping delivers TYPING events
+	CapabilityTyping = "typing"
+
+	// CapabilityPresence delivers PRESENCE_UPDATE events
+	CapabilityPresence = "presence"
+)
+
+// capabilityEvents maps the optional event types to the capability they require.
+var capabilityEvents = map[chat.MessageType]string{
+	chat.MessageType_TYPING:          CapabilityTyping,
+	chat.MessageType_PRESENCE_UPDATE: CapabilityPresence,
+}
+
+// acceptsEvent reports whether a connection receives an event type, optional events require their capability.
+func acceptsEvent(conn *Connection, eventType chat.MessageType) bool {
+	capability, optional := capabilityEvents[eventType]
+	return !optional || conn.HasCapability(capability)
+}
+
+// gatewayError is a protocol error reported to the client in an ERROR frame.
+type gatewayError struct {
+	code    chat.GatewayErrorCode
+	message string
+}
+
+// newGatewayError creates a protocol error with the given code.
+func newGatewayError(code chat.GatewayErrorCode, format string, args ...any) *gatewayError {
+	return &gatewayError{code: code, message: fmt.Sprintf(format, args...)}
+}
+
+func (e *gatewayError) Error() string {
+	return fmt.Sprintf("%s: %s", e.code, e.message)
+}
+
+// Encoding is the serialization of the frames of a WebSocket, negotiated when it connects.
+// Protobuf frames are sent as binary messages and JSON frames as text messages.
+type Encoding int
+
+const (
+	// EncodingProtobuf serializes frames as protobuf, the default
+	EncodingProtobuf Encoding = iota
+
+	// EncodingJSON serializes frames as JSON, for clients without a protobuf runtime.
+	// It follows the proto3 JSON mapping with the field names of the .proto files, so that
+	// both encodings share one schema: enums are names, 64-bit integers are strings.
+	EncodingJSON
+
+	// encodingCount is the number of encodings
+	encodingCount
+)
+
+// Subprotocols are the WebSocket subprotocols selecting an encoding, in order of preference.
+var Subprotocols = []string{"chat.protobuf", "chat.json"}
+
+// subprotocolEncodings maps each subprotocol to its encoding.
+var subprotocolEncodings = map[string]Encoding{
+	"chat.protobuf": EncodingProtobuf,
+	"chat.json":     EncodingJSON,
+}
+
+// ParseEncoding parses the name of an encoding, either "protobuf" or "json".
+func ParseEncoding(name string) (Encoding, error) {
+	switch name {
+	case "protobuf":
+		return EncodingProtobuf, nil
+	case "json":
+		return EncodingJSON, nil
+	default:
+		return 0, fmt.Errorf("unsupported encoding %q", name)
+	}
+}
+
+// NegotiateEncoding resolves the encoding requested by a WebSocket upgrade request.
+// The encoding query parameter takes precedence over the subprotocols offered by the client,
+// which are matched in the order of Subprotocols like the upgrader does. Without either,
+// frames are encoded as protobuf.
+//
+// Parameters:
+//   - r: The upgrade request
+//
+// Returns:
+//   - Encoding: The negotiated encoding
+//   - error: An error if the requested encoding is not supported
+func NegotiateEncoding(r *http.Request) (Encoding, error) {
+	if name := r.URL.Query().Get("encoding"); name != "" {
+		return ParseEncoding(name)
+	}
+
+	requested := websocket.Subprotocols(r)
+	for _, subprotocol := range Subprotocols {
+		if slices.Contains(requested, subprotocol) {
+			return subprotocolEncodings[subprotocol], nil
+		}
+	}
+	return EncodingProtobuf, nil
+}
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingProtobuf:
+		return "protobuf"
+	case EncodingJSON:
+		return "json"
+	default:
+		return fmt.Sprintf("Encoding(%d)", int(e))
+	}
+}
+
+// messageType returns the WebSocket message type carrying frames of the encoding.
+func (e Encoding) messageType() int {
+	if e == EncodingJSON {
+		return websocket.TextMessage
+	}
+	return websocket.BinaryMessage
+}
+
+// Frame is a downstream frame. It is serialized lazily and at most once per encoding,
+// so that an event fanned out to many connections is encoded once for each encoding in use.
+type Frame struct {
+	frame   *chat.GatewayFrame
+	encoded [encodingCount]encodedFrame
+}
+
+// encodedFrame caches the serialization of a frame in one encoding.
+type encodedFrame struct {
+	once sync.Once
+	data []byte
+	err  error
+}
+
+var (
+	// jsonMarshal serializes JSON frames with the field names of the .proto files
+	jsonMarshal = protojson.MarshalOptions{UseProtoNames: true}
+
+	// jsonUnmarshal parses JSON frames, unknown fields are ignored as in protobuf frames
+	jsonUnmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+)
+
+// NewDispatch wraps an event in a DISPATCH frame.
+//
+// Parameters:
+//   - event: The event to deliver
+//
+// Returns:
+//   - *Frame: The frame, serialized by each connection in its encoding
+func NewDispatch(event *chat.WSMessage) *Frame {
+	return newFrame(&chat.GatewayFrame{
+		Op:    chat.GatewayOpcode_DISPATCH,
+		Event: event,
+	})
+}
+
+// newFrame stamps a frame with the protocol version.
+func newFrame(frame *chat.GatewayFrame) *Frame {
+	frame.Version = ProtocolVersion
+	return &Frame{frame: frame}
+}
+
+// newErrorFrame creates an ERROR frame.
+func newErrorFrame(code chat.GatewayErrorCode, message string) *Frame {
+	return newFrame(&chat.GatewayFrame{
+		Op: chat.GatewayOpcode_ERROR,
+		Error: &chat.GatewayError{
+			Code:    code,
+			Message: message,
+		},
+	})
+}
+
+// Encode serializes the frame in an encoding. The frame must not be modified once encoded.
+//
+// Parameters:
+//   - encoding: The encoding of the receiving connection
+//
+// Returns:
+//   - []byte: The serialized frame
+//   - error: Any error encountered during serialization
+func (f *Frame) Encode(encoding Encoding) ([]byte, error) {
+	if encoding < 0 || encoding >= encodingCount {
+		return nil, fmt.Errorf("unsupported encoding %s", encoding)
+	}
+
+	encoded := &f.encoded[encoding]
+	encoded.once.Do(func() {
+		var err error
+		switch encoding {
+		case EncodingJSON:
+			encoded.data, err = jsonMarshal.Marshal(f.frame)
+		default:
+			encoded.data, err = proto.Marshal(f.frame)
+		}
+		if err != nil {
+			encoded.err = fmt.Errorf("failed to serialize %s frame as %s: %w", f.frame.Op, encoding, err)
+		}
+	})
+	return encoded.data, encoded.err
+}
+
+// writeFrame serializes a frame and writes it to a WebSocket that is not served by a Connection yet.
+func writeFrame(ws *websocket.Conn, encoding Encoding, frame *Frame) error {
+	data, err := frame.Encode(encoding)
+	if err != nil {
+		return err
+	}
+	return ws.WriteMessage(encoding.messageType(), data)
+}
+
+// decodeFrame parses an upstream frame, which must use the negotiated encoding.
+//
+// Parameters:
+//   - data: The raw frame data
+//   - encoding: The negotiated encoding
+//   - messageType: The WebSocket message type the frame was received as
+//
+// Returns:
+//   - *chat.GatewayFrame: The parsed frame
+//   - error: A *gatewayError with ERROR_DECODE if the frame does not match the encoding
+func decodeFrame(data []byte, encoding Encoding, messageType int) (*chat.GatewayFrame, error) {
+	if messageType != encoding.messageType() {
+		return nil, newGatewayError(chat.GatewayErrorCode_ERROR_DECODE,
+			"the session uses %s encoding, frames must be sent as %s messages", encoding, messageTypeName(encoding.messageType()))
+	}
+
+	var frame chat.GatewayFrame
+	switch encoding {
+	case EncodingJSON:
+		if err := jsonUnmarshal.Unmarshal(data, &frame); err != nil {
+			return nil, newGatewayError(chat.GatewayErrorCode_ERROR_DECODE, "failed to parse JSON frame: %v", err)
+		}
+	default:
+		if err := proto.Unmarshal(data, &frame); err != nil {
+			return nil, newGatewayError(chat.GatewayErrorCode_ERROR_DECODE, "failed to parse protobuf frame: %v", err)
+		}
+	}
+	return &frame, nil
+}
+
+// messageTypeName names the WebSocket data message types.
+func messageTypeName(messageType int) string {
+	if messageType == websocket.TextMessage {
+		return "text"
+	}
+	return "binary"
+}
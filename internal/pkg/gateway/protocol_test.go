@@ -0,0 +1,142 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	chat "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
+)
+
+// TestFrame_Encode tests that a frame is versioned and serialized in each encoding.
+func TestFrame_Encode(t *testing.T) {
+	frame := NewDispatch(&chat.WSMessage{
+		MessageId: "msg_1",
+		GuildId:   "guild_1",
+		Type:      chat.MessageType_TEXT,
+	})
+
+	data, err := frame.Encode(EncodingProtobuf)
+	require.NoError(t, err)
+	var decoded chat.GatewayFrame
+	require.NoError(t, proto.Unmarshal(data, &decoded))
+	assert.Equal(t, int32(ProtocolVersion), decoded.Version)
+	assert.Equal(t, chat.GatewayOpcode_DISPATCH, decoded.Op)
+	assert.Equal(t, "msg_1", decoded.Event.MessageId)
+
+	data, err = frame.Encode(EncodingJSON)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":1,"event":{"message_id":"msg_1","guild_id":"guild_1"}}`, string(data))
+
+	// Each encoding is serialized once
+	again, err := frame.Encode(EncodingJSON)
+	require.NoError(t, err)
+	assert.Same(t, &data[0], &again[0])
+
+	// JSON frames follow the proto3 JSON mapping, enums are names and 64-bit integers strings
+	data, err = NewDispatch(&chat.WSMessage{MessageId: "msg_1", Type: chat.MessageType_MESSAGE_EDIT, SeqId: 42}).Encode(EncodingJSON)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":1,"event":{"message_id":"msg_1","type":"MESSAGE_EDIT","seq_id":"42"}}`, string(data))
+
+	data, err = newErrorFrame(chat.GatewayErrorCode_ERROR_NODE_DRAINING, "draining").Encode(EncodingJSON)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":1,"op":"ERROR","error":{"code":"ERROR_NODE_DRAINING","message":"draining"}}`, string(data))
+}
+
+// TestDecodeFrame tests that upstream frames must match the negotiated encoding.
+func TestDecodeFrame(t *testing.T) {
+	data, err := proto.Marshal(&chat.GatewayFrame{
+		Version: ProtocolVersion,
+		Op:      chat.GatewayOpcode_IDENTIFY,
+		Identify: &chat.Identify{
+			Token:   "token",
+			GuildId: "guild_1",
+		},
+	})
+	require.NoError(t, err)
+
+	frame, err := decodeFrame(data, EncodingProtobuf, websocket.BinaryMessage)
+	require.NoError(t, err)
+	assert.Equal(t, chat.GatewayOpcode_IDENTIFY, frame.Op)
+	assert.Equal(t, "token", frame.Identify.Token)
+
+	jsonData := []byte(`{"version":1,"op":0,"event":{"guild_id":"guild_1","content":"hi"}}`)
+	frame, err = decodeFrame(jsonData, EncodingJSON, websocket.TextMessage)
+	require.NoError(t, err)
+	assert.Equal(t, chat.GatewayOpcode_DISPATCH, frame.Op)
+	assert.Equal(t, "hi", frame.Event.Content)
+
+	// Enum names and string encoded integers are accepted as well
+	frame, err = decodeFrame([]byte(`{"version":1,"op":"HEARTBEAT","event":{"type":"ACK","seq_id":"7"}}`), EncodingJSON, websocket.TextMessage)
+	require.NoError(t, err)
+	assert.Equal(t, chat.GatewayOpcode_HEARTBEAT, frame.Op)
+	assert.Equal(t, chat.MessageType_ACK, frame.Event.Type)
+	assert.Equal(t, int64(7), frame.Event.SeqId)
+
+	// Frames of the other encoding are rejected
+	for _, tc := range []struct {
+		data        []byte
+		encoding    Encoding
+		messageType int
+	}{
+		{jsonData, EncodingProtobuf, websocket.TextMessage},
+		{jsonData, EncodingProtobuf, websocket.BinaryMessage},
+		{data, EncodingJSON, websocket.BinaryMessage},
+		{data, EncodingJSON, websocket.TextMessage},
+	} {
+		_, err = decodeFrame(tc.data, tc.encoding, tc.messageType)
+		var gwErr *gatewayError
+		require.True(t, errors.As(err, &gwErr))
+		assert.Equal(t, chat.GatewayErrorCode_ERROR_DECODE, gwErr.code)
+	}
+}
+
+// TestNegotiateEncoding tests encoding selection by query parameter and subprotocol.
+func TestNegotiateEncoding(t *testing.T) {
+	newRequest := func(target string, subprotocols ...string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		if len(subprotocols) > 0 {
+			r.Header.Set("Sec-WebSocket-Protocol", strings.Join(subprotocols, ", "))
+		}
+		return r
+	}
+
+	for _, tc := range []struct {
+		name     string
+		request  *http.Request
+		expected Encoding
+	}{
+		{"default", newRequest("/ws"), EncodingProtobuf},
+		{"query", newRequest("/ws?encoding=json"), EncodingJSON},
+		{"subprotocol", newRequest("/ws", "chat.json"), EncodingJSON},
+		{"query over subprotocol", newRequest("/ws?encoding=protobuf", "chat.json"), EncodingProtobuf},
+		{"unknown subprotocol", newRequest("/ws", "mqtt"), EncodingProtobuf},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			encoding, err := NegotiateEncoding(tc.request)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, encoding)
+		})
+	}
+
+	_, err := NegotiateEncoding(newRequest("/ws?encoding=xml"))
+	assert.Error(t, err)
+}
+
+// TestAcceptsEvent tests that optional events are only delivered to connections with the capability.
+func TestAcceptsEvent(t *testing.T) {
+	conn := NewConnection(context.Background(), "session_1", "user_1", "web", "guild_1", "", nil)
+	conn.setCapabilities([]string{CapabilityTyping})
+
+	assert.True(t, acceptsEvent(conn, chat.MessageType_TEXT))
+	assert.True(t, acceptsEvent(conn, chat.MessageType_TYPING))
+	assert.False(t, acceptsEvent(conn, chat.MessageType_PRESENCE_UPDATE))
+}
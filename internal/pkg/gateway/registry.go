@@ -0,0 +1,95 @@
+package gateway
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+// guildIndexShards is the number of independently locked shards of the guild index.
+const guildIndexShards = 64
+
+// guildIndex maps guild IDs to the connections subscribed to them.
+// It is split into shards so that broadcasts and subscriptions of different guilds
+// do not contend on a single lock, and a broadcast only visits the guild's subscribers.
+type guildIndex struct {
+	shards [guildIndexShards]guildShard
+}
+
+// guildShard holds the subscribers of the guilds hashed to it.
+type guildShard struct {
+	mu sync.RWMutex
+
+	// guilds maps guildID to its subscribed connections, keyed by sessionID
+	guilds map[string]map[string]*Connection
+}
+
+// newGuildIndex creates an empty guild index.
+func newGuildIndex() *guildIndex {
+	gi := &guildIndex{}
+	for i := range gi.shards {
+		gi.shards[i].guilds = make(map[string]map[string]*Connection)
+	}
+	return gi
+}
+
+// shard returns the shard responsible for a guild.
+func (gi *guildIndex) shard(guildID string) *guildShard {
+	h := fnv.New32a()
+	h.Write([]byte(guildID))
+	return &gi.shards[h.Sum32()%guildIndexShards]
+}
+
+// add subscribes a connection to a guild.
+//
+// Returns:
+//   - bool: true if the subscription was added, false if it already existed
+func (gi *guildIndex) add(guildID string, conn *Connection) bool {
+	shard := gi.shard(guildID)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	subscribers, exists := shard.guilds[guildID]
+	if !exists {
+		subscribers = make(map[string]*Connection)
+		shard.guilds[guildID] = subscribers
+	}
+	if _, subscribed := subscribers[conn.SessionID]; subscribed {
+		return false
+	}
+	subscribers[conn.SessionID] = conn
+	return true
+}
+
+// remove unsubscribes a session from a guild.
+//
+// Returns:
+//   - bool: true if the subscription was removed, false if it did not exist
+func (gi *guildIndex) remove(guildID, sessionID string) bool {
+	shard := gi.shard(guildID)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	subscribers := shard.guilds[guildID]
+	if _, subscribed := subscribers[sessionID]; !subscribed {
+		return false
+	}
+	delete(subscribers, sessionID)
+	if len(subscribers) == 0 {
+		delete(shard.guilds, guildID)
+	}
+	return true
+}
+
+// connections returns a snapshot of the connections subscribed to a guild.
+func (gi *guildIndex) connections(guildID string) []*Connection {
+	shard := gi.shard(guildID)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+
+	subscribers := shard.guilds[guildID]
+	connections := make([]*Connection, 0, len(subscribers))
+	for _, conn := range subscribers {
+		connections = append(connections, conn)
+	}
+	return connections
+}
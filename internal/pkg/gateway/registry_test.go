@@ -0,0 +1,65 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGuildIndex_AddRemove tests that the index reports whether subscriptions changed.
+func TestGuildIndex_AddRemove(t *testing.T) {
+	gi := newGuildIndex()
+	conn1 := NewConnection(context.Background(), "session_1", "user_1", "web", "guild_1", "", nil)
+	conn2 := NewConnection(context.Background(), "session_2", "user_1", "ios", "guild_1", "", nil)
+
+	assert.True(t, gi.add("guild_1", conn1))
+	assert.True(t, gi.add("guild_1", conn2))
+	assert.False(t, gi.add("guild_1", conn1), "already subscribed")
+	assert.ElementsMatch(t, []*Connection{conn1, conn2}, gi.connections("guild_1"))
+	assert.Empty(t, gi.connections("guild_2"))
+
+	assert.True(t, gi.remove("guild_1", conn1.SessionID))
+	assert.False(t, gi.remove("guild_1", conn1.SessionID), "already removed")
+	assert.True(t, gi.remove("guild_1", conn2.SessionID))
+	assert.Empty(t, gi.connections("guild_1"))
+
+	// Removing from an unknown guild is a no-op
+	assert.False(t, gi.remove("guild_2", conn1.SessionID))
+}
+
+// TestGuildIndex_Shards tests that guilds spread over shards stay independent.
+func TestGuildIndex_Shards(t *testing.T) {
+	gi := newGuildIndex()
+	conn := NewConnection(context.Background(), "session_1", "user_1", "", "", "", nil)
+
+	for i := range 1000 {
+		gi.add(fmt.Sprintf("guild_%d", i), conn)
+	}
+	for i := range 1000 {
+		assert.Len(t, gi.connections(fmt.Sprintf("guild_%d", i)), 1)
+	}
+}
+
+// TestConnection_Subscriptions tests guild subscriptions and channel filtering of a connection.
+func TestConnection_Subscriptions(t *testing.T) {
+	conn := NewConnection(context.Background(), "session_1", "user_1", "web", "guild_1", "channel_1", nil)
+
+	assert.True(t, conn.Subscribed("guild_1"), "the guild the connection was opened for")
+	assert.False(t, conn.Subscribed("guild_2"))
+
+	assert.True(t, conn.subscribe("guild_2"))
+	assert.False(t, conn.subscribe("guild_2"), "already subscribed")
+	assert.ElementsMatch(t, []string{"guild_1", "guild_2"}, conn.Guilds())
+
+	// The channel filter only applies to the guild the connection was opened for
+	assert.True(t, conn.Wants("guild_1", "channel_1"))
+	assert.True(t, conn.Wants("guild_1", ""))
+	assert.False(t, conn.Wants("guild_1", "channel_2"))
+	assert.True(t, conn.Wants("guild_2", "channel_2"))
+
+	assert.True(t, conn.unsubscribe("guild_2"))
+	assert.False(t, conn.unsubscribe("guild_2"))
+	assert.False(t, conn.Subscribed("guild_2"))
+}
@@ -0,0 +1,111 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+
+	chat "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
+)
+
+var (
+	// ErrInvalidStream is returned when a guild stream is requested with invalid guilds or positions
+	ErrInvalidStream = errors.New("invalid guild stream")
+
+	// ErrStreamClosed is returned when the gateway ends a guild stream, e.g. because the node is
+	// draining or the subscriber fell behind. The subscriber should subscribe again and resume.
+	ErrStreamClosed = errors.New("guild stream closed by the gateway")
+)
+
+// StreamGuilds streams the events of a set of guilds to an internal subscriber, such as a bot,
+// an archiver or an analytics pipeline. Events are fed by the same Redis Pub/Sub pipeline as the
+// WebSocket sessions, including the optional typing and presence events. When resume positions
+// are given, the messages after them are replayed first and a RESUME event reports where the
+// replay stopped, as for a resuming session.
+// It blocks until the context is done, send fails or the gateway closes the stream.
+//
+// Parameters:
+//   - ctx: Context of the subscriber, the stream ends when it is done
+//   - guildIDs: The guilds to stream, at most maxGuildSubscriptions
+//   - positions: The last seq_id the subscriber received per timeline (optional)
+//   - send: Delivers an event to the subscriber, it is called from a single goroutine
+//
+// Returns:
+//   - error: nil when the context is done, ErrInvalidStream, ErrStreamClosed or the error of send
+func (h *MessageHandler) StreamGuilds(ctx context.Context, guildIDs []string, positions []*chat.ResumePosition, send func(*chat.WSMessage) error) error {
+	if len(guildIDs) == 0 {
+		return fmt.Errorf("%w: at least one guild is required", ErrInvalidStream)
+	}
+	if len(guildIDs) > maxGuildSubscriptions {
+		return fmt.Errorf("%w: cannot stream more than %d guilds", ErrInvalidStream, maxGuildSubscriptions)
+	}
+	for _, guildID := range guildIDs {
+		if guildID == "" {
+			return fmt.Errorf("%w: guild ID is required", ErrInvalidStream)
+		}
+	}
+	if len(positions) > maxResumePositions {
+		return fmt.Errorf("%w: cannot resume more than %d timelines", ErrInvalidStream, maxResumePositions)
+	}
+	for _, position := range positions {
+		if !slices.Contains(guildIDs, position.GuildId) {
+			return fmt.Errorf("%w: cannot resume guild %s which is not streamed", ErrInvalidStream, position.GuildId)
+		}
+	}
+	if h.Draining() {
+		return fmt.Errorf("%w: node is draining", ErrStreamClosed)
+	}
+
+	resume := len(positions) > 0
+	conn := h.connManager.openStream(guildIDs, resume)
+	defer h.connManager.closeStream(conn)
+
+	// Checked once the stream is registered, a drain either sees the stream or the stream sees the drain
+	if h.Draining() {
+		return fmt.Errorf("%w: node is draining", ErrStreamClosed)
+	}
+
+	if resume {
+		// The replay is queued while the loop below delivers it
+		go func() {
+			if err := h.handleResume(conn, positions); err != nil {
+				log.Printf("Failed to resume guild stream %s: %v", conn.SessionID, err)
+			}
+		}()
+	}
+
+	log.Printf("Guild stream %s opened for %d guilds", conn.SessionID, len(guildIDs))
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-conn.Context().Done():
+			// The frames still queued are delivered, the last one is the ERROR frame when there is one
+			for _, frame := range conn.pending() {
+				if err := forwardStreamFrame(frame, send); err != nil {
+					return err
+				}
+			}
+			// Closed without ERROR frame, e.g. the subscriber fell behind or the gateway shut down
+			return ErrStreamClosed
+		case frame := <-conn.Send:
+			if err := forwardStreamFrame(frame, send); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// forwardStreamFrame delivers the event of a DISPATCH frame to a stream subscriber.
+// An ERROR frame, sent when the gateway closes the stream, ends it with ErrStreamClosed.
+func forwardStreamFrame(frame *Frame, send func(*chat.WSMessage) error) error {
+	switch frame.frame.Op {
+	case chat.GatewayOpcode_DISPATCH:
+		return send(frame.frame.Event)
+	case chat.GatewayOpcode_ERROR:
+		return fmt.Errorf("%w: %s", ErrStreamClosed, frame.frame.Error.Message)
+	}
+	return nil
+}
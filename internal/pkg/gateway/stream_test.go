@@ -0,0 +1,89 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	redislib "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/Gopher0727/ChatRoom/config"
+	chat "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
+)
+
+// newStreamTestHandler creates a handler whose manager only indexes guilds, without Redis.
+func newStreamTestHandler(ctx context.Context) *MessageHandler {
+	cm := &ConnectionManager{
+		connections:  make(map[string]*Connection),
+		userSessions: make(map[string]map[string]*Connection),
+		streams:      make(map[string]*Connection),
+		guilds:       newGuildIndex(),
+		subscriber:   newGuildSubscriber(nil),
+		config:       &config.WebsocketConfig{},
+		ctx:          ctx,
+	}
+	return &MessageHandler{connManager: cm, ctx: ctx, drained: make(chan struct{})}
+}
+
+// TestStreamGuilds tests that guild streams receive the events of their guilds until they are closed.
+func TestStreamGuilds(t *testing.T) {
+	h := newStreamTestHandler(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events := make(chan *chat.WSMessage, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- h.StreamGuilds(ctx, []string{"guild_1", "guild_2"}, nil, func(event *chat.WSMessage) error {
+			events <- event
+			return nil
+		})
+	}()
+	require.Eventually(t, func() bool { return len(h.connManager.getStreams()) == 1 }, time.Second, 10*time.Millisecond)
+
+	publish := func(event *chat.WSMessage) {
+		data, err := proto.Marshal(event)
+		require.NoError(t, err)
+		require.NoError(t, h.handleDownstreamMessage(&redislib.Message{Payload: string(data)}))
+	}
+
+	// Optional events are streamed too, other guilds are not
+	publish(&chat.WSMessage{MessageId: "message_1", GuildId: "guild_1", Type: chat.MessageType_TEXT})
+	publish(&chat.WSMessage{MessageId: "message_2", GuildId: "guild_3", Type: chat.MessageType_TEXT})
+	publish(&chat.WSMessage{UserId: "user_1", GuildId: "guild_2", Type: chat.MessageType_TYPING})
+
+	assert.Equal(t, "message_1", (<-events).MessageId)
+	assert.Equal(t, chat.MessageType_TYPING, (<-events).Type)
+
+	cancel()
+	assert.NoError(t, <-done)
+	assert.Empty(t, h.connManager.getStreams())
+	assert.Empty(t, h.connManager.GetConnectionsByGuild("guild_1"))
+	assert.Empty(t, events)
+}
+
+// TestStreamGuilds_Closed tests that a stream closed by the gateway reports ErrStreamClosed.
+func TestStreamGuilds_Closed(t *testing.T) {
+	h := newStreamTestHandler(context.Background())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.StreamGuilds(context.Background(), []string{"guild_1"}, nil, func(*chat.WSMessage) error { return nil })
+	}()
+	require.Eventually(t, func() bool { return len(h.connManager.getStreams()) == 1 }, time.Second, 10*time.Millisecond)
+
+	h.connManager.getStreams()[0].closeWithCode(chat.GatewayErrorCode_ERROR_NODE_DRAINING, "node is draining")
+	assert.ErrorIs(t, <-done, ErrStreamClosed)
+}
+
+// TestStreamGuilds_Invalid tests the validation of stream requests.
+func TestStreamGuilds_Invalid(t *testing.T) {
+	h := newStreamTestHandler(context.Background())
+	send := func(*chat.WSMessage) error { return nil }
+
+	assert.ErrorIs(t, h.StreamGuilds(context.Background(), nil, nil, send), ErrInvalidStream)
+	assert.ErrorIs(t, h.StreamGuilds(context.Background(), []string{""}, nil, send), ErrInvalidStream)
+	assert.ErrorIs(t, h.StreamGuilds(context.Background(), []string{"guild_1"}, []*chat.ResumePosition{{GuildId: "guild_2", SeqId: 1}}, send), ErrInvalidStream)
+}
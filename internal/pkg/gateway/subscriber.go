@@ -0,0 +1,294 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	redislib "github.com/redis/go-redis/v9"
+
+	redis "github.com/Gopher0727/ChatRoom/internal/pkg/redis"
+)
+
+const (
+	// guildSubscriberHealthCheck is how long the subscriber waits for a message before pinging Redis
+	guildSubscriberHealthCheck = 30 * time.Second
+
+	// guildSubscriberRetryDelay is the delay between attempts to reconnect to Redis
+	guildSubscriberRetryDelay = time.Second
+
+	// guildSubscribeTimeout bounds the wait for Redis to confirm guild subscriptions
+	guildSubscribeTimeout = 2 * time.Second
+)
+
+// closedChan is a closed channel, returned when there is nothing to wait for.
+var closedChan = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+// subscribeWait tracks the SUBSCRIBE commands of a guild channel that Redis has not confirmed yet.
+type subscribeWait struct {
+	// inflight is the number of confirmations still expected
+	inflight int
+
+	// done is closed once the subscription is confirmed, or the guild released
+	done chan struct{}
+}
+
+// guildSubscriber subscribes this node to the Pub/Sub channels of the guilds
+// its local connections are subscribed to, instead of receiving every guild.
+// Subscriptions are reference counted: a guild channel is subscribed when its first
+// local subscriber arrives and unsubscribed when the last one leaves.
+// When the Redis connection is lost, all referenced channels are subscribed again on a new one.
+// SUBSCRIBE does not wait for Redis, acquire returns a channel closed once the subscription is
+// confirmed, from then on every message published to the guild is received.
+type guildSubscriber struct {
+	redisClient redis.RedisClient
+
+	// mu protects refs, waits and pubsub, and orders the SUBSCRIBE and UNSUBSCRIBE commands
+	mu sync.Mutex
+
+	// refs maps guildID to its number of local subscribers
+	refs map[string]int
+
+	// waits maps guildID to its unconfirmed subscription
+	waits map[string]*subscribeWait
+
+	// started is set once the first subscription is opened, nothing is confirmed before
+	started bool
+
+	// pubsub is the current subscription, nil until started and while reconnecting
+	pubsub *redislib.PubSub
+
+	// closed is set once the subscriber is stopped
+	closed bool
+}
+
+// newGuildSubscriber creates a guild subscriber, it receives nothing until started.
+func newGuildSubscriber(redisClient redis.RedisClient) *guildSubscriber {
+	return &guildSubscriber{
+		redisClient: redisClient,
+		refs:        make(map[string]int),
+		waits:       make(map[string]*subscribeWait),
+	}
+}
+
+// guildChannel returns the Pub/Sub channel of a guild.
+func guildChannel(guildID string) string {
+	return fmt.Sprintf("guild:%s", guildID)
+}
+
+// acquire adds a local subscriber to a guild, subscribing its channel if it is the first.
+// The returned channel is closed once Redis confirmed the subscription of the guild.
+func (s *guildSubscriber) acquire(ctx context.Context, guildID string) <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refs[guildID]++
+	if s.refs[guildID] > 1 {
+		if wait, ok := s.waits[guildID]; ok {
+			return wait.done
+		}
+		return closedChan
+	}
+	// Before the subscriber is started nothing is received yet, start subscribes every referenced guild
+	if !s.started {
+		return closedChan
+	}
+
+	wait := &subscribeWait{done: make(chan struct{})}
+	s.waits[guildID] = wait
+	if s.pubsub == nil {
+		// Reconnecting, connect subscribes the guild with the others
+		return wait.done
+	}
+	// The channel is kept by the subscription even when sending fails, and sent again once reconnected
+	wait.inflight++
+	if err := s.pubsub.Subscribe(ctx, guildChannel(guildID)); err != nil {
+		log.Printf("Failed to subscribe to guild %s: %v", guildID, err)
+	}
+	return wait.done
+}
+
+// confirm records a subscription confirmed by Redis.
+func (s *guildSubscriber) confirm(channel string) {
+	guildID, ok := strings.CutPrefix(channel, guildChannel(""))
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wait, ok := s.waits[guildID]
+	if !ok {
+		return
+	}
+	// Confirmations of earlier SUBSCRIBE commands may still arrive, wait for the last one
+	wait.inflight--
+	if wait.inflight <= 0 {
+		close(wait.done)
+		delete(s.waits, guildID)
+	}
+}
+
+// release removes a local subscriber from a guild, unsubscribing its channel if it was the last.
+func (s *guildSubscriber) release(ctx context.Context, guildID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.refs[guildID] == 0 {
+		return
+	}
+	s.refs[guildID]--
+	if s.refs[guildID] > 0 {
+		return
+	}
+	delete(s.refs, guildID)
+	if wait, ok := s.waits[guildID]; ok {
+		close(wait.done)
+		delete(s.waits, guildID)
+	}
+	if s.pubsub == nil {
+		return
+	}
+	if err := s.pubsub.Unsubscribe(ctx, guildChannel(guildID)); err != nil {
+		// Messages of the guild are still received until reconnected, they find no local subscriber
+		log.Printf("Failed to unsubscribe from guild %s: %v", guildID, err)
+	}
+}
+
+// start subscribes the referenced guild channels and passes received messages to handle
+// until the context is cancelled.
+func (s *guildSubscriber) start(ctx context.Context, handle func(*redislib.Message) error) error {
+	if err := s.connect(ctx); err != nil {
+		return err
+	}
+
+	context.AfterFunc(ctx, s.close)
+	go s.receive(ctx, handle)
+
+	return nil
+}
+
+// connect opens a new subscription to every referenced guild channel.
+func (s *guildSubscriber) connect(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("guild subscriber is closed")
+	}
+
+	channels := make([]string, 0, len(s.refs))
+	for guildID := range s.refs {
+		channels = append(channels, guildChannel(guildID))
+	}
+	pubsub, err := s.redisClient.Subscribe(ctx, channels...)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to guild channels: %w", err)
+	}
+	s.pubsub = pubsub
+	s.started = true
+	// Every referenced guild is subscribed again, the confirmations of the lost connection never arrive
+	for _, wait := range s.waits {
+		wait.inflight = 1
+	}
+
+	log.Printf("Subscribed to %d guild channels", len(channels))
+	return nil
+}
+
+// disconnect drops a broken subscription so that the next receive reconnects.
+func (s *guildSubscriber) disconnect(pubsub *redislib.PubSub) {
+	s.mu.Lock()
+	if s.pubsub == pubsub {
+		s.pubsub = nil
+	}
+	s.mu.Unlock()
+
+	pubsub.Close()
+}
+
+// close stops the subscriber and releases its Redis connection.
+func (s *guildSubscriber) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for guildID, wait := range s.waits {
+		close(wait.done)
+		delete(s.waits, guildID)
+	}
+	if s.pubsub != nil {
+		s.pubsub.Close()
+		s.pubsub = nil
+	}
+}
+
+// receive forwards messages of the subscribed guild channels and reconnects when the connection is lost.
+func (s *guildSubscriber) receive(ctx context.Context, handle func(*redislib.Message) error) {
+	defer s.close()
+
+	pingPending := false
+	for {
+		s.mu.Lock()
+		pubsub, closed := s.pubsub, s.closed
+		s.mu.Unlock()
+
+		if closed {
+			return
+		}
+		if pubsub == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(guildSubscriberRetryDelay):
+			}
+			if err := s.connect(ctx); err != nil {
+				log.Printf("Failed to reconnect guild subscriber: %v", err)
+			}
+			continue
+		}
+
+		msg, err := pubsub.ReceiveTimeout(ctx, guildSubscriberHealthCheck)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			// Nothing received for a while, check that the connection is still alive
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() && !pingPending {
+				pingPending = true
+				if err = pubsub.Ping(ctx); err == nil {
+					continue
+				}
+			}
+
+			log.Printf("Guild subscriber connection lost, resubscribing: %v", err)
+			s.disconnect(pubsub)
+			pingPending = false
+			continue
+		}
+		pingPending = false
+
+		switch message := msg.(type) {
+		case *redislib.Message:
+			if err := handle(message); err != nil {
+				log.Printf("Error handling downstream message: %v", err)
+			}
+		case *redislib.Subscription:
+			if message.Kind == "subscribe" {
+				s.confirm(message.Channel)
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGuildSubscriber_RefCount tests that guild channels are counted per local subscriber.
+func TestGuildSubscriber_RefCount(t *testing.T) {
+	ctx := context.Background()
+	s := newGuildSubscriber(nil)
+
+	s.acquire(ctx, "guild_1")
+	s.acquire(ctx, "guild_1")
+	s.acquire(ctx, "guild_2")
+	assert.Equal(t, map[string]int{"guild_1": 2, "guild_2": 1}, s.refs)
+
+	s.release(ctx, "guild_1")
+	assert.Equal(t, 1, s.refs["guild_1"])
+
+	s.release(ctx, "guild_1")
+	s.release(ctx, "guild_2")
+	assert.Empty(t, s.refs)
+
+	// Releasing a guild without subscribers is a no-op
+	s.release(ctx, "guild_3")
+	assert.Empty(t, s.refs)
+}
+
+// TestGuildSubscriber_Confirm tests that acquiring a guild waits for Redis to confirm its subscription.
+func TestGuildSubscriber_Confirm(t *testing.T) {
+	ctx := context.Background()
+	s := newGuildSubscriber(nil)
+
+	// Nothing to wait for before the subscriber is started
+	assert.True(t, isClosed(s.acquire(ctx, "guild_1")))
+
+	s.started = true
+	done := s.acquire(ctx, "guild_2")
+	assert.False(t, isClosed(done))
+	assert.Equal(t, done, s.acquire(ctx, "guild_2"))
+
+	s.confirm(guildChannel("guild_3"))
+	assert.False(t, isClosed(done))
+	s.confirm(guildChannel("guild_2"))
+	assert.True(t, isClosed(done))
+	assert.True(t, isClosed(s.acquire(ctx, "guild_2")))
+
+	// Releasing the last subscriber ends the wait
+	done = s.acquire(ctx, "guild_4")
+	s.release(ctx, "guild_4")
+	assert.True(t, isClosed(done))
+	assert.Empty(t, s.waits)
+}
+
+// isClosed reports whether a channel is closed without blocking.
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -61,6 +61,19 @@ func (c *GatewayClient) PushMessage(ctx context.Context, userID string, message
 	return nil
 }
 
+// PushToUsers 批量推送消息到节点上的多个用户，返回未送达的用户
+func (c *GatewayClient) PushToUsers(ctx context.Context, userIDs []string, message *pb.WSMessage) ([]string, error) {
+	resp, err := c.client.PushToUsers(ctx, &pb.PushToUsersRequest{
+		UserIds: userIDs,
+		Message: message,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("grpc call failed: %w", err)
+	}
+
+	return resp.UndeliveredUserIds, nil
+}
+
 // BroadcastToGuild 广播消息到 Guild
 func (c *GatewayClient) BroadcastToGuild(ctx context.Context, guildID string, message *pb.WSMessage, excludeUserIDs []string) (int32, error) {
 	resp, err := c.client.BroadcastToGuild(ctx, &pb.BroadcastRequest{
@@ -97,6 +110,31 @@ func (c *GatewayClient) GetNodeInfo(ctx context.Context) (*pb.NodeInfoResponse,
 	return resp, nil
 }
 
+// SubscribeGuilds 订阅 Guild 的实时事件，每个事件交给 handle 处理
+// 阻塞直到 ctx 取消、handle 返回错误或节点关闭订阅；positions 为各时间线最后收到的位置，可为空
+func (c *GatewayClient) SubscribeGuilds(ctx context.Context, guildIDs []string, positions []*pb.ResumePosition, handle func(*pb.WSMessage) error) error {
+	stream, err := c.client.SubscribeGuilds(ctx, &pb.SubscribeGuildsRequest{
+		GuildIds:        guildIDs,
+		ResumePositions: positions,
+	})
+	if err != nil {
+		return fmt.Errorf("grpc call failed: %w", err)
+	}
+
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("guild stream ended: %w", err)
+		}
+		if err := handle(event); err != nil {
+			return err
+		}
+	}
+}
+
 // HealthCheck 健康检查
 func (c *GatewayClient) HealthCheck(ctx context.Context) (bool, error) {
 	resp, err := c.client.HealthCheck(ctx, &pb.HealthCheckRequest{})
@@ -0,0 +1,199 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/Gopher0727/ChatRoom/internal/pkg/gateway"
+	pb "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
+	"github.com/Gopher0727/ChatRoom/internal/pkg/redis"
+)
+
+// pushTimeout 单次推送到 Gateway 节点的超时时间
+const pushTimeout = 3 * time.Second
+
+// GatewayPool 按节点 ID 复用 Gateway gRPC 客户端
+// 节点地址从 Redis 中的节点注册表解析，连接在首次使用时建立
+type GatewayPool struct {
+	redisClient redis.RedisClient
+	mu          sync.Mutex
+	clients     map[string]*GatewayClient
+}
+
+// NewGatewayPool 创建 Gateway 客户端池
+func NewGatewayPool(redisClient redis.RedisClient) *GatewayPool {
+	return &GatewayPool{
+		redisClient: redisClient,
+		clients:     make(map[string]*GatewayClient),
+	}
+}
+
+// Get 获取节点的客户端，不存在时按注册表中的地址建立连接
+func (p *GatewayPool) Get(ctx context.Context, nodeID string) (*GatewayClient, error) {
+	p.mu.Lock()
+	client, ok := p.clients[nodeID]
+	p.mu.Unlock()
+	if ok {
+		return client, nil
+	}
+
+	node, err := p.redisClient.GetGatewayNode(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	if node == nil {
+		return nil, fmt.Errorf("gateway node %s is not registered", nodeID)
+	}
+
+	// 建立连接可能阻塞数秒，不持有锁
+	client, err = NewGatewayClient(node.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to gateway node %s at %s: %w", nodeID, node.Address, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// 并发建立的连接只保留一个
+	if existing, ok := p.clients[nodeID]; ok {
+		client.Close()
+		return existing, nil
+	}
+	p.clients[nodeID] = client
+	return client, nil
+}
+
+// Remove 关闭并移除节点的客户端，节点重启后地址可能变化，下次使用时重新解析
+func (p *GatewayPool) Remove(nodeID string) {
+	p.mu.Lock()
+	client, ok := p.clients[nodeID]
+	delete(p.clients, nodeID)
+	p.mu.Unlock()
+
+	if ok {
+		client.Close()
+	}
+}
+
+// Close 关闭所有客户端
+func (p *GatewayPool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var errs []error
+	for nodeID, client := range p.clients {
+		if err := client.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close client of gateway node %s: %w", nodeID, err))
+		}
+		delete(p.clients, nodeID)
+	}
+	return errors.Join(errs...)
+}
+
+// GatewayRouter 将私信和用户级事件只推送到持有用户会话的 Gateway 节点，而不是广播到所有节点
+// 用户所在节点取自 user:<id>:nodes 及各节点带 TTL 的会话数，由各节点在连接和心跳时维护
+// 用户按节点分组，每个节点一次批量调用，各节点并发推送；本节点的用户直接写入连接管理器
+type GatewayRouter struct {
+	redisClient redis.RedisClient
+	pool        *GatewayPool
+	local       *gateway.ConnectionManager
+	nodeID      string
+}
+
+// NewGatewayRouter 创建事件路由器，local 为本节点的连接管理器，nodeID 为本节点 ID
+func NewGatewayRouter(redisClient redis.RedisClient, pool *GatewayPool, local *gateway.ConnectionManager, nodeID string) *GatewayRouter {
+	return &GatewayRouter{
+		redisClient: redisClient,
+		pool:        pool,
+		local:       local,
+		nodeID:      nodeID,
+	}
+}
+
+// PushToUsers 推送事件到各用户所在的节点，离线用户直接跳过
+// 推送失败不影响其他用户和节点，所有错误合并返回
+func (r *GatewayRouter) PushToUsers(ctx context.Context, userIDs []string, event *pb.WSMessage) error {
+	var errs []error
+
+	// 按节点分组用户
+	nodes := make(map[string][]string)
+	for _, userID := range userIDs {
+		sessions, err := r.redisClient.GetUserSessions(ctx, userID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for nodeID, count := range sessions {
+			if count > 0 {
+				nodes[nodeID] = append(nodes[nodeID], userID)
+			}
+		}
+	}
+	if len(nodes) == 0 {
+		return errors.Join(errs...)
+	}
+
+	// 接收方只用于路由，不下发给客户端；复制一份，事件会被多个节点并发读取
+	if len(event.RecipientIds) > 0 {
+		event = proto.Clone(event).(*pb.WSMessage)
+		event.RecipientIds = nil
+	}
+
+	// 本节点的用户直接写入发送队列，不经过 gRPC
+	if localUsers, ok := nodes[r.nodeID]; ok {
+		delete(nodes, r.nodeID)
+		for _, userID := range localUsers {
+			if r.local.PushToUser(userID, event) == 0 {
+				errs = append(errs, fmt.Errorf("failed to push to user %s on this gateway node", userID))
+			}
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for nodeID, nodeUsers := range nodes {
+		wg.Go(func() {
+			if err := r.push(ctx, nodeID, nodeUsers, event); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// push 批量推送事件到节点上的用户会话
+func (r *GatewayRouter) push(ctx context.Context, nodeID string, userIDs []string, event *pb.WSMessage) error {
+	client, err := r.pool.Get(ctx, nodeID)
+	if err != nil {
+		return err
+	}
+
+	pushCtx, cancel := context.WithTimeout(ctx, pushTimeout)
+	defer cancel()
+
+	undelivered, err := client.PushToUsers(pushCtx, userIDs, event)
+	if err != nil {
+		// 节点不可达时丢弃连接，下次使用时按注册表重新连接
+		if status.Code(err) == codes.Unavailable {
+			log.Printf("Gateway node %s is unavailable, dropping its client", nodeID)
+			r.pool.Remove(nodeID)
+		}
+		return fmt.Errorf("failed to push to %d users on gateway node %s: %w", len(userIDs), nodeID, err)
+	}
+	if len(undelivered) > 0 {
+		return fmt.Errorf("failed to push to users %v on gateway node %s", undelivered, nodeID)
+	}
+	return nil
+}
@@ -2,34 +2,50 @@ package grpc
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"time"
 
-	"github.com/gorilla/websocket"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"google.golang.org/protobuf/proto"
 
 	"github.com/Gopher0727/ChatRoom/internal/pkg/gateway"
 	pb "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
+	"github.com/Gopher0727/ChatRoom/internal/pkg/redis"
 )
 
+// Drainer 排空 Gateway 节点：停止接受新连接并通知客户端重连到其他节点
+type Drainer interface {
+	StartDrain(window time.Duration) <-chan struct{}
+	Draining() bool
+}
+
+// GuildStreamer 将 Guild 的实时事件推送给内部订阅者
+type GuildStreamer interface {
+	StreamGuilds(ctx context.Context, guildIDs []string, positions []*pb.ResumePosition, send func(*pb.WSMessage) error) error
+}
+
 // GatewayServer 实现 GatewayService gRPC 服务
 type GatewayServer struct {
 	pb.UnimplementedGatewayServiceServer
-	manager   *gateway.ConnectionManager
-	nodeID    string
-	address   string
-	startTime time.Time
+	manager     *gateway.ConnectionManager
+	drainer     Drainer
+	streamer    GuildStreamer
+	redisClient redis.RedisClient
+	nodeID      string
+	address     string
+	startTime   time.Time
 }
 
 // NewGatewayServer 创建新的 Gateway gRPC 服务器
-func NewGatewayServer(manager *gateway.ConnectionManager, nodeID, address string) *GatewayServer {
+func NewGatewayServer(manager *gateway.ConnectionManager, drainer Drainer, streamer GuildStreamer, redisClient redis.RedisClient, nodeID, address string) *GatewayServer {
 	return &GatewayServer{
-		manager:   manager,
-		nodeID:    nodeID,
-		address:   address,
-		startTime: time.Now(),
+		manager:     manager,
+		drainer:     drainer,
+		streamer:    streamer,
+		redisClient: redisClient,
+		nodeID:      nodeID,
+		address:     address,
+		startTime:   time.Now(),
 	}
 }
 
@@ -42,30 +58,19 @@ func (s *GatewayServer) PushMessage(ctx context.Context, req *pb.PushMessageRequ
 		return nil, status.Error(codes.InvalidArgument, "message is required")
 	}
 
-	// 获取用户连接
-	conn, exists := s.manager.GetConnection(req.UserId)
-	if !exists {
+	// 用户在本节点上没有会话时，路由信息已过期
+	if len(s.manager.GetUserConnections(req.UserId)) == 0 {
 		return &pb.PushMessageResponse{
 			Success: false,
 			Error:   "user not connected to this gateway",
 		}, nil
 	}
 
-	// 序列化消息
-	data, err := proto.Marshal(req.Message)
-	if err != nil {
-		return &pb.PushMessageResponse{
-			Success: false,
-			Error:   fmt.Sprintf("failed to marshal message: %v", err),
-		}, nil
-	}
-
-	// 推送消息
-	err = conn.WriteMessage(websocket.BinaryMessage, data)
-	if err != nil {
+	// 写入每个会话的发送队列（不阻塞），任一会话成功即视为送达
+	if s.manager.PushToUser(req.UserId, req.Message) == 0 {
 		return &pb.PushMessageResponse{
 			Success: false,
-			Error:   fmt.Sprintf("failed to push message: %v", err),
+			Error:   "send queues of all sessions are full",
 		}, nil
 	}
 
@@ -74,6 +79,27 @@ func (s *GatewayServer) PushMessage(ctx context.Context, req *pb.PushMessageRequ
 	}, nil
 }
 
+// PushToUsers 批量推送消息到本节点上的多个用户
+func (s *GatewayServer) PushToUsers(ctx context.Context, req *pb.PushToUsersRequest) (*pb.PushToUsersResponse, error) {
+	if len(req.UserIds) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_ids is required")
+	}
+	if req.Message == nil {
+		return nil, status.Error(codes.InvalidArgument, "message is required")
+	}
+
+	// 写入每个会话的发送队列（不阻塞），用户的任一会话成功即视为送达
+	resp := &pb.PushToUsersResponse{}
+	for _, userID := range req.UserIds {
+		if s.manager.PushToUser(userID, req.Message) > 0 {
+			resp.DeliveredCount++
+		} else {
+			resp.UndeliveredUserIds = append(resp.UndeliveredUserIds, userID)
+		}
+	}
+	return resp, nil
+}
+
 // BroadcastToGuild 推送消息到 Guild 的所有在线成员
 func (s *GatewayServer) BroadcastToGuild(ctx context.Context, req *pb.BroadcastRequest) (*pb.BroadcastResponse, error) {
 	if req.GuildId == "" {
@@ -91,11 +117,8 @@ func (s *GatewayServer) BroadcastToGuild(ctx context.Context, req *pb.BroadcastR
 	deliveredCount := int32(0)
 	var failedUserIDs []string
 
-	// 序列化消息
-	data, err := proto.Marshal(req.Message)
-	if err != nil {
-		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to marshal message: %v", err))
-	}
+	// 封装为 DISPATCH 帧，按各会话协商的编码序列化
+	frame := gateway.NewDispatch(req.Message)
 
 	// 获取该 Guild 的所有连接
 	connections := s.manager.GetConnectionsByGuild(req.GuildId)
@@ -105,12 +128,11 @@ func (s *GatewayServer) BroadcastToGuild(ctx context.Context, req *pb.BroadcastR
 			continue
 		}
 
-		// 推送消息
-		err := conn.WriteMessage(websocket.BinaryMessage, data)
-		if err != nil {
-			failedUserIDs = append(failedUserIDs, conn.UserID)
-		} else {
+		// 写入发送队列，不阻塞；队列已满时按慢消费者策略处理
+		if conn.Enqueue(frame, "") {
 			deliveredCount++
+		} else {
+			failedUserIDs = append(failedUserIDs, conn.UserID)
 		}
 	}
 
@@ -126,11 +148,30 @@ func (s *GatewayServer) CheckUserOnline(ctx context.Context, req *pb.UserStatusR
 		return nil, status.Error(codes.InvalidArgument, "user_id is required")
 	}
 
-	_, exists := s.manager.GetConnection(req.UserId)
+	// 用户可能连接在任意节点，以 Redis 中的会话数为准
+	sessions, err := s.redisClient.GetUserSessions(ctx, req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user sessions: %v", err)
+	}
+
+	for nodeID, count := range sessions {
+		if count <= 0 {
+			continue
+		}
+		if nodeID == s.nodeID {
+			return &pb.UserStatusResponse{Online: true, GatewayNode: s.address}, nil
+		}
+		node, err := s.redisClient.GetGatewayNode(ctx, nodeID)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get gateway node: %v", err)
+		}
+		if node != nil {
+			return &pb.UserStatusResponse{Online: true, GatewayNode: node.Address}, nil
+		}
+	}
 
 	return &pb.UserStatusResponse{
-		Online:      exists,
-		GatewayNode: s.address,
+		Online: false,
 	}, nil
 }
 
@@ -138,17 +179,74 @@ func (s *GatewayServer) CheckUserOnline(ctx context.Context, req *pb.UserStatusR
 func (s *GatewayServer) GetNodeInfo(ctx context.Context, req *pb.NodeInfoRequest) (*pb.NodeInfoResponse, error) {
 	allConns := s.manager.GetAllConnections()
 	uptime := time.Since(s.startTime)
+	sendStats := s.manager.SendStats()
 
 	return &pb.NodeInfoResponse{
-		NodeId:          s.nodeID,
-		Address:         s.address,
-		ConnectionCount: int32(len(allConns)),
-		UptimeSeconds:   int64(uptime.Seconds()),
+		NodeId:                  s.nodeID,
+		Address:                 s.address,
+		ConnectionCount:         int32(len(allConns)),
+		UptimeSeconds:           int64(uptime.Seconds()),
+		DroppedFrames:           sendStats.DroppedFrames,
+		SlowConsumerDisconnects: sendStats.SlowConsumerDisconnects,
 	}, nil
 }
 
+// Drain 排空节点，部署前调用，使节点下线时不中断客户端
+// 排空开始后不可撤销，重复调用返回同一次排空的进度
+func (s *GatewayServer) Drain(ctx context.Context, req *pb.DrainRequest) (*pb.DrainResponse, error) {
+	if req.WindowSeconds < 0 {
+		return nil, status.Error(codes.InvalidArgument, "window_seconds must not be negative")
+	}
+
+	drained := s.drainer.StartDrain(time.Duration(req.WindowSeconds) * time.Second)
+	if req.Wait {
+		select {
+		case <-drained:
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+
+	done := false
+	select {
+	case <-drained:
+		done = true
+	default:
+	}
+
+	return &pb.DrainResponse{
+		Drained:         done,
+		ConnectionCount: int32(s.manager.ConnectionCount()),
+	}, nil
+}
+
+// SubscribeGuilds 推送 Guild 的实时事件，直到客户端取消或节点关闭订阅
+// 节点关闭订阅时返回 Unavailable，客户端应带上最后收到的位置重新订阅
+func (s *GatewayServer) SubscribeGuilds(req *pb.SubscribeGuildsRequest, stream pb.GatewayService_SubscribeGuildsServer) error {
+	err := s.streamer.StreamGuilds(stream.Context(), req.GuildIds, req.ResumePositions, stream.Send)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gateway.ErrInvalidStream):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, gateway.ErrStreamClosed):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		// 发送失败，通常是客户端已断开
+		return err
+	}
+}
+
 // HealthCheck 健康检查
 func (s *GatewayServer) HealthCheck(ctx context.Context, req *pb.HealthCheckRequest) (*pb.HealthCheckResponse, error) {
+	// 排空中的节点不再接受新连接，负载均衡应将其摘除
+	if s.drainer.Draining() {
+		return &pb.HealthCheckResponse{
+			Healthy: false,
+			Status:  "draining",
+		}, nil
+	}
+
 	return &pb.HealthCheckResponse{
 		Healthy: true,
 		Status:  "ok",
@@ -13,13 +13,21 @@ import (
 // MessageServer 实现 MessageService gRPC 服务
 type MessageServer struct {
 	pb.UnimplementedMessageServiceServer
-	messageService service.IMessageService
+	messageService  service.IMessageService
+	reactionService service.IReactionService
+	pinService      service.IPinService
 }
 
 // NewMessageServer 创建新的 Message gRPC 服务器
-func NewMessageServer(messageService service.IMessageService) *MessageServer {
+func NewMessageServer(
+	messageService service.IMessageService,
+	reactionService service.IReactionService,
+	pinService service.IPinService,
+) *MessageServer {
 	return &MessageServer{
-		messageService: messageService,
+		messageService:  messageService,
+		reactionService: reactionService,
+		pinService:      pinService,
 	}
 }
 
@@ -28,67 +36,113 @@ func (s *MessageServer) SendMessage(ctx context.Context, req *pb.SendMessageRequ
 	if req.UserId == "" {
 		return nil, status.Error(codes.InvalidArgument, "user_id is required")
 	}
-	if req.GuildId == "" {
-		return nil, status.Error(codes.InvalidArgument, "guild_id is required")
+	if req.GuildId == "" && req.ConversationId == "" {
+		return nil, status.Error(codes.InvalidArgument, "guild_id or conversation_id is required")
 	}
-	if req.Content == "" {
-		return nil, status.Error(codes.InvalidArgument, "content is required")
+	if req.Content == "" && len(req.AttachmentIds) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "content or attachment_ids is required")
 	}
 
 	// 调用业务层发送消息
-	message, err := s.messageService.SendMessage(ctx, req.UserId, req.GuildId, req.Content)
+	message, err := s.messageService.SendMessage(ctx, &service.SendMessageRequest{
+		UserID:    req.UserId,
+		GuildID:   req.GuildId,
+		ChannelID: req.ChannelId,
+		Content:   req.Content,
+		ReplyToID: req.ReplyToId,
+
+		AttachmentIDs:  req.AttachmentIds,
+		ConversationID: req.ConversationId,
+		Nonce:          req.Nonce,
+	})
 	if err != nil {
 		return &pb.SendMessageResponse{
 			Error: err.Error(),
 		}, nil
 	}
 
-	// 转换为 protobuf 消息
-	wsMessage := &pb.WSMessage{
-		MessageId: message.ID,
-		UserId:    message.UserID,
-		GuildId:   message.GuildID,
-		Content:   message.Content,
-		SeqId:     message.SeqID,
-		Timestamp: message.CreatedAt.Unix(),
-		Type:      pb.MessageType_TEXT,
-	}
-
 	return &pb.SendMessageResponse{
-		Message: wsMessage,
+		Message: service.ToWSMessage(message, "", pb.MessageType_TEXT),
 	}, nil
 }
 
 // GetHistory 获取历史消息
 func (s *MessageServer) GetHistory(ctx context.Context, req *pb.HistoryRequest) (*pb.HistoryResponse, error) {
-	if req.GuildId == "" {
-		return nil, status.Error(codes.InvalidArgument, "guild_id is required")
+	if req.GuildId == "" && req.ConversationId == "" {
+		return nil, status.Error(codes.InvalidArgument, "guild_id or conversation_id is required")
 	}
 
 	// 调用业务层获取历史消息
-	messages, hasMore, err := s.messageService.GetMessagesWithUser(ctx, req.GuildId, req.LastSeqId, int(req.Limit))
+	page, err := s.messageService.GetMessagesWithUser(ctx, &service.GetMessagesRequest{
+		GuildID:     req.GuildId,
+		ChannelID:   req.ChannelId,
+		LastSeqID:   req.LastSeqId,
+		BeforeSeqID: req.BeforeSeqId,
+		AfterSeqID:  req.AfterSeqId,
+		AroundSeqID: req.AroundSeqId,
+		Limit:       int(req.Limit),
+
+		ConversationID: req.ConversationId,
+	})
 	if err != nil {
+		switch err {
+		case service.ErrChannelNotFound:
+			return nil, status.Error(codes.NotFound, err.Error())
+		case service.ErrInvalidHistoryCursor:
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	// 转换为 protobuf 消息
-	wsMessages := make([]*pb.WSMessage, len(messages))
-	for i, msg := range messages {
-		wsMessages[i] = &pb.WSMessage{
-			MessageId: msg.ID,
-			UserId:    msg.UserID,
-			GuildId:   msg.GuildID,
-			Content:   msg.Content,
-			SeqId:     msg.SeqID,
-			Timestamp: msg.CreatedAt.Unix(),
-			Type:      pb.MessageType_TEXT,
-			Username:  msg.Username,
-		}
+	wsMessages := make([]*pb.WSMessage, len(page.Messages))
+	for i, msg := range page.Messages {
+		wsMessages[i] = msg.ToWSMessage()
 	}
 
 	return &pb.HistoryResponse{
-		Messages: wsMessages,
-		HasMore:  hasMore,
+		Messages:      wsMessages,
+		HasMore:       page.HasMore,
+		HasMoreBefore: page.HasMoreBefore,
+		HasMoreAfter:  page.HasMoreAfter,
+	}, nil
+}
+
+// GetThread 获取话题消息
+func (s *MessageServer) GetThread(ctx context.Context, req *pb.ThreadRequest) (*pb.ThreadResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if req.RootMessageId == "" {
+		return nil, status.Error(codes.InvalidArgument, "root_message_id is required")
+	}
+
+	// 调用业务层获取话题消息
+	thread, err := s.messageService.GetThread(ctx, req.UserId, req.RootMessageId, req.AfterSeqId, int(req.Limit))
+	if err != nil {
+		switch err {
+		case service.ErrMessageNotFound:
+			return nil, status.Error(codes.NotFound, err.Error())
+		case service.ErrUserNotInGuild, service.ErrNotParticipant:
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	// 转换为 protobuf 消息
+	root := thread.Root.ToWSMessage()
+
+	wsMessages := make([]*pb.WSMessage, len(thread.Messages))
+	for i, msg := range thread.Messages {
+		wsMessages[i] = msg.ToWSMessage()
+	}
+
+	return &pb.ThreadResponse{
+		Root:      root,
+		Messages:  wsMessages,
+		HasMore:   thread.HasMore,
+		NextSeqId: thread.NextSeqID,
 	}, nil
 }
 
@@ -109,18 +163,192 @@ func (s *MessageServer) BatchGetMessages(ctx context.Context, req *pb.BatchGetMe
 	// 转换为 protobuf 消息
 	wsMessages := make([]*pb.WSMessage, len(messages))
 	for i, msg := range messages {
-		wsMessages[i] = &pb.WSMessage{
-			MessageId: msg.ID,
-			UserId:    msg.UserID,
-			GuildId:   msg.GuildID,
-			Content:   msg.Content,
-			SeqId:     msg.SeqID,
-			Timestamp: msg.CreatedAt.Unix(),
-			Type:      pb.MessageType_TEXT,
-		}
+		wsMessages[i] = service.ToWSMessage(msg, "", pb.MessageType_TEXT)
 	}
 
 	return &pb.BatchGetMessagesResponse{
 		Messages: wsMessages,
 	}, nil
 }
+
+// EditMessage 编辑消息（仅作者）
+func (s *MessageServer) EditMessage(ctx context.Context, req *pb.EditMessageRequest) (*pb.EditMessageResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if req.MessageId == "" {
+		return nil, status.Error(codes.InvalidArgument, "message_id is required")
+	}
+	if req.Content == "" {
+		return nil, status.Error(codes.InvalidArgument, "content is required")
+	}
+
+	// 调用业务层编辑消息
+	message, err := s.messageService.EditMessage(ctx, req.UserId, req.MessageId, req.Content)
+	if err != nil {
+		return &pb.EditMessageResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.EditMessageResponse{
+		Message: service.ToWSMessage(message, "", pb.MessageType_MESSAGE_EDIT),
+	}, nil
+}
+
+// DeleteMessage 删除消息（仅作者，软删除）
+func (s *MessageServer) DeleteMessage(ctx context.Context, req *pb.DeleteMessageRequest) (*pb.DeleteMessageResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if req.MessageId == "" {
+		return nil, status.Error(codes.InvalidArgument, "message_id is required")
+	}
+
+	// 调用业务层删除消息
+	if err := s.messageService.DeleteMessage(ctx, req.UserId, req.MessageId); err != nil {
+		return &pb.DeleteMessageResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.DeleteMessageResponse{
+		Success: true,
+	}, nil
+}
+
+// AddReaction 为消息添加表情回应
+func (s *MessageServer) AddReaction(ctx context.Context, req *pb.ReactionRequest) (*pb.ReactionResponse, error) {
+	if err := validateReactionRequest(req); err != nil {
+		return nil, err
+	}
+
+	// 调用业务层添加表情回应
+	if err := s.reactionService.AddReaction(ctx, req.UserId, req.MessageId, req.Emoji); err != nil {
+		return &pb.ReactionResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.ReactionResponse{
+		Success: true,
+	}, nil
+}
+
+// RemoveReaction 移除消息的表情回应
+func (s *MessageServer) RemoveReaction(ctx context.Context, req *pb.ReactionRequest) (*pb.ReactionResponse, error) {
+	if err := validateReactionRequest(req); err != nil {
+		return nil, err
+	}
+
+	// 调用业务层移除表情回应
+	if err := s.reactionService.RemoveReaction(ctx, req.UserId, req.MessageId, req.Emoji); err != nil {
+		return &pb.ReactionResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.ReactionResponse{
+		Success: true,
+	}, nil
+}
+
+// GetPinnedMessages 获取 Guild 的置顶消息
+func (s *MessageServer) GetPinnedMessages(ctx context.Context, req *pb.PinnedMessagesRequest) (*pb.PinnedMessagesResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if req.GuildId == "" {
+		return nil, status.Error(codes.InvalidArgument, "guild_id is required")
+	}
+
+	// 调用业务层获取置顶消息
+	messages, err := s.pinService.GetPinnedMessages(ctx, req.UserId, req.GuildId)
+	if err != nil {
+		if err == service.ErrUserNotInGuild {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// 转换为 protobuf 消息
+	wsMessages := make([]*pb.WSMessage, len(messages))
+	for i, msg := range messages {
+		wsMessages[i] = msg.ToWSMessage()
+	}
+
+	return &pb.PinnedMessagesResponse{
+		Messages: wsMessages,
+	}, nil
+}
+
+// SearchMessages 在 Guild 内全文检索消息
+func (s *MessageServer) SearchMessages(ctx context.Context, req *pb.SearchMessagesRequest) (*pb.SearchMessagesResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if req.GuildId == "" {
+		return nil, status.Error(codes.InvalidArgument, "guild_id is required")
+	}
+	if req.Query == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+
+	// 调用业务层检索消息
+	page, err := s.messageService.SearchMessages(ctx, &service.SearchMessagesRequest{
+		UserID:        req.UserId,
+		GuildID:       req.GuildId,
+		Query:         req.Query,
+		ChannelID:     req.ChannelId,
+		AuthorID:      req.AuthorId,
+		After:         req.After,
+		Before:        req.Before,
+		HasAttachment: req.HasAttachment,
+		Offset:        int(req.Offset),
+		Limit:         int(req.Limit),
+	})
+	if err != nil {
+		switch err {
+		case service.ErrUserNotInGuild:
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		case service.ErrChannelNotFound:
+			return nil, status.Error(codes.NotFound, err.Error())
+		case service.ErrInvalidSearchQuery:
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// 转换为 protobuf 消息
+	results := make([]*pb.SearchResult, len(page.Results))
+	for i, result := range page.Results {
+		results[i] = &pb.SearchResult{
+			Message: result.ToWSMessage(),
+			Rank:    result.Rank,
+			Snippet: result.Snippet,
+		}
+	}
+
+	return &pb.SearchMessagesResponse{
+		Results:    results,
+		HasMore:    page.HasMore,
+		NextOffset: int32(page.NextOffset),
+	}, nil
+}
+
+// validateReactionRequest 校验表情回应请求的必填字段
+func validateReactionRequest(req *pb.ReactionRequest) error {
+	if req.UserId == "" {
+		return status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if req.MessageId == "" {
+		return status.Error(codes.InvalidArgument, "message_id is required")
+	}
+	if req.Emoji == "" {
+		return status.Error(codes.InvalidArgument, "emoji is required")
+	}
+	return nil
+}
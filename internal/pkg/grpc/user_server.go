@@ -2,24 +2,28 @@ package grpc
 
 import (
 	"context"
+	"errors"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	pb "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
 	"github.com/Gopher0727/ChatRoom/internal/repository"
+	"github.com/Gopher0727/ChatRoom/internal/service"
 )
 
 // UserServer 实现 UserService gRPC 服务
 type UserServer struct {
 	pb.UnimplementedUserServiceServer
-	userRepo repository.IUserRepository
+	userRepo        repository.IUserRepository
+	presenceService service.IPresenceService
 }
 
 // NewUserServer 创建新的 User gRPC 服务器
-func NewUserServer(userRepo repository.IUserRepository) *UserServer {
+func NewUserServer(userRepo repository.IUserRepository, presenceService service.IPresenceService) *UserServer {
 	return &UserServer{
-		userRepo: userRepo,
+		userRepo:        userRepo,
+		presenceService: presenceService,
 	}
 }
 
@@ -77,10 +81,35 @@ func (s *UserServer) UpdateUserStatus(ctx context.Context, req *pb.UpdateUserSta
 		return nil, status.Error(codes.InvalidArgument, "user_id is required")
 	}
 
-	// 这里可以更新 Redis 中的用户在线状态
-	// 暂时返回成功
-	// TODO
+	// 设置状态优先，否则视为 Gateway 上报连接建立或断开
+	var err error
+	switch {
+	case req.Status != "":
+		err = s.presenceService.SetStatus(ctx, req.UserId, req.Status, req.CustomStatus)
+	case req.Online:
+		err = s.presenceService.Connected(ctx, req.UserId)
+	default:
+		err = s.presenceService.Disconnected(ctx, req.UserId)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, service.ErrInvalidStatus), errors.Is(err, service.ErrCustomStatusTooLong):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			return nil, status.Errorf(codes.Internal, "failed to update user status: %v", err)
+		}
+	}
+
+	presence, err := s.presenceService.GetPresence(ctx, req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user status: %v", err)
+	}
+
 	return &pb.UpdateUserStatusResponse{
-		Success: true,
+		Success:      true,
+		Status:       presence.Status,
+		CustomStatus: presence.CustomStatus,
 	}, nil
 }
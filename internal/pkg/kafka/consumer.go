@@ -153,7 +153,10 @@ func (h *consumerGroupHandler) Setup(sarama.ConsumerGroupSession) error {
 }
 
 // Cleanup is run at the end of a session, once all ConsumeClaim goroutines have exited.
-func (h *consumerGroupHandler) Cleanup(sarama.ConsumerGroupSession) error {
+// The marked offsets are committed right away, so that the next owner of the partitions
+// resumes after the last processed message when the consumer stops or rebalances.
+func (h *consumerGroupHandler) Cleanup(session sarama.ConsumerGroupSession) error {
+	session.Commit()
 	return nil
 }
 
@@ -169,6 +172,11 @@ func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession,
 
 			// Process message with retry logic
 			if err := h.processMessageWithRetry(session.Context(), message); err != nil {
+				// Interrupted by a shutdown or rebalance, leave the message unmarked for the next owner
+				if session.Context().Err() != nil {
+					return nil
+				}
+
 				// Send to DLQ after max retries
 				if dlqErr := h.sendToDLQ(session.Context(), message, err); dlqErr != nil {
 					fmt.Printf("Failed to send message to DLQ: %v\n", dlqErr)
@@ -3,6 +3,7 @@ package kafka
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/IBM/sarama"
@@ -15,6 +16,9 @@ import (
 type Producer struct {
 	producer sarama.SyncProducer
 	config   *config.KafkaConfig
+
+	// inflight counts the sends waiting for the brokers' acknowledgement
+	inflight atomic.Int64
 }
 
 // NewProducer creates a new Kafka producer instance.
@@ -77,7 +81,9 @@ func (p *Producer) Produce(ctx context.Context, topic string, key []byte, value
 		msg.Key = sarama.ByteEncoder(key)
 	}
 
+	p.inflight.Add(1)
 	partition, offset, err = p.producer.SendMessage(msg)
+	p.inflight.Add(-1)
 	if err != nil {
 		return 0, 0, fmt.Errorf("failed to send message to topic %s: %w", topic, err)
 	}
@@ -122,6 +128,28 @@ func (p *Producer) ProduceWithRetry(ctx context.Context, topic string, key []byt
 	return 0, 0, fmt.Errorf("failed to send message after %d attempts: %w", maxRetries, lastErr)
 }
 
+// Flush waits until every in-flight send has been acknowledged or has failed.
+// Sends started meanwhile are waited for as well, callers stop producing first.
+//
+// Parameters:
+//   - ctx: Context bounding the wait
+//
+// Returns:
+//   - error: The context error if sends were still in flight when it was done
+func (p *Producer) Flush(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for p.inflight.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d kafka sends still in flight: %w", p.inflight.Load(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
 // Close closes the Kafka producer and releases all resources.
 // It should be called when the producer is no longer needed.
 //
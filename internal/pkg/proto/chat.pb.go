@@ -25,19 +25,52 @@ const (
 type MessageType int32
 
 const (
-	MessageType_TEXT   MessageType = 0
-	MessageType_SYSTEM MessageType = 1
+	MessageType_TEXT              MessageType = 0
+	MessageType_SYSTEM            MessageType = 1
+	MessageType_MESSAGE_EDIT      MessageType = 2  // 消息被编辑
+	MessageType_MESSAGE_DELETE    MessageType = 3  // 消息被删除
+	MessageType_REACTION_ADD      MessageType = 4  // 添加表情回应
+	MessageType_REACTION_REMOVE   MessageType = 5  // 移除表情回应
+	MessageType_ACK               MessageType = 6  // 客户端上报已读位置 (上行)
+	MessageType_READ_STATE        MessageType = 7  // 已读位置变化，推送给用户的其他会话 (下行)
+	MessageType_TYPING            MessageType = 8  // 正在输入，临时事件，不落库也不分配 Seq ID
+	MessageType_PRESENCE_UPDATE   MessageType = 9  // 上行：设置自己的在线状态；下行：共同 Guild 成员的在线状态变化
+	MessageType_GUILD_SUBSCRIBE   MessageType = 10 // 控制帧：连接额外订阅 guild_ids 中的 Guild
+	MessageType_GUILD_UNSUBSCRIBE MessageType = 11 // 控制帧：连接取消订阅 guild_ids 中的 Guild
+	MessageType_RESUME            MessageType = 12 // 下行：会话恢复补发完成，携带各时间线最后补发的 seq_id
 )
 
 // Enum value maps for MessageType.
 var (
 	MessageType_name = map[int32]string{
-		0: "TEXT",
-		1: "SYSTEM",
+		0:  "TEXT",
+		1:  "SYSTEM",
+		2:  "MESSAGE_EDIT",
+		3:  "MESSAGE_DELETE",
+		4:  "REACTION_ADD",
+		5:  "REACTION_REMOVE",
+		6:  "ACK",
+		7:  "READ_STATE",
+		8:  "TYPING",
+		9:  "PRESENCE_UPDATE",
+		10: "GUILD_SUBSCRIBE",
+		11: "GUILD_UNSUBSCRIBE",
+		12: "RESUME",
 	}
 	MessageType_value = map[string]int32{
-		"TEXT":   0,
-		"SYSTEM": 1,
+		"TEXT":              0,
+		"SYSTEM":            1,
+		"MESSAGE_EDIT":      2,
+		"MESSAGE_DELETE":    3,
+		"REACTION_ADD":      4,
+		"REACTION_REMOVE":   5,
+		"ACK":               6,
+		"READ_STATE":        7,
+		"TYPING":            8,
+		"PRESENCE_UPDATE":   9,
+		"GUILD_SUBSCRIBE":   10,
+		"GUILD_UNSUBSCRIBE": 11,
+		"RESUME":            12,
 	}
 )
 
@@ -68,36 +101,927 @@ func (MessageType) EnumDescriptor() ([]byte, []int) {
 	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{0}
 }
 
+// 网关协议操作码，WebSocket 上的每一帧都是一个 GatewayFrame
+type GatewayOpcode int32
+
+const (
+	GatewayOpcode_DISPATCH      GatewayOpcode = 0  // 事件帧，上下行均携带 event
+	GatewayOpcode_HEARTBEAT     GatewayOpcode = 1  // 上行：客户端心跳
+	GatewayOpcode_IDENTIFY      GatewayOpcode = 2  // 上行：连接后的第一帧，携带 token 与能力声明
+	GatewayOpcode_HELLO         GatewayOpcode = 10 // 下行：连接建立后立即发送，携带心跳间隔
+	GatewayOpcode_READY         GatewayOpcode = 11 // 下行：认证成功，携带初始 Guild 与在线状态
+	GatewayOpcode_HEARTBEAT_ACK GatewayOpcode = 12 // 下行：心跳确认
+	GatewayOpcode_ERROR         GatewayOpcode = 13 // 下行：错误，携带错误码
+)
+
+// Enum value maps for GatewayOpcode.
+var (
+	GatewayOpcode_name = map[int32]string{
+		0:  "DISPATCH",
+		1:  "HEARTBEAT",
+		2:  "IDENTIFY",
+		10: "HELLO",
+		11: "READY",
+		12: "HEARTBEAT_ACK",
+		13: "ERROR",
+	}
+	GatewayOpcode_value = map[string]int32{
+		"DISPATCH":      0,
+		"HEARTBEAT":     1,
+		"IDENTIFY":      2,
+		"HELLO":         10,
+		"READY":         11,
+		"HEARTBEAT_ACK": 12,
+		"ERROR":         13,
+	}
+)
+
+func (x GatewayOpcode) Enum() *GatewayOpcode {
+	p := new(GatewayOpcode)
+	*p = x
+	return p
+}
+
+func (x GatewayOpcode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GatewayOpcode) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_pkg_proto_chat_proto_enumTypes[1].Descriptor()
+}
+
+func (GatewayOpcode) Type() protoreflect.EnumType {
+	return &file_internal_pkg_proto_chat_proto_enumTypes[1]
+}
+
+func (x GatewayOpcode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GatewayOpcode.Descriptor instead.
+func (GatewayOpcode) EnumDescriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{1}
+}
+
+// 网关错误码，致命错误同时作为 WebSocket 关闭码
+type GatewayErrorCode int32
+
+const (
+	GatewayErrorCode_ERROR_UNSPECIFIED           GatewayErrorCode = 0
+	GatewayErrorCode_ERROR_UNKNOWN               GatewayErrorCode = 4000 // 服务端内部错误
+	GatewayErrorCode_ERROR_UNKNOWN_OPCODE        GatewayErrorCode = 4001 // 无法识别的操作码
+	GatewayErrorCode_ERROR_DECODE                GatewayErrorCode = 4002 // 无法解析的帧
+	GatewayErrorCode_ERROR_NOT_AUTHENTICATED     GatewayErrorCode = 4003 // IDENTIFY 之前发送了其他帧
+	GatewayErrorCode_ERROR_AUTHENTICATION_FAILED GatewayErrorCode = 4004 // token 无效或已过期
+	GatewayErrorCode_ERROR_ALREADY_AUTHENTICATED GatewayErrorCode = 4005 // 重复发送 IDENTIFY
+	GatewayErrorCode_ERROR_INVALID_REQUEST       GatewayErrorCode = 4006 // 帧内容不合法
+	GatewayErrorCode_ERROR_FORBIDDEN             GatewayErrorCode = 4007 // 无权访问目标 Guild 或频道
+	GatewayErrorCode_ERROR_NOT_FOUND             GatewayErrorCode = 4008 // 目标频道不存在
+	GatewayErrorCode_ERROR_UNSUPPORTED_VERSION   GatewayErrorCode = 4009 // 不支持的协议版本
+	GatewayErrorCode_ERROR_SESSION_TIMEOUT       GatewayErrorCode = 4010 // 未在规定时间内完成 IDENTIFY
+	GatewayErrorCode_ERROR_NODE_DRAINING         GatewayErrorCode = 4011 // 节点正在下线，重连到其他节点并恢复会话
+	GatewayErrorCode_ERROR_SESSION_NOT_FOUND     GatewayErrorCode = 4012 // SSE / 长轮询会话不存在或已过期，需要重新建立
+	GatewayErrorCode_ERROR_RESUME_REQUIRED       GatewayErrorCode = 4013 // 会话丢失了事件（如发送队列溢出），需要重连并恢复会话
+)
+
+// Enum value maps for GatewayErrorCode.
+var (
+	GatewayErrorCode_name = map[int32]string{
+		0:    "ERROR_UNSPECIFIED",
+		4000: "ERROR_UNKNOWN",
+		4001: "ERROR_UNKNOWN_OPCODE",
+		4002: "ERROR_DECODE",
+		4003: "ERROR_NOT_AUTHENTICATED",
+		4004: "ERROR_AUTHENTICATION_FAILED",
+		4005: "ERROR_ALREADY_AUTHENTICATED",
+		4006: "ERROR_INVALID_REQUEST",
+		4007: "ERROR_FORBIDDEN",
+		4008: "ERROR_NOT_FOUND",
+		4009: "ERROR_UNSUPPORTED_VERSION",
+		4010: "ERROR_SESSION_TIMEOUT",
+		4011: "ERROR_NODE_DRAINING",
+		4012: "ERROR_SESSION_NOT_FOUND",
+		4013: "ERROR_RESUME_REQUIRED",
+	}
+	GatewayErrorCode_value = map[string]int32{
+		"ERROR_UNSPECIFIED":           0,
+		"ERROR_UNKNOWN":               4000,
+		"ERROR_UNKNOWN_OPCODE":        4001,
+		"ERROR_DECODE":                4002,
+		"ERROR_NOT_AUTHENTICATED":     4003,
+		"ERROR_AUTHENTICATION_FAILED": 4004,
+		"ERROR_ALREADY_AUTHENTICATED": 4005,
+		"ERROR_INVALID_REQUEST":       4006,
+		"ERROR_FORBIDDEN":             4007,
+		"ERROR_NOT_FOUND":             4008,
+		"ERROR_UNSUPPORTED_VERSION":   4009,
+		"ERROR_SESSION_TIMEOUT":       4010,
+		"ERROR_NODE_DRAINING":         4011,
+		"ERROR_SESSION_NOT_FOUND":     4012,
+		"ERROR_RESUME_REQUIRED":       4013,
+	}
+)
+
+func (x GatewayErrorCode) Enum() *GatewayErrorCode {
+	p := new(GatewayErrorCode)
+	*p = x
+	return p
+}
+
+func (x GatewayErrorCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GatewayErrorCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_pkg_proto_chat_proto_enumTypes[2].Descriptor()
+}
+
+func (GatewayErrorCode) Type() protoreflect.EnumType {
+	return &file_internal_pkg_proto_chat_proto_enumTypes[2]
+}
+
+func (x GatewayErrorCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GatewayErrorCode.Descriptor instead.
+func (GatewayErrorCode) EnumDescriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{2}
+}
+
+// 表情回应计数
+type ReactionCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Emoji         string                 `protobuf:"bytes,1,opt,name=emoji,proto3" json:"emoji,omitempty"`
+	Count         int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReactionCount) Reset() {
+	*x = ReactionCount{}
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReactionCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReactionCount) ProtoMessage() {}
+
+func (x *ReactionCount) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReactionCount.ProtoReflect.Descriptor instead.
+func (*ReactionCount) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ReactionCount) GetEmoji() string {
+	if x != nil {
+		return x.Emoji
+	}
+	return ""
+}
+
+func (x *ReactionCount) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// 附件描述，下载链接需通过 API 单独签发
+type Attachment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Filename      string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
+	ContentType   string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Attachment) Reset() {
+	*x = Attachment{}
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Attachment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attachment) ProtoMessage() {}
+
+func (x *Attachment) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attachment.ProtoReflect.Descriptor instead.
+func (*Attachment) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Attachment) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Attachment) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *Attachment) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *Attachment) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+// 会话恢复位置，对应一条 Guild 或频道时间线
+type ResumePosition struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GuildId       string                 `protobuf:"bytes,1,opt,name=guild_id,json=guildId,proto3" json:"guild_id,omitempty"`
+	ChannelId     string                 `protobuf:"bytes,2,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"` // 空表示 Guild 默认时间线
+	SeqId         int64                  `protobuf:"varint,3,opt,name=seq_id,json=seqId,proto3" json:"seq_id,omitempty"`            // IDENTIFY 中为最后收到的 seq_id，RESUME 事件中为最后补发的 seq_id
+	HasMore       bool                   `protobuf:"varint,4,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`      // 补发达到上限，剩余消息需通过历史接口从 seq_id 之后拉取
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumePosition) Reset() {
+	*x = ResumePosition{}
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumePosition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumePosition) ProtoMessage() {}
+
+func (x *ResumePosition) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumePosition.ProtoReflect.Descriptor instead.
+func (*ResumePosition) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ResumePosition) GetGuildId() string {
+	if x != nil {
+		return x.GuildId
+	}
+	return ""
+}
+
+func (x *ResumePosition) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *ResumePosition) GetSeqId() int64 {
+	if x != nil {
+		return x.SeqId
+	}
+	return 0
+}
+
+func (x *ResumePosition) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
 // WebSocket 消息
 type WSMessage struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	MessageId       string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	UserId          string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	GuildId         string                 `protobuf:"bytes,3,opt,name=guild_id,json=guildId,proto3" json:"guild_id,omitempty"`
+	Content         string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
+	SeqId           int64                  `protobuf:"varint,5,opt,name=seq_id,json=seqId,proto3" json:"seq_id,omitempty"`
+	Timestamp       int64                  `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // 事件时间 (毫秒)，消息事件为消息的创建时间
+	Type            MessageType            `protobuf:"varint,7,opt,name=type,proto3,enum=chat.MessageType" json:"type,omitempty"`
+	Username        string                 `protobuf:"bytes,8,opt,name=username,proto3" json:"username,omitempty"`
+	EditedAt        int64                  `protobuf:"varint,9,opt,name=edited_at,json=editedAt,proto3" json:"edited_at,omitempty"`                       // 最后编辑时间 (毫秒)，0 表示未编辑
+	ReplyToId       string                 `protobuf:"bytes,10,opt,name=reply_to_id,json=replyToId,proto3" json:"reply_to_id,omitempty"`                  // 回复的消息 ID
+	ThreadRootId    string                 `protobuf:"bytes,11,opt,name=thread_root_id,json=threadRootId,proto3" json:"thread_root_id,omitempty"`         // 所属话题的根消息 ID
+	Emoji           string                 `protobuf:"bytes,12,opt,name=emoji,proto3" json:"emoji,omitempty"`                                             // REACTION_ADD / REACTION_REMOVE 的表情
+	Reactions       []*ReactionCount       `protobuf:"bytes,13,rep,name=reactions,proto3" json:"reactions,omitempty"`                                     // 消息上的表情回应汇总
+	ChannelId       string                 `protobuf:"bytes,14,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`                    // 所属频道 ID，空表示 Guild 默认时间线
+	ConversationId  string                 `protobuf:"bytes,15,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`     // 私信会话 ID，私信消息的 guild_id 为空
+	RecipientIds    []string               `protobuf:"bytes,16,rep,name=recipient_ids,json=recipientIds,proto3" json:"recipient_ids,omitempty"`           // 私信接收者，仅用于服务端路由，不下发给客户端
+	MentionUserIds  []string               `protobuf:"bytes,17,rep,name=mention_user_ids,json=mentionUserIds,proto3" json:"mention_user_ids,omitempty"`   // 被显式 @ 的用户 ID
+	MentionEveryone bool                   `protobuf:"varint,18,opt,name=mention_everyone,json=mentionEveryone,proto3" json:"mention_everyone,omitempty"` // 是否包含 @everyone
+	MentionHere     bool                   `protobuf:"varint,19,opt,name=mention_here,json=mentionHere,proto3" json:"mention_here,omitempty"`             // 是否包含 @here
+	Attachments     []*Attachment          `protobuf:"bytes,20,rep,name=attachments,proto3" json:"attachments,omitempty"`                                 // 消息附件，上行时只需填写 id
+	TtlMs           int64                  `protobuf:"varint,21,opt,name=ttl_ms,json=ttlMs,proto3" json:"ttl_ms,omitempty"`                               // 临时事件的有效期 (毫秒)，从 timestamp 起算
+	Status          string                 `protobuf:"bytes,22,opt,name=status,proto3" json:"status,omitempty"`                                           // 在线状态：online, idle, dnd, invisible (仅上行), offline
+	CustomStatus    string                 `protobuf:"bytes,23,opt,name=custom_status,json=customStatus,proto3" json:"custom_status,omitempty"`           // 自定义状态文本
+	GuildIds        []string               `protobuf:"bytes,24,rep,name=guild_ids,json=guildIds,proto3" json:"guild_ids,omitempty"`                       // 订阅控制帧的目标 Guild，回复中为连接当前订阅的全部 Guild
+	ResumePositions []*ResumePosition      `protobuf:"bytes,25,rep,name=resume_positions,json=resumePositions,proto3" json:"resume_positions,omitempty"`  // RESUME 事件的各时间线位置
+	Nonce           string                 `protobuf:"bytes,26,opt,name=nonce,proto3" json:"nonce,omitempty"`                                             // 客户端生成的幂等键，重试时返回原消息，推送新消息时原样带回
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *WSMessage) Reset() {
+	*x = WSMessage{}
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WSMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WSMessage) ProtoMessage() {}
+
+func (x *WSMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WSMessage.ProtoReflect.Descriptor instead.
+func (*WSMessage) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *WSMessage) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *WSMessage) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *WSMessage) GetGuildId() string {
+	if x != nil {
+		return x.GuildId
+	}
+	return ""
+}
+
+func (x *WSMessage) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *WSMessage) GetSeqId() int64 {
+	if x != nil {
+		return x.SeqId
+	}
+	return 0
+}
+
+func (x *WSMessage) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *WSMessage) GetType() MessageType {
+	if x != nil {
+		return x.Type
+	}
+	return MessageType_TEXT
+}
+
+func (x *WSMessage) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *WSMessage) GetEditedAt() int64 {
+	if x != nil {
+		return x.EditedAt
+	}
+	return 0
+}
+
+func (x *WSMessage) GetReplyToId() string {
+	if x != nil {
+		return x.ReplyToId
+	}
+	return ""
+}
+
+func (x *WSMessage) GetThreadRootId() string {
+	if x != nil {
+		return x.ThreadRootId
+	}
+	return ""
+}
+
+func (x *WSMessage) GetEmoji() string {
+	if x != nil {
+		return x.Emoji
+	}
+	return ""
+}
+
+func (x *WSMessage) GetReactions() []*ReactionCount {
+	if x != nil {
+		return x.Reactions
+	}
+	return nil
+}
+
+func (x *WSMessage) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *WSMessage) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *WSMessage) GetRecipientIds() []string {
+	if x != nil {
+		return x.RecipientIds
+	}
+	return nil
+}
+
+func (x *WSMessage) GetMentionUserIds() []string {
+	if x != nil {
+		return x.MentionUserIds
+	}
+	return nil
+}
+
+func (x *WSMessage) GetMentionEveryone() bool {
+	if x != nil {
+		return x.MentionEveryone
+	}
+	return false
+}
+
+func (x *WSMessage) GetMentionHere() bool {
+	if x != nil {
+		return x.MentionHere
+	}
+	return false
+}
+
+func (x *WSMessage) GetAttachments() []*Attachment {
+	if x != nil {
+		return x.Attachments
+	}
+	return nil
+}
+
+func (x *WSMessage) GetTtlMs() int64 {
+	if x != nil {
+		return x.TtlMs
+	}
+	return 0
+}
+
+func (x *WSMessage) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *WSMessage) GetCustomStatus() string {
+	if x != nil {
+		return x.CustomStatus
+	}
+	return ""
+}
+
+func (x *WSMessage) GetGuildIds() []string {
+	if x != nil {
+		return x.GuildIds
+	}
+	return nil
+}
+
+func (x *WSMessage) GetResumePositions() []*ResumePosition {
+	if x != nil {
+		return x.ResumePositions
+	}
+	return nil
+}
+
+func (x *WSMessage) GetNonce() string {
+	if x != nil {
+		return x.Nonce
+	}
+	return ""
+}
+
+// 网关帧信封
+type GatewayFrame struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       int32                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"` // 协议版本，当前为 1
+	Op            GatewayOpcode          `protobuf:"varint,2,opt,name=op,proto3,enum=chat.GatewayOpcode" json:"op,omitempty"`
+	Event         *WSMessage             `protobuf:"bytes,3,opt,name=event,proto3" json:"event,omitempty"`       // DISPATCH
+	Hello         *Hello                 `protobuf:"bytes,4,opt,name=hello,proto3" json:"hello,omitempty"`       // HELLO
+	Identify      *Identify              `protobuf:"bytes,5,opt,name=identify,proto3" json:"identify,omitempty"` // IDENTIFY
+	Ready         *Ready                 `protobuf:"bytes,6,opt,name=ready,proto3" json:"ready,omitempty"`       // READY
+	Error         *GatewayError          `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`       // ERROR
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GatewayFrame) Reset() {
+	*x = GatewayFrame{}
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GatewayFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GatewayFrame) ProtoMessage() {}
+
+func (x *GatewayFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GatewayFrame.ProtoReflect.Descriptor instead.
+func (*GatewayFrame) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GatewayFrame) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *GatewayFrame) GetOp() GatewayOpcode {
+	if x != nil {
+		return x.Op
+	}
+	return GatewayOpcode_DISPATCH
+}
+
+func (x *GatewayFrame) GetEvent() *WSMessage {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *GatewayFrame) GetHello() *Hello {
+	if x != nil {
+		return x.Hello
+	}
+	return nil
+}
+
+func (x *GatewayFrame) GetIdentify() *Identify {
+	if x != nil {
+		return x.Identify
+	}
+	return nil
+}
+
+func (x *GatewayFrame) GetReady() *Ready {
+	if x != nil {
+		return x.Ready
+	}
+	return nil
+}
+
+func (x *GatewayFrame) GetError() *GatewayError {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+// HELLO 载荷
+type Hello struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	HeartbeatIntervalMs int64                  `protobuf:"varint,1,opt,name=heartbeat_interval_ms,json=heartbeatIntervalMs,proto3" json:"heartbeat_interval_ms,omitempty"` // 客户端发送 HEARTBEAT 的间隔
+	IdentifyTimeoutMs   int64                  `protobuf:"varint,2,opt,name=identify_timeout_ms,json=identifyTimeoutMs,proto3" json:"identify_timeout_ms,omitempty"`       // 须在该时间内发送 IDENTIFY
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Hello) Reset() {
+	*x = Hello{}
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Hello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Hello) ProtoMessage() {}
+
+func (x *Hello) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Hello.ProtoReflect.Descriptor instead.
+func (*Hello) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Hello) GetHeartbeatIntervalMs() int64 {
+	if x != nil {
+		return x.HeartbeatIntervalMs
+	}
+	return 0
+}
+
+func (x *Hello) GetIdentifyTimeoutMs() int64 {
+	if x != nil {
+		return x.IdentifyTimeoutMs
+	}
+	return 0
+}
+
+// IDENTIFY 载荷
+type Identify struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Token           string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Capabilities    []string               `protobuf:"bytes,2,rep,name=capabilities,proto3" json:"capabilities,omitempty"`                              // 客户端订阅的可选事件，如 typing、presence
+	GuildId         string                 `protobuf:"bytes,3,opt,name=guild_id,json=guildId,proto3" json:"guild_id,omitempty"`                         // 会话所属 Guild，可选
+	ChannelId       string                 `protobuf:"bytes,4,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`                   // 会话所属频道，可选
+	Device          string                 `protobuf:"bytes,5,opt,name=device,proto3" json:"device,omitempty"`                                          // 设备标签，可选
+	ResumePositions []*ResumePosition      `protobuf:"bytes,6,rep,name=resume_positions,json=resumePositions,proto3" json:"resume_positions,omitempty"` // 断线重连时各时间线最后收到的位置，设置后补发错过的消息
+	GuildIds        []string               `protobuf:"bytes,7,rep,name=guild_ids,json=guildIds,proto3" json:"guild_ids,omitempty"`                      // 额外订阅的 Guild，可选，需包含恢复位置所在的 Guild
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Identify) Reset() {
+	*x = Identify{}
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Identify) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Identify) ProtoMessage() {}
+
+func (x *Identify) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Identify.ProtoReflect.Descriptor instead.
+func (*Identify) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Identify) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *Identify) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *Identify) GetGuildId() string {
+	if x != nil {
+		return x.GuildId
+	}
+	return ""
+}
+
+func (x *Identify) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *Identify) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+func (x *Identify) GetResumePositions() []*ResumePosition {
+	if x != nil {
+		return x.ResumePositions
+	}
+	return nil
+}
+
+func (x *Identify) GetGuildIds() []string {
+	if x != nil {
+		return x.GuildIds
+	}
+	return nil
+}
+
+// READY 中的 Guild 及未读状态
+type ReadyGuild struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	UnreadCount   int64                  `protobuf:"varint,3,opt,name=unread_count,json=unreadCount,proto3" json:"unread_count,omitempty"`
+	MentionCount  int64                  `protobuf:"varint,4,opt,name=mention_count,json=mentionCount,proto3" json:"mention_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadyGuild) Reset() {
+	*x = ReadyGuild{}
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadyGuild) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadyGuild) ProtoMessage() {}
+
+func (x *ReadyGuild) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadyGuild.ProtoReflect.Descriptor instead.
+func (*ReadyGuild) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ReadyGuild) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ReadyGuild) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ReadyGuild) GetUnreadCount() int64 {
+	if x != nil {
+		return x.UnreadCount
+	}
+	return 0
+}
+
+func (x *ReadyGuild) GetMentionCount() int64 {
+	if x != nil {
+		return x.MentionCount
+	}
+	return 0
+}
+
+// READY 载荷
+type Ready struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
 	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	GuildId       string                 `protobuf:"bytes,3,opt,name=guild_id,json=guildId,proto3" json:"guild_id,omitempty"`
-	Content       string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
-	SeqId         int64                  `protobuf:"varint,5,opt,name=seq_id,json=seqId,proto3" json:"seq_id,omitempty"`
-	Timestamp     int64                  `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Type          MessageType            `protobuf:"varint,7,opt,name=type,proto3,enum=chat.MessageType" json:"type,omitempty"`
-	Username      string                 `protobuf:"bytes,8,opt,name=username,proto3" json:"username,omitempty"`
+	Guilds        []*ReadyGuild          `protobuf:"bytes,3,rep,name=guilds,proto3" json:"guilds,omitempty"` // 用户加入的全部 Guild
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"` // 自己当前的在线状态
+	CustomStatus  string                 `protobuf:"bytes,5,opt,name=custom_status,json=customStatus,proto3" json:"custom_status,omitempty"`
+	Capabilities  []string               `protobuf:"bytes,6,rep,name=capabilities,proto3" json:"capabilities,omitempty"`         // 服务端接受的能力声明
+	GuildIds      []string               `protobuf:"bytes,7,rep,name=guild_ids,json=guildIds,proto3" json:"guild_ids,omitempty"` // 会话当前订阅的 Guild
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *WSMessage) Reset() {
-	*x = WSMessage{}
-	mi := &file_internal_pkg_proto_chat_proto_msgTypes[0]
+func (x *Ready) Reset() {
+	*x = Ready{}
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *WSMessage) String() string {
+func (x *Ready) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WSMessage) ProtoMessage() {}
+func (*Ready) ProtoMessage() {}
 
-func (x *WSMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_chat_proto_msgTypes[0]
+func (x *Ready) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -108,80 +1032,131 @@ func (x *WSMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WSMessage.ProtoReflect.Descriptor instead.
-func (*WSMessage) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use Ready.ProtoReflect.Descriptor instead.
+func (*Ready) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *WSMessage) GetMessageId() string {
+func (x *Ready) GetSessionId() string {
 	if x != nil {
-		return x.MessageId
+		return x.SessionId
 	}
 	return ""
 }
 
-func (x *WSMessage) GetUserId() string {
+func (x *Ready) GetUserId() string {
 	if x != nil {
 		return x.UserId
 	}
 	return ""
 }
 
-func (x *WSMessage) GetGuildId() string {
+func (x *Ready) GetGuilds() []*ReadyGuild {
 	if x != nil {
-		return x.GuildId
+		return x.Guilds
+	}
+	return nil
+}
+
+func (x *Ready) GetStatus() string {
+	if x != nil {
+		return x.Status
 	}
 	return ""
 }
 
-func (x *WSMessage) GetContent() string {
+func (x *Ready) GetCustomStatus() string {
 	if x != nil {
-		return x.Content
+		return x.CustomStatus
 	}
 	return ""
 }
 
-func (x *WSMessage) GetSeqId() int64 {
+func (x *Ready) GetCapabilities() []string {
 	if x != nil {
-		return x.SeqId
+		return x.Capabilities
 	}
-	return 0
+	return nil
 }
 
-func (x *WSMessage) GetTimestamp() int64 {
+func (x *Ready) GetGuildIds() []string {
 	if x != nil {
-		return x.Timestamp
+		return x.GuildIds
 	}
-	return 0
+	return nil
 }
 
-func (x *WSMessage) GetType() MessageType {
+// ERROR 载荷
+type GatewayError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          GatewayErrorCode       `protobuf:"varint,1,opt,name=code,proto3,enum=chat.GatewayErrorCode" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GatewayError) Reset() {
+	*x = GatewayError{}
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GatewayError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GatewayError) ProtoMessage() {}
+
+func (x *GatewayError) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[9]
 	if x != nil {
-		return x.Type
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return MessageType_TEXT
+	return mi.MessageOf(x)
 }
 
-func (x *WSMessage) GetUsername() string {
+// Deprecated: Use GatewayError.ProtoReflect.Descriptor instead.
+func (*GatewayError) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GatewayError) GetCode() GatewayErrorCode {
 	if x != nil {
-		return x.Username
+		return x.Code
+	}
+	return GatewayErrorCode_ERROR_UNSPECIFIED
+}
+
+func (x *GatewayError) GetMessage() string {
+	if x != nil {
+		return x.Message
 	}
 	return ""
 }
 
 // 历史消息请求
 type HistoryRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	GuildId       string                 `protobuf:"bytes,1,opt,name=guild_id,json=guildId,proto3" json:"guild_id,omitempty"`
-	LastSeqId     int64                  `protobuf:"varint,2,opt,name=last_seq_id,json=lastSeqId,proto3" json:"last_seq_id,omitempty"`
-	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	GuildId        string                 `protobuf:"bytes,1,opt,name=guild_id,json=guildId,proto3" json:"guild_id,omitempty"`
+	LastSeqId      int64                  `protobuf:"varint,2,opt,name=last_seq_id,json=lastSeqId,proto3" json:"last_seq_id,omitempty"`
+	Limit          int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	ChannelId      string                 `protobuf:"bytes,4,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`                // 按频道过滤，空表示 Guild 默认时间线
+	ConversationId string                 `protobuf:"bytes,5,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // 私信会话 ID，设置时忽略 guild_id 和 channel_id
+	BeforeSeqId    int64                  `protobuf:"varint,6,opt,name=before_seq_id,json=beforeSeqId,proto3" json:"before_seq_id,omitempty"`       // 向前翻页：seq_id 小于该值的消息
+	AfterSeqId     int64                  `protobuf:"varint,7,opt,name=after_seq_id,json=afterSeqId,proto3" json:"after_seq_id,omitempty"`          // 向后翻页：seq_id 大于该值的消息，last_seq_id 为其旧名
+	AroundSeqId    int64                  `protobuf:"varint,8,opt,name=around_seq_id,json=aroundSeqId,proto3" json:"around_seq_id,omitempty"`       // 以该消息为中心的窗口，用于跳转到指定消息
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *HistoryRequest) Reset() {
 	*x = HistoryRequest{}
-	mi := &file_internal_pkg_proto_chat_proto_msgTypes[1]
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -193,7 +1168,7 @@ func (x *HistoryRequest) String() string {
 func (*HistoryRequest) ProtoMessage() {}
 
 func (x *HistoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_chat_proto_msgTypes[1]
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -206,7 +1181,7 @@ func (x *HistoryRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HistoryRequest.ProtoReflect.Descriptor instead.
 func (*HistoryRequest) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{1}
+	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *HistoryRequest) GetGuildId() string {
@@ -230,18 +1205,55 @@ func (x *HistoryRequest) GetLimit() int32 {
 	return 0
 }
 
-// 历史消息响应
+func (x *HistoryRequest) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *HistoryRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *HistoryRequest) GetBeforeSeqId() int64 {
+	if x != nil {
+		return x.BeforeSeqId
+	}
+	return 0
+}
+
+func (x *HistoryRequest) GetAfterSeqId() int64 {
+	if x != nil {
+		return x.AfterSeqId
+	}
+	return 0
+}
+
+func (x *HistoryRequest) GetAroundSeqId() int64 {
+	if x != nil {
+		return x.AroundSeqId
+	}
+	return 0
+}
+
+// 历史消息响应，消息始终按 seq_id 升序
 type HistoryResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Messages      []*WSMessage           `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
-	HasMore       bool                   `protobuf:"varint,2,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	HasMore       bool                   `protobuf:"varint,2,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`                     // 翻页方向上是否还有消息
+	HasMoreBefore bool                   `protobuf:"varint,3,opt,name=has_more_before,json=hasMoreBefore,proto3" json:"has_more_before,omitempty"` // 是否还有更早的消息
+	HasMoreAfter  bool                   `protobuf:"varint,4,opt,name=has_more_after,json=hasMoreAfter,proto3" json:"has_more_after,omitempty"`    // 是否还有更新的消息
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *HistoryResponse) Reset() {
 	*x = HistoryResponse{}
-	mi := &file_internal_pkg_proto_chat_proto_msgTypes[2]
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -253,7 +1265,7 @@ func (x *HistoryResponse) String() string {
 func (*HistoryResponse) ProtoMessage() {}
 
 func (x *HistoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_chat_proto_msgTypes[2]
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -266,7 +1278,7 @@ func (x *HistoryResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HistoryResponse.ProtoReflect.Descriptor instead.
 func (*HistoryResponse) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{2}
+	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *HistoryResponse) GetMessages() []*WSMessage {
@@ -283,11 +1295,178 @@ func (x *HistoryResponse) GetHasMore() bool {
 	return false
 }
 
+func (x *HistoryResponse) GetHasMoreBefore() bool {
+	if x != nil {
+		return x.HasMoreBefore
+	}
+	return false
+}
+
+func (x *HistoryResponse) GetHasMoreAfter() bool {
+	if x != nil {
+		return x.HasMoreAfter
+	}
+	return false
+}
+
+// 话题消息请求
+type ThreadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	RootMessageId string                 `protobuf:"bytes,2,opt,name=root_message_id,json=rootMessageId,proto3" json:"root_message_id,omitempty"`
+	AfterSeqId    int64                  `protobuf:"varint,3,opt,name=after_seq_id,json=afterSeqId,proto3" json:"after_seq_id,omitempty"`
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ThreadRequest) Reset() {
+	*x = ThreadRequest{}
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ThreadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThreadRequest) ProtoMessage() {}
+
+func (x *ThreadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThreadRequest.ProtoReflect.Descriptor instead.
+func (*ThreadRequest) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ThreadRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ThreadRequest) GetRootMessageId() string {
+	if x != nil {
+		return x.RootMessageId
+	}
+	return ""
+}
+
+func (x *ThreadRequest) GetAfterSeqId() int64 {
+	if x != nil {
+		return x.AfterSeqId
+	}
+	return 0
+}
+
+func (x *ThreadRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// 话题消息响应
+type ThreadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Root          *WSMessage             `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	Messages      []*WSMessage           `protobuf:"bytes,2,rep,name=messages,proto3" json:"messages,omitempty"`
+	HasMore       bool                   `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	NextSeqId     int64                  `protobuf:"varint,4,opt,name=next_seq_id,json=nextSeqId,proto3" json:"next_seq_id,omitempty"` // 下一页的游标
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ThreadResponse) Reset() {
+	*x = ThreadResponse{}
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ThreadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThreadResponse) ProtoMessage() {}
+
+func (x *ThreadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_chat_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThreadResponse.ProtoReflect.Descriptor instead.
+func (*ThreadResponse) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_chat_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ThreadResponse) GetRoot() *WSMessage {
+	if x != nil {
+		return x.Root
+	}
+	return nil
+}
+
+func (x *ThreadResponse) GetMessages() []*WSMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *ThreadResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+func (x *ThreadResponse) GetNextSeqId() int64 {
+	if x != nil {
+		return x.NextSeqId
+	}
+	return 0
+}
+
 var File_internal_pkg_proto_chat_proto protoreflect.FileDescriptor
 
 const file_internal_pkg_proto_chat_proto_rawDesc = "" +
 	"\n" +
-	"\x1dinternal/pkg/proto/chat.proto\x12\x04chat\"\xf0\x01\n" +
+	"\x1dinternal/pkg/proto/chat.proto\x12\x04chat\";\n" +
+	"\rReactionCount\x12\x14\n" +
+	"\x05emoji\x18\x01 \x01(\tR\x05emoji\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\"o\n" +
+	"\n" +
+	"Attachment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\"|\n" +
+	"\x0eResumePosition\x12\x19\n" +
+	"\bguild_id\x18\x01 \x01(\tR\aguildId\x12\x1d\n" +
+	"\n" +
+	"channel_id\x18\x02 \x01(\tR\tchannelId\x12\x15\n" +
+	"\x06seq_id\x18\x03 \x01(\x03R\x05seqId\x12\x19\n" +
+	"\bhas_more\x18\x04 \x01(\bR\ahasMore\"\xfd\x06\n" +
 	"\tWSMessage\x12\x1d\n" +
 	"\n" +
 	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x17\n" +
@@ -297,18 +1476,136 @@ const file_internal_pkg_proto_chat_proto_rawDesc = "" +
 	"\x06seq_id\x18\x05 \x01(\x03R\x05seqId\x12\x1c\n" +
 	"\ttimestamp\x18\x06 \x01(\x03R\ttimestamp\x12%\n" +
 	"\x04type\x18\a \x01(\x0e2\x11.chat.MessageTypeR\x04type\x12\x1a\n" +
-	"\busername\x18\b \x01(\tR\busername\"a\n" +
+	"\busername\x18\b \x01(\tR\busername\x12\x1b\n" +
+	"\tedited_at\x18\t \x01(\x03R\beditedAt\x12\x1e\n" +
+	"\vreply_to_id\x18\n" +
+	" \x01(\tR\treplyToId\x12$\n" +
+	"\x0ethread_root_id\x18\v \x01(\tR\fthreadRootId\x12\x14\n" +
+	"\x05emoji\x18\f \x01(\tR\x05emoji\x121\n" +
+	"\treactions\x18\r \x03(\v2\x13.chat.ReactionCountR\treactions\x12\x1d\n" +
+	"\n" +
+	"channel_id\x18\x0e \x01(\tR\tchannelId\x12'\n" +
+	"\x0fconversation_id\x18\x0f \x01(\tR\x0econversationId\x12#\n" +
+	"\rrecipient_ids\x18\x10 \x03(\tR\frecipientIds\x12(\n" +
+	"\x10mention_user_ids\x18\x11 \x03(\tR\x0ementionUserIds\x12)\n" +
+	"\x10mention_everyone\x18\x12 \x01(\bR\x0fmentionEveryone\x12!\n" +
+	"\fmention_here\x18\x13 \x01(\bR\vmentionHere\x122\n" +
+	"\vattachments\x18\x14 \x03(\v2\x10.chat.AttachmentR\vattachments\x12\x15\n" +
+	"\x06ttl_ms\x18\x15 \x01(\x03R\x05ttlMs\x12\x16\n" +
+	"\x06status\x18\x16 \x01(\tR\x06status\x12#\n" +
+	"\rcustom_status\x18\x17 \x01(\tR\fcustomStatus\x12\x1b\n" +
+	"\tguild_ids\x18\x18 \x03(\tR\bguildIds\x12?\n" +
+	"\x10resume_positions\x18\x19 \x03(\v2\x14.chat.ResumePositionR\x0fresumePositions\x12\x14\n" +
+	"\x05nonce\x18\x1a \x01(\tR\x05nonce\"\x90\x02\n" +
+	"\fGatewayFrame\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x05R\aversion\x12#\n" +
+	"\x02op\x18\x02 \x01(\x0e2\x13.chat.GatewayOpcodeR\x02op\x12%\n" +
+	"\x05event\x18\x03 \x01(\v2\x0f.chat.WSMessageR\x05event\x12!\n" +
+	"\x05hello\x18\x04 \x01(\v2\v.chat.HelloR\x05hello\x12*\n" +
+	"\bidentify\x18\x05 \x01(\v2\x0e.chat.IdentifyR\bidentify\x12!\n" +
+	"\x05ready\x18\x06 \x01(\v2\v.chat.ReadyR\x05ready\x12(\n" +
+	"\x05error\x18\a \x01(\v2\x12.chat.GatewayErrorR\x05error\"k\n" +
+	"\x05Hello\x122\n" +
+	"\x15heartbeat_interval_ms\x18\x01 \x01(\x03R\x13heartbeatIntervalMs\x12.\n" +
+	"\x13identify_timeout_ms\x18\x02 \x01(\x03R\x11identifyTimeoutMs\"\xf4\x01\n" +
+	"\bIdentify\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\"\n" +
+	"\fcapabilities\x18\x02 \x03(\tR\fcapabilities\x12\x19\n" +
+	"\bguild_id\x18\x03 \x01(\tR\aguildId\x12\x1d\n" +
+	"\n" +
+	"channel_id\x18\x04 \x01(\tR\tchannelId\x12\x16\n" +
+	"\x06device\x18\x05 \x01(\tR\x06device\x12?\n" +
+	"\x10resume_positions\x18\x06 \x03(\v2\x14.chat.ResumePositionR\x0fresumePositions\x12\x1b\n" +
+	"\tguild_ids\x18\a \x03(\tR\bguildIds\"x\n" +
+	"\n" +
+	"ReadyGuild\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12!\n" +
+	"\funread_count\x18\x03 \x01(\x03R\vunreadCount\x12#\n" +
+	"\rmention_count\x18\x04 \x01(\x03R\fmentionCount\"\xe7\x01\n" +
+	"\x05Ready\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12(\n" +
+	"\x06guilds\x18\x03 \x03(\v2\x10.chat.ReadyGuildR\x06guilds\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12#\n" +
+	"\rcustom_status\x18\x05 \x01(\tR\fcustomStatus\x12\"\n" +
+	"\fcapabilities\x18\x06 \x03(\tR\fcapabilities\x12\x1b\n" +
+	"\tguild_ids\x18\a \x03(\tR\bguildIds\"T\n" +
+	"\fGatewayError\x12*\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x16.chat.GatewayErrorCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x93\x02\n" +
 	"\x0eHistoryRequest\x12\x19\n" +
 	"\bguild_id\x18\x01 \x01(\tR\aguildId\x12\x1e\n" +
 	"\vlast_seq_id\x18\x02 \x01(\x03R\tlastSeqId\x12\x14\n" +
-	"\x05limit\x18\x03 \x01(\x05R\x05limit\"Y\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x1d\n" +
+	"\n" +
+	"channel_id\x18\x04 \x01(\tR\tchannelId\x12'\n" +
+	"\x0fconversation_id\x18\x05 \x01(\tR\x0econversationId\x12\"\n" +
+	"\rbefore_seq_id\x18\x06 \x01(\x03R\vbeforeSeqId\x12 \n" +
+	"\fafter_seq_id\x18\a \x01(\x03R\n" +
+	"afterSeqId\x12\"\n" +
+	"\raround_seq_id\x18\b \x01(\x03R\varoundSeqId\"\xa7\x01\n" +
 	"\x0fHistoryResponse\x12+\n" +
 	"\bmessages\x18\x01 \x03(\v2\x0f.chat.WSMessageR\bmessages\x12\x19\n" +
-	"\bhas_more\x18\x02 \x01(\bR\ahasMore*#\n" +
+	"\bhas_more\x18\x02 \x01(\bR\ahasMore\x12&\n" +
+	"\x0fhas_more_before\x18\x03 \x01(\bR\rhasMoreBefore\x12$\n" +
+	"\x0ehas_more_after\x18\x04 \x01(\bR\fhasMoreAfter\"\x88\x01\n" +
+	"\rThreadRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12&\n" +
+	"\x0froot_message_id\x18\x02 \x01(\tR\rrootMessageId\x12 \n" +
+	"\fafter_seq_id\x18\x03 \x01(\x03R\n" +
+	"afterSeqId\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\"\x9d\x01\n" +
+	"\x0eThreadResponse\x12#\n" +
+	"\x04root\x18\x01 \x01(\v2\x0f.chat.WSMessageR\x04root\x12+\n" +
+	"\bmessages\x18\x02 \x03(\v2\x0f.chat.WSMessageR\bmessages\x12\x19\n" +
+	"\bhas_more\x18\x03 \x01(\bR\ahasMore\x12\x1e\n" +
+	"\vnext_seq_id\x18\x04 \x01(\x03R\tnextSeqId*\xe2\x01\n" +
 	"\vMessageType\x12\b\n" +
 	"\x04TEXT\x10\x00\x12\n" +
 	"\n" +
-	"\x06SYSTEM\x10\x01B&Z$github.com/Gopher0727/ChatRoom/protob\x06proto3"
+	"\x06SYSTEM\x10\x01\x12\x10\n" +
+	"\fMESSAGE_EDIT\x10\x02\x12\x12\n" +
+	"\x0eMESSAGE_DELETE\x10\x03\x12\x10\n" +
+	"\fREACTION_ADD\x10\x04\x12\x13\n" +
+	"\x0fREACTION_REMOVE\x10\x05\x12\a\n" +
+	"\x03ACK\x10\x06\x12\x0e\n" +
+	"\n" +
+	"READ_STATE\x10\a\x12\n" +
+	"\n" +
+	"\x06TYPING\x10\b\x12\x13\n" +
+	"\x0fPRESENCE_UPDATE\x10\t\x12\x13\n" +
+	"\x0fGUILD_SUBSCRIBE\x10\n" +
+	"\x12\x15\n" +
+	"\x11GUILD_UNSUBSCRIBE\x10\v\x12\n" +
+	"\n" +
+	"\x06RESUME\x10\f*n\n" +
+	"\rGatewayOpcode\x12\f\n" +
+	"\bDISPATCH\x10\x00\x12\r\n" +
+	"\tHEARTBEAT\x10\x01\x12\f\n" +
+	"\bIDENTIFY\x10\x02\x12\t\n" +
+	"\x05HELLO\x10\n" +
+	"\x12\t\n" +
+	"\x05READY\x10\v\x12\x11\n" +
+	"\rHEARTBEAT_ACK\x10\f\x12\t\n" +
+	"\x05ERROR\x10\r*\xa5\x03\n" +
+	"\x10GatewayErrorCode\x12\x15\n" +
+	"\x11ERROR_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\rERROR_UNKNOWN\x10\xa0\x1f\x12\x19\n" +
+	"\x14ERROR_UNKNOWN_OPCODE\x10\xa1\x1f\x12\x11\n" +
+	"\fERROR_DECODE\x10\xa2\x1f\x12\x1c\n" +
+	"\x17ERROR_NOT_AUTHENTICATED\x10\xa3\x1f\x12 \n" +
+	"\x1bERROR_AUTHENTICATION_FAILED\x10\xa4\x1f\x12 \n" +
+	"\x1bERROR_ALREADY_AUTHENTICATED\x10\xa5\x1f\x12\x1a\n" +
+	"\x15ERROR_INVALID_REQUEST\x10\xa6\x1f\x12\x14\n" +
+	"\x0fERROR_FORBIDDEN\x10\xa7\x1f\x12\x14\n" +
+	"\x0fERROR_NOT_FOUND\x10\xa8\x1f\x12\x1e\n" +
+	"\x19ERROR_UNSUPPORTED_VERSION\x10\xa9\x1f\x12\x1a\n" +
+	"\x15ERROR_SESSION_TIMEOUT\x10\xaa\x1f\x12\x18\n" +
+	"\x13ERROR_NODE_DRAINING\x10\xab\x1f\x12\x1c\n" +
+	"\x17ERROR_SESSION_NOT_FOUND\x10\xac\x1f\x12\x1a\n" +
+	"\x15ERROR_RESUME_REQUIRED\x10\xad\x1fB&Z$github.com/Gopher0727/ChatRoom/protob\x06proto3"
 
 var (
 	file_internal_pkg_proto_chat_proto_rawDescOnce sync.Once
@@ -322,22 +1619,49 @@ func file_internal_pkg_proto_chat_proto_rawDescGZIP() []byte {
 	return file_internal_pkg_proto_chat_proto_rawDescData
 }
 
-var file_internal_pkg_proto_chat_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_internal_pkg_proto_chat_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_internal_pkg_proto_chat_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_internal_pkg_proto_chat_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_internal_pkg_proto_chat_proto_goTypes = []any{
 	(MessageType)(0),        // 0: chat.MessageType
-	(*WSMessage)(nil),       // 1: chat.WSMessage
-	(*HistoryRequest)(nil),  // 2: chat.HistoryRequest
-	(*HistoryResponse)(nil), // 3: chat.HistoryResponse
+	(GatewayOpcode)(0),      // 1: chat.GatewayOpcode
+	(GatewayErrorCode)(0),   // 2: chat.GatewayErrorCode
+	(*ReactionCount)(nil),   // 3: chat.ReactionCount
+	(*Attachment)(nil),      // 4: chat.Attachment
+	(*ResumePosition)(nil),  // 5: chat.ResumePosition
+	(*WSMessage)(nil),       // 6: chat.WSMessage
+	(*GatewayFrame)(nil),    // 7: chat.GatewayFrame
+	(*Hello)(nil),           // 8: chat.Hello
+	(*Identify)(nil),        // 9: chat.Identify
+	(*ReadyGuild)(nil),      // 10: chat.ReadyGuild
+	(*Ready)(nil),           // 11: chat.Ready
+	(*GatewayError)(nil),    // 12: chat.GatewayError
+	(*HistoryRequest)(nil),  // 13: chat.HistoryRequest
+	(*HistoryResponse)(nil), // 14: chat.HistoryResponse
+	(*ThreadRequest)(nil),   // 15: chat.ThreadRequest
+	(*ThreadResponse)(nil),  // 16: chat.ThreadResponse
 }
 var file_internal_pkg_proto_chat_proto_depIdxs = []int32{
-	0, // 0: chat.WSMessage.type:type_name -> chat.MessageType
-	1, // 1: chat.HistoryResponse.messages:type_name -> chat.WSMessage
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	0,  // 0: chat.WSMessage.type:type_name -> chat.MessageType
+	3,  // 1: chat.WSMessage.reactions:type_name -> chat.ReactionCount
+	4,  // 2: chat.WSMessage.attachments:type_name -> chat.Attachment
+	5,  // 3: chat.WSMessage.resume_positions:type_name -> chat.ResumePosition
+	1,  // 4: chat.GatewayFrame.op:type_name -> chat.GatewayOpcode
+	6,  // 5: chat.GatewayFrame.event:type_name -> chat.WSMessage
+	8,  // 6: chat.GatewayFrame.hello:type_name -> chat.Hello
+	9,  // 7: chat.GatewayFrame.identify:type_name -> chat.Identify
+	11, // 8: chat.GatewayFrame.ready:type_name -> chat.Ready
+	12, // 9: chat.GatewayFrame.error:type_name -> chat.GatewayError
+	5,  // 10: chat.Identify.resume_positions:type_name -> chat.ResumePosition
+	10, // 11: chat.Ready.guilds:type_name -> chat.ReadyGuild
+	2,  // 12: chat.GatewayError.code:type_name -> chat.GatewayErrorCode
+	6,  // 13: chat.HistoryResponse.messages:type_name -> chat.WSMessage
+	6,  // 14: chat.ThreadResponse.root:type_name -> chat.WSMessage
+	6,  // 15: chat.ThreadResponse.messages:type_name -> chat.WSMessage
+	16, // [16:16] is the sub-list for method output_type
+	16, // [16:16] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_internal_pkg_proto_chat_proto_init() }
@@ -350,8 +1674,8 @@ func file_internal_pkg_proto_chat_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_pkg_proto_chat_proto_rawDesc), len(file_internal_pkg_proto_chat_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   3,
+			NumEnums:      3,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -164,6 +164,33 @@ func TestMessageType_Enum(t *testing.T) {
 	if MessageType_SYSTEM != 1 {
 		t.Errorf("SYSTEM value mismatch: got %v, want 1", MessageType_SYSTEM)
 	}
+	if MessageType_MESSAGE_EDIT != 2 {
+		t.Errorf("MESSAGE_EDIT value mismatch: got %v, want 2", MessageType_MESSAGE_EDIT)
+	}
+	if MessageType_MESSAGE_DELETE != 3 {
+		t.Errorf("MESSAGE_DELETE value mismatch: got %v, want 3", MessageType_MESSAGE_DELETE)
+	}
+	if MessageType_REACTION_ADD != 4 {
+		t.Errorf("REACTION_ADD value mismatch: got %v, want 4", MessageType_REACTION_ADD)
+	}
+	if MessageType_REACTION_REMOVE != 5 {
+		t.Errorf("REACTION_REMOVE value mismatch: got %v, want 5", MessageType_REACTION_REMOVE)
+	}
+	if MessageType_ACK != 6 {
+		t.Errorf("ACK value mismatch: got %v, want 6", MessageType_ACK)
+	}
+	if MessageType_READ_STATE != 7 {
+		t.Errorf("READ_STATE value mismatch: got %v, want 7", MessageType_READ_STATE)
+	}
+	if MessageType_TYPING != 8 {
+		t.Errorf("TYPING value mismatch: got %v, want 8", MessageType_TYPING)
+	}
+	if MessageType_PRESENCE_UPDATE != 9 {
+		t.Errorf("PRESENCE_UPDATE value mismatch: got %v, want 9", MessageType_PRESENCE_UPDATE)
+	}
+	if MessageType_RESUME != 12 {
+		t.Errorf("RESUME value mismatch: got %v, want 12", MessageType_RESUME)
+	}
 }
 
 // genWSMessage generates random WSMessage instances for property testing
@@ -442,6 +469,294 @@ func TestWSMessage_SystemType(t *testing.T) {
 	}
 }
 
+// TestWSMessage_EditType tests MESSAGE_EDIT events carry the edit timestamp
+func TestWSMessage_EditType(t *testing.T) {
+	original := &WSMessage{
+		MessageId: "msg_123",
+		UserId:    "user_456",
+		GuildId:   "guild_789",
+		Content:   "Hello, edited!",
+		SeqId:     100,
+		Timestamp: 1234567890,
+		Type:      MessageType_MESSAGE_EDIT,
+		EditedAt:  1234567999,
+	}
+
+	// Marshal
+	data, err := proto.Marshal(original)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	// Unmarshal
+	decoded := &WSMessage{}
+	err = proto.Unmarshal(data, decoded)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	if decoded.Type != MessageType_MESSAGE_EDIT {
+		t.Errorf("Type should be MESSAGE_EDIT, got %v", decoded.Type)
+	}
+	if decoded.EditedAt != original.EditedAt {
+		t.Errorf("EditedAt mismatch: got %v, want %v", decoded.EditedAt, original.EditedAt)
+	}
+}
+
+// TestThreadResponse_MarshalUnmarshal tests thread pages keep root, replies and cursor
+func TestThreadResponse_MarshalUnmarshal(t *testing.T) {
+	original := &ThreadResponse{
+		Root: &WSMessage{
+			MessageId: "root_1",
+			GuildId:   "guild_789",
+			Content:   "Root message",
+			SeqId:     10,
+		},
+		Messages: []*WSMessage{
+			{MessageId: "reply_1", ReplyToId: "root_1", ThreadRootId: "root_1", SeqId: 11},
+			{MessageId: "reply_2", ReplyToId: "reply_1", ThreadRootId: "root_1", SeqId: 15},
+		},
+		HasMore:   true,
+		NextSeqId: 15,
+	}
+
+	data, err := proto.Marshal(original)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	decoded := &ThreadResponse{}
+	if err := proto.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	if !proto.Equal(original, decoded) {
+		t.Errorf("ThreadResponse mismatch: got %v, want %v", decoded, original)
+	}
+	if decoded.Messages[1].ReplyToId != "reply_1" || decoded.Messages[1].ThreadRootId != "root_1" {
+		t.Errorf("Reply references not preserved: %v", decoded.Messages[1])
+	}
+}
+
+// TestWSMessage_Reactions tests reaction events and aggregated counts survive a round trip
+func TestWSMessage_Reactions(t *testing.T) {
+	original := &WSMessage{
+		MessageId: "msg_123",
+		UserId:    "user_456",
+		GuildId:   "guild_789",
+		Type:      MessageType_REACTION_ADD,
+		Emoji:     "👍",
+		Reactions: []*ReactionCount{
+			{Emoji: "👍", Count: 3},
+			{Emoji: "party_parrot", Count: 1},
+		},
+	}
+
+	data, err := proto.Marshal(original)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	decoded := &WSMessage{}
+	if err := proto.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	if !proto.Equal(original, decoded) {
+		t.Errorf("WSMessage mismatch: got %v, want %v", decoded, original)
+	}
+}
+
+// TestHistoryRequest_ChannelFilter tests that the channel filter survives a round trip
+func TestHistoryRequest_ChannelFilter(t *testing.T) {
+	original := &HistoryRequest{
+		GuildId:   "guild_789",
+		ChannelId: "channel_42",
+		LastSeqId: 7,
+		Limit:     20,
+	}
+
+	data, err := proto.Marshal(original)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	decoded := &HistoryRequest{}
+	if err := proto.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	if decoded.ChannelId != original.ChannelId {
+		t.Errorf("ChannelId mismatch: got %v, want %v", decoded.ChannelId, original.ChannelId)
+	}
+	if decoded.GuildId != original.GuildId {
+		t.Errorf("GuildId mismatch: got %v, want %v", decoded.GuildId, original.GuildId)
+	}
+}
+
+// TestHistoryCursors tests that the seq_id cursors and both has_more flags survive a round trip
+func TestHistoryCursors(t *testing.T) {
+	request := &HistoryRequest{
+		GuildId:     "guild_123",
+		AroundSeqId: 42,
+		Limit:       20,
+	}
+	data, err := proto.Marshal(request)
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+	decodedRequest := &HistoryRequest{}
+	if err := proto.Unmarshal(data, decodedRequest); err != nil {
+		t.Fatalf("Failed to unmarshal request: %v", err)
+	}
+	if !proto.Equal(request, decodedRequest) {
+		t.Errorf("HistoryRequest mismatch: got %v, want %v", decodedRequest, request)
+	}
+	if decodedRequest.BeforeSeqId != 0 || decodedRequest.AfterSeqId != 0 || decodedRequest.LastSeqId != 0 {
+		t.Errorf("Unset cursors should be zero, got %v", decodedRequest)
+	}
+
+	response := &HistoryResponse{
+		Messages: []*WSMessage{
+			{MessageId: "msg_41", SeqId: 41},
+			{MessageId: "msg_42", SeqId: 42},
+		},
+		HasMore:       true,
+		HasMoreBefore: true,
+		HasMoreAfter:  false,
+	}
+	data, err = proto.Marshal(response)
+	if err != nil {
+		t.Fatalf("Failed to marshal response: %v", err)
+	}
+	decodedResponse := &HistoryResponse{}
+	if err := proto.Unmarshal(data, decodedResponse); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if !proto.Equal(response, decodedResponse) {
+		t.Errorf("HistoryResponse mismatch: got %v, want %v", decodedResponse, response)
+	}
+}
+
+// TestWSMessage_DirectMessage tests that direct message routing fields survive a round trip
+func TestWSMessage_DirectMessage(t *testing.T) {
+	original := &WSMessage{
+		MessageId:      "msg_123",
+		UserId:         "user_456",
+		Content:        "Hello, DM!",
+		SeqId:          3,
+		Type:           MessageType_TEXT,
+		ConversationId: "dm_1",
+		RecipientIds:   []string{"user_456", "user_789"},
+	}
+
+	data, err := proto.Marshal(original)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	decoded := &WSMessage{}
+	if err := proto.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	if !proto.Equal(original, decoded) {
+		t.Errorf("WSMessage mismatch: got %v, want %v", decoded, original)
+	}
+	if decoded.GuildId != "" {
+		t.Errorf("GuildId should be empty for direct messages, got %v", decoded.GuildId)
+	}
+}
+
+// TestWSMessage_Typing tests that a typing indicator carries its TTL and no seq ID
+func TestWSMessage_Typing(t *testing.T) {
+	original := &WSMessage{
+		UserId:    "user_456",
+		GuildId:   "guild_789",
+		ChannelId: "channel_1",
+		Timestamp: 1_700_000_000_000,
+		Type:      MessageType_TYPING,
+		TtlMs:     8000,
+	}
+
+	data, err := proto.Marshal(original)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	decoded := &WSMessage{}
+	if err := proto.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	if !proto.Equal(original, decoded) {
+		t.Errorf("WSMessage mismatch: got %v, want %v", decoded, original)
+	}
+	if decoded.SeqId != 0 {
+		t.Errorf("SeqId should be empty for typing indicators, got %v", decoded.SeqId)
+	}
+}
+
+// TestWSMessage_Resume tests that the positions of a resume frame survive a round trip
+func TestWSMessage_Resume(t *testing.T) {
+	original := &WSMessage{
+		Type: MessageType_RESUME,
+		ResumePositions: []*ResumePosition{
+			{GuildId: "guild_789", SeqId: 42},
+			{GuildId: "guild_789", ChannelId: "channel_1", SeqId: 7, HasMore: true},
+		},
+	}
+
+	data, err := proto.Marshal(original)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	decoded := &WSMessage{}
+	if err := proto.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	if !proto.Equal(original, decoded) {
+		t.Errorf("WSMessage mismatch: got %v, want %v", decoded, original)
+	}
+	if len(decoded.ResumePositions) != 2 {
+		t.Fatalf("ResumePositions length mismatch: got %d, want 2", len(decoded.ResumePositions))
+	}
+}
+
+// TestWSMessage_Attachments tests that attachment descriptors survive a round trip
+func TestWSMessage_Attachments(t *testing.T) {
+	original := &WSMessage{
+		MessageId: "msg_123",
+		UserId:    "user_456",
+		GuildId:   "guild_789",
+		SeqId:     4,
+		Type:      MessageType_TEXT,
+		Attachments: []*Attachment{
+			{Id: "att_1", Filename: "cat.png", ContentType: "image/png", Size: 2048},
+			{Id: "att_2", Filename: "notes.txt", ContentType: "text/plain", Size: 12},
+		},
+	}
+
+	data, err := proto.Marshal(original)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	decoded := &WSMessage{}
+	if err := proto.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	if !proto.Equal(original, decoded) {
+		t.Errorf("WSMessage mismatch: got %v, want %v", decoded, original)
+	}
+	if decoded.Content != "" {
+		t.Errorf("Content should be empty for an attachment-only message, got %v", decoded.Content)
+	}
+}
+
 // TestHistoryRequest_EmptyFields tests that empty fields are handled correctly
 func TestHistoryRequest_EmptyFields(t *testing.T) {
 	original := &HistoryRequest{
@@ -125,6 +125,110 @@ func (x *PushMessageResponse) GetError() string {
 	return ""
 }
 
+type PushToUsersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserIds       []string               `protobuf:"bytes,1,rep,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"`
+	Message       *WSMessage             `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PushToUsersRequest) Reset() {
+	*x = PushToUsersRequest{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushToUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushToUsersRequest) ProtoMessage() {}
+
+func (x *PushToUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushToUsersRequest.ProtoReflect.Descriptor instead.
+func (*PushToUsersRequest) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PushToUsersRequest) GetUserIds() []string {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+func (x *PushToUsersRequest) GetMessage() *WSMessage {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+type PushToUsersResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	DeliveredCount     int32                  `protobuf:"varint,1,opt,name=delivered_count,json=deliveredCount,proto3" json:"delivered_count,omitempty"`              // 至少一个会话收到消息的用户数
+	UndeliveredUserIds []string               `protobuf:"bytes,2,rep,name=undelivered_user_ids,json=undeliveredUserIds,proto3" json:"undelivered_user_ids,omitempty"` // 未送达的用户（不在本节点或发送队列已满）
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *PushToUsersResponse) Reset() {
+	*x = PushToUsersResponse{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushToUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushToUsersResponse) ProtoMessage() {}
+
+func (x *PushToUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushToUsersResponse.ProtoReflect.Descriptor instead.
+func (*PushToUsersResponse) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PushToUsersResponse) GetDeliveredCount() int32 {
+	if x != nil {
+		return x.DeliveredCount
+	}
+	return 0
+}
+
+func (x *PushToUsersResponse) GetUndeliveredUserIds() []string {
+	if x != nil {
+		return x.UndeliveredUserIds
+	}
+	return nil
+}
+
 type BroadcastRequest struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	GuildId        string                 `protobuf:"bytes,1,opt,name=guild_id,json=guildId,proto3" json:"guild_id,omitempty"`
@@ -136,7 +240,7 @@ type BroadcastRequest struct {
 
 func (x *BroadcastRequest) Reset() {
 	*x = BroadcastRequest{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[2]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -148,7 +252,7 @@ func (x *BroadcastRequest) String() string {
 func (*BroadcastRequest) ProtoMessage() {}
 
 func (x *BroadcastRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[2]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -161,7 +265,7 @@ func (x *BroadcastRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BroadcastRequest.ProtoReflect.Descriptor instead.
 func (*BroadcastRequest) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{2}
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *BroadcastRequest) GetGuildId() string {
@@ -195,7 +299,7 @@ type BroadcastResponse struct {
 
 func (x *BroadcastResponse) Reset() {
 	*x = BroadcastResponse{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[3]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -207,7 +311,7 @@ func (x *BroadcastResponse) String() string {
 func (*BroadcastResponse) ProtoMessage() {}
 
 func (x *BroadcastResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[3]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -220,7 +324,7 @@ func (x *BroadcastResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BroadcastResponse.ProtoReflect.Descriptor instead.
 func (*BroadcastResponse) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{3}
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *BroadcastResponse) GetDeliveredCount() int32 {
@@ -246,7 +350,7 @@ type UserStatusRequest struct {
 
 func (x *UserStatusRequest) Reset() {
 	*x = UserStatusRequest{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[4]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -258,7 +362,7 @@ func (x *UserStatusRequest) String() string {
 func (*UserStatusRequest) ProtoMessage() {}
 
 func (x *UserStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[4]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -271,7 +375,7 @@ func (x *UserStatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UserStatusRequest.ProtoReflect.Descriptor instead.
 func (*UserStatusRequest) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{4}
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *UserStatusRequest) GetUserId() string {
@@ -291,7 +395,7 @@ type UserStatusResponse struct {
 
 func (x *UserStatusResponse) Reset() {
 	*x = UserStatusResponse{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[5]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -303,7 +407,7 @@ func (x *UserStatusResponse) String() string {
 func (*UserStatusResponse) ProtoMessage() {}
 
 func (x *UserStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[5]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -316,7 +420,7 @@ func (x *UserStatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UserStatusResponse.ProtoReflect.Descriptor instead.
 func (*UserStatusResponse) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{5}
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *UserStatusResponse) GetOnline() bool {
@@ -341,7 +445,7 @@ type NodeInfoRequest struct {
 
 func (x *NodeInfoRequest) Reset() {
 	*x = NodeInfoRequest{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[6]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -353,7 +457,7 @@ func (x *NodeInfoRequest) String() string {
 func (*NodeInfoRequest) ProtoMessage() {}
 
 func (x *NodeInfoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[6]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -366,22 +470,24 @@ func (x *NodeInfoRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodeInfoRequest.ProtoReflect.Descriptor instead.
 func (*NodeInfoRequest) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{6}
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{8}
 }
 
 type NodeInfoResponse struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	NodeId          string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
-	Address         string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
-	ConnectionCount int32                  `protobuf:"varint,3,opt,name=connection_count,json=connectionCount,proto3" json:"connection_count,omitempty"`
-	UptimeSeconds   int64                  `protobuf:"varint,4,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	NodeId                  string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Address                 string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	ConnectionCount         int32                  `protobuf:"varint,3,opt,name=connection_count,json=connectionCount,proto3" json:"connection_count,omitempty"`
+	UptimeSeconds           int64                  `protobuf:"varint,4,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
+	DroppedFrames           uint64                 `protobuf:"varint,5,opt,name=dropped_frames,json=droppedFrames,proto3" json:"dropped_frames,omitempty"`                                 // 发送队列已满而丢弃的帧数
+	SlowConsumerDisconnects uint64                 `protobuf:"varint,6,opt,name=slow_consumer_disconnects,json=slowConsumerDisconnects,proto3" json:"slow_consumer_disconnects,omitempty"` // 因发送队列已满而断开的连接数
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
 }
 
 func (x *NodeInfoResponse) Reset() {
 	*x = NodeInfoResponse{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[7]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -393,7 +499,7 @@ func (x *NodeInfoResponse) String() string {
 func (*NodeInfoResponse) ProtoMessage() {}
 
 func (x *NodeInfoResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[7]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -406,7 +512,7 @@ func (x *NodeInfoResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodeInfoResponse.ProtoReflect.Descriptor instead.
 func (*NodeInfoResponse) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{7}
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *NodeInfoResponse) GetNodeId() string {
@@ -416,48 +522,884 @@ func (x *NodeInfoResponse) GetNodeId() string {
 	return ""
 }
 
-func (x *NodeInfoResponse) GetAddress() string {
-	if x != nil {
-		return x.Address
-	}
-	return ""
+func (x *NodeInfoResponse) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *NodeInfoResponse) GetConnectionCount() int32 {
+	if x != nil {
+		return x.ConnectionCount
+	}
+	return 0
+}
+
+func (x *NodeInfoResponse) GetUptimeSeconds() int64 {
+	if x != nil {
+		return x.UptimeSeconds
+	}
+	return 0
+}
+
+func (x *NodeInfoResponse) GetDroppedFrames() uint64 {
+	if x != nil {
+		return x.DroppedFrames
+	}
+	return 0
+}
+
+func (x *NodeInfoResponse) GetSlowConsumerDisconnects() uint64 {
+	if x != nil {
+		return x.SlowConsumerDisconnects
+	}
+	return 0
+}
+
+type DrainRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WindowSeconds int32                  `protobuf:"varint,1,opt,name=window_seconds,json=windowSeconds,proto3" json:"window_seconds,omitempty"` // 分批断开连接的时间窗口，0 使用配置值
+	Wait          bool                   `protobuf:"varint,2,opt,name=wait,proto3" json:"wait,omitempty"`                                        // 是否等待排空完成后再返回
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DrainRequest) Reset() {
+	*x = DrainRequest{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DrainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrainRequest) ProtoMessage() {}
+
+func (x *DrainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrainRequest.ProtoReflect.Descriptor instead.
+func (*DrainRequest) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DrainRequest) GetWindowSeconds() int32 {
+	if x != nil {
+		return x.WindowSeconds
+	}
+	return 0
+}
+
+func (x *DrainRequest) GetWait() bool {
+	if x != nil {
+		return x.Wait
+	}
+	return false
+}
+
+type DrainResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Drained         bool                   `protobuf:"varint,1,opt,name=drained,proto3" json:"drained,omitempty"`                                        // 排空是否已完成
+	ConnectionCount int32                  `protobuf:"varint,2,opt,name=connection_count,json=connectionCount,proto3" json:"connection_count,omitempty"` // 节点上剩余的连接数
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DrainResponse) Reset() {
+	*x = DrainResponse{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DrainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrainResponse) ProtoMessage() {}
+
+func (x *DrainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrainResponse.ProtoReflect.Descriptor instead.
+func (*DrainResponse) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DrainResponse) GetDrained() bool {
+	if x != nil {
+		return x.Drained
+	}
+	return false
+}
+
+func (x *DrainResponse) GetConnectionCount() int32 {
+	if x != nil {
+		return x.ConnectionCount
+	}
+	return 0
+}
+
+type SubscribeGuildsRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	GuildIds        []string               `protobuf:"bytes,1,rep,name=guild_ids,json=guildIds,proto3" json:"guild_ids,omitempty"`                      // 订阅的 Guild
+	ResumePositions []*ResumePosition      `protobuf:"bytes,2,rep,name=resume_positions,json=resumePositions,proto3" json:"resume_positions,omitempty"` // 各时间线最后收到的位置，补发完成后以 RESUME 事件通知
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SubscribeGuildsRequest) Reset() {
+	*x = SubscribeGuildsRequest{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeGuildsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeGuildsRequest) ProtoMessage() {}
+
+func (x *SubscribeGuildsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeGuildsRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeGuildsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SubscribeGuildsRequest) GetGuildIds() []string {
+	if x != nil {
+		return x.GuildIds
+	}
+	return nil
+}
+
+func (x *SubscribeGuildsRequest) GetResumePositions() []*ResumePosition {
+	if x != nil {
+		return x.ResumePositions
+	}
+	return nil
+}
+
+type HealthCheckRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthCheckRequest) Reset() {
+	*x = HealthCheckRequest{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthCheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheckRequest) ProtoMessage() {}
+
+func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
+func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{13}
+}
+
+type HealthCheckResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Healthy       bool                   `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthCheckResponse) Reset() {
+	*x = HealthCheckResponse{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthCheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheckResponse) ProtoMessage() {}
+
+func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
+func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *HealthCheckResponse) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *HealthCheckResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type SendMessageRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	UserId         string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	GuildId        string                 `protobuf:"bytes,2,opt,name=guild_id,json=guildId,proto3" json:"guild_id,omitempty"`
+	Content        string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	Type           MessageType            `protobuf:"varint,4,opt,name=type,proto3,enum=chat.MessageType" json:"type,omitempty"`
+	ReplyToId      string                 `protobuf:"bytes,5,opt,name=reply_to_id,json=replyToId,proto3" json:"reply_to_id,omitempty"`              // 回复的消息 ID (可选)
+	ChannelId      string                 `protobuf:"bytes,6,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`                // 频道 ID (可选)，空表示 Guild 默认时间线
+	ConversationId string                 `protobuf:"bytes,7,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // 私信会话 ID (可选)，设置时不填 guild_id
+	AttachmentIds  []string               `protobuf:"bytes,8,rep,name=attachment_ids,json=attachmentIds,proto3" json:"attachment_ids,omitempty"`    // 已上传的附件 ID (可选)，有附件时 content 可以为空
+	Nonce          string                 `protobuf:"bytes,9,opt,name=nonce,proto3" json:"nonce,omitempty"`                                         // 客户端幂等键 (可选)，重复发送时返回原消息
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SendMessageRequest) Reset() {
+	*x = SendMessageRequest{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendMessageRequest) ProtoMessage() {}
+
+func (x *SendMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendMessageRequest.ProtoReflect.Descriptor instead.
+func (*SendMessageRequest) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SendMessageRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetGuildId() string {
+	if x != nil {
+		return x.GuildId
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetType() MessageType {
+	if x != nil {
+		return x.Type
+	}
+	return MessageType_TEXT
+}
+
+func (x *SendMessageRequest) GetReplyToId() string {
+	if x != nil {
+		return x.ReplyToId
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetAttachmentIds() []string {
+	if x != nil {
+		return x.AttachmentIds
+	}
+	return nil
+}
+
+func (x *SendMessageRequest) GetNonce() string {
+	if x != nil {
+		return x.Nonce
+	}
+	return ""
+}
+
+type SendMessageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       *WSMessage             `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendMessageResponse) Reset() {
+	*x = SendMessageResponse{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendMessageResponse) ProtoMessage() {}
+
+func (x *SendMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendMessageResponse.ProtoReflect.Descriptor instead.
+func (*SendMessageResponse) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SendMessageResponse) GetMessage() *WSMessage {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *SendMessageResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type BatchGetMessagesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageIds    []string               `protobuf:"bytes,1,rep,name=message_ids,json=messageIds,proto3" json:"message_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetMessagesRequest) Reset() {
+	*x = BatchGetMessagesRequest{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetMessagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetMessagesRequest) ProtoMessage() {}
+
+func (x *BatchGetMessagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetMessagesRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *BatchGetMessagesRequest) GetMessageIds() []string {
+	if x != nil {
+		return x.MessageIds
+	}
+	return nil
+}
+
+type BatchGetMessagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Messages      []*WSMessage           `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetMessagesResponse) Reset() {
+	*x = BatchGetMessagesResponse{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetMessagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetMessagesResponse) ProtoMessage() {}
+
+func (x *BatchGetMessagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetMessagesResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetMessagesResponse) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *BatchGetMessagesResponse) GetMessages() []*WSMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+type EditMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	MessageId     string                 `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EditMessageRequest) Reset() {
+	*x = EditMessageRequest{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EditMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditMessageRequest) ProtoMessage() {}
+
+func (x *EditMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditMessageRequest.ProtoReflect.Descriptor instead.
+func (*EditMessageRequest) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *EditMessageRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *EditMessageRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *EditMessageRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+type EditMessageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       *WSMessage             `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EditMessageResponse) Reset() {
+	*x = EditMessageResponse{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EditMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditMessageResponse) ProtoMessage() {}
+
+func (x *EditMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditMessageResponse.ProtoReflect.Descriptor instead.
+func (*EditMessageResponse) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *EditMessageResponse) GetMessage() *WSMessage {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *EditMessageResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type DeleteMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	MessageId     string                 `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMessageRequest) Reset() {
+	*x = DeleteMessageRequest{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMessageRequest) ProtoMessage() {}
+
+func (x *DeleteMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMessageRequest.ProtoReflect.Descriptor instead.
+func (*DeleteMessageRequest) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DeleteMessageRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *DeleteMessageRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+type DeleteMessageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMessageResponse) Reset() {
+	*x = DeleteMessageResponse{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMessageResponse) ProtoMessage() {}
+
+func (x *DeleteMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMessageResponse.ProtoReflect.Descriptor instead.
+func (*DeleteMessageResponse) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *DeleteMessageResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DeleteMessageResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ReactionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	MessageId     string                 `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Emoji         string                 `protobuf:"bytes,3,opt,name=emoji,proto3" json:"emoji,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReactionRequest) Reset() {
+	*x = ReactionRequest{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReactionRequest) ProtoMessage() {}
+
+func (x *ReactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReactionRequest.ProtoReflect.Descriptor instead.
+func (*ReactionRequest) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ReactionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ReactionRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *ReactionRequest) GetEmoji() string {
+	if x != nil {
+		return x.Emoji
+	}
+	return ""
+}
+
+type ReactionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReactionResponse) Reset() {
+	*x = ReactionResponse{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReactionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReactionResponse) ProtoMessage() {}
+
+func (x *ReactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReactionResponse.ProtoReflect.Descriptor instead.
+func (*ReactionResponse) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *NodeInfoResponse) GetConnectionCount() int32 {
+func (x *ReactionResponse) GetSuccess() bool {
 	if x != nil {
-		return x.ConnectionCount
+		return x.Success
 	}
-	return 0
+	return false
 }
 
-func (x *NodeInfoResponse) GetUptimeSeconds() int64 {
+func (x *ReactionResponse) GetError() string {
 	if x != nil {
-		return x.UptimeSeconds
+		return x.Error
 	}
-	return 0
+	return ""
 }
 
-type HealthCheckRequest struct {
+type PinnedMessagesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	GuildId       string                 `protobuf:"bytes,2,opt,name=guild_id,json=guildId,proto3" json:"guild_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthCheckRequest) Reset() {
-	*x = HealthCheckRequest{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[8]
+func (x *PinnedMessagesRequest) Reset() {
+	*x = PinnedMessagesRequest{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthCheckRequest) String() string {
+func (x *PinnedMessagesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthCheckRequest) ProtoMessage() {}
+func (*PinnedMessagesRequest) ProtoMessage() {}
 
-func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[8]
+func (x *PinnedMessagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -468,34 +1410,47 @@ func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
-func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use PinnedMessagesRequest.ProtoReflect.Descriptor instead.
+func (*PinnedMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{25}
 }
 
-type HealthCheckResponse struct {
+func (x *PinnedMessagesRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *PinnedMessagesRequest) GetGuildId() string {
+	if x != nil {
+		return x.GuildId
+	}
+	return ""
+}
+
+type PinnedMessagesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Healthy       bool                   `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
-	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Messages      []*WSMessage           `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"` // 按置顶时间倒序
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthCheckResponse) Reset() {
-	*x = HealthCheckResponse{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[9]
+func (x *PinnedMessagesResponse) Reset() {
+	*x = PinnedMessagesResponse{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthCheckResponse) String() string {
+func (x *PinnedMessagesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthCheckResponse) ProtoMessage() {}
+func (*PinnedMessagesResponse) ProtoMessage() {}
 
-func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[9]
+func (x *PinnedMessagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -506,50 +1461,49 @@ func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
-func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{9}
-}
-
-func (x *HealthCheckResponse) GetHealthy() bool {
-	if x != nil {
-		return x.Healthy
-	}
-	return false
+// Deprecated: Use PinnedMessagesResponse.ProtoReflect.Descriptor instead.
+func (*PinnedMessagesResponse) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *HealthCheckResponse) GetStatus() string {
+func (x *PinnedMessagesResponse) GetMessages() []*WSMessage {
 	if x != nil {
-		return x.Status
+		return x.Messages
 	}
-	return ""
+	return nil
 }
 
-type SendMessageRequest struct {
+type SearchMessagesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	GuildId       string                 `protobuf:"bytes,2,opt,name=guild_id,json=guildId,proto3" json:"guild_id,omitempty"`
-	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
-	Type          MessageType            `protobuf:"varint,4,opt,name=type,proto3,enum=chat.MessageType" json:"type,omitempty"`
+	Query         string                 `protobuf:"bytes,3,opt,name=query,proto3" json:"query,omitempty"`                                       // 支持 websearch 语法："短语"、or、-排除
+	ChannelId     string                 `protobuf:"bytes,4,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`              // 频道 ID (可选)，空表示检索所有频道
+	AuthorId      string                 `protobuf:"bytes,5,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`                 // 作者 ID (可选)
+	After         int64                  `protobuf:"varint,6,opt,name=after,proto3" json:"after,omitempty"`                                      // 起始时间 (毫秒，可选)
+	Before        int64                  `protobuf:"varint,7,opt,name=before,proto3" json:"before,omitempty"`                                    // 截止时间 (毫秒，可选)
+	HasAttachment bool                   `protobuf:"varint,8,opt,name=has_attachment,json=hasAttachment,proto3" json:"has_attachment,omitempty"` // 仅检索带附件的消息
+	Offset        int32                  `protobuf:"varint,9,opt,name=offset,proto3" json:"offset,omitempty"`
+	Limit         int32                  `protobuf:"varint,10,opt,name=limit,proto3" json:"limit,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SendMessageRequest) Reset() {
-	*x = SendMessageRequest{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[10]
+func (x *SearchMessagesRequest) Reset() {
+	*x = SearchMessagesRequest{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SendMessageRequest) String() string {
+func (x *SearchMessagesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SendMessageRequest) ProtoMessage() {}
+func (*SearchMessagesRequest) ProtoMessage() {}
 
-func (x *SendMessageRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[10]
+func (x *SearchMessagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -560,113 +1514,105 @@ func (x *SendMessageRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SendMessageRequest.ProtoReflect.Descriptor instead.
-func (*SendMessageRequest) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use SearchMessagesRequest.ProtoReflect.Descriptor instead.
+func (*SearchMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{27}
 }
 
-func (x *SendMessageRequest) GetUserId() string {
+func (x *SearchMessagesRequest) GetUserId() string {
 	if x != nil {
 		return x.UserId
 	}
 	return ""
 }
 
-func (x *SendMessageRequest) GetGuildId() string {
+func (x *SearchMessagesRequest) GetGuildId() string {
 	if x != nil {
 		return x.GuildId
 	}
 	return ""
 }
 
-func (x *SendMessageRequest) GetContent() string {
+func (x *SearchMessagesRequest) GetQuery() string {
 	if x != nil {
-		return x.Content
+		return x.Query
 	}
 	return ""
 }
 
-func (x *SendMessageRequest) GetType() MessageType {
+func (x *SearchMessagesRequest) GetChannelId() string {
 	if x != nil {
-		return x.Type
+		return x.ChannelId
 	}
-	return MessageType_TEXT
-}
-
-type SendMessageResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Message       *WSMessage             `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	return ""
 }
 
-func (x *SendMessageResponse) Reset() {
-	*x = SendMessageResponse{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[11]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *SearchMessagesRequest) GetAuthorId() string {
+	if x != nil {
+		return x.AuthorId
+	}
+	return ""
 }
 
-func (x *SendMessageResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *SearchMessagesRequest) GetAfter() int64 {
+	if x != nil {
+		return x.After
+	}
+	return 0
 }
 
-func (*SendMessageResponse) ProtoMessage() {}
-
-func (x *SendMessageResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[11]
+func (x *SearchMessagesRequest) GetBefore() int64 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Before
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use SendMessageResponse.ProtoReflect.Descriptor instead.
-func (*SendMessageResponse) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{11}
+func (x *SearchMessagesRequest) GetHasAttachment() bool {
+	if x != nil {
+		return x.HasAttachment
+	}
+	return false
 }
 
-func (x *SendMessageResponse) GetMessage() *WSMessage {
+func (x *SearchMessagesRequest) GetOffset() int32 {
 	if x != nil {
-		return x.Message
+		return x.Offset
 	}
-	return nil
+	return 0
 }
 
-func (x *SendMessageResponse) GetError() string {
+func (x *SearchMessagesRequest) GetLimit() int32 {
 	if x != nil {
-		return x.Error
+		return x.Limit
 	}
-	return ""
+	return 0
 }
 
-type BatchGetMessagesRequest struct {
+type SearchResult struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	MessageIds    []string               `protobuf:"bytes,1,rep,name=message_ids,json=messageIds,proto3" json:"message_ids,omitempty"`
+	Message       *WSMessage             `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Rank          float64                `protobuf:"fixed64,2,opt,name=rank,proto3" json:"rank,omitempty"`     // 相关度得分
+	Snippet       string                 `protobuf:"bytes,3,opt,name=snippet,proto3" json:"snippet,omitempty"` // 已做 HTML 转义，命中词以 <mark> 标记
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *BatchGetMessagesRequest) Reset() {
-	*x = BatchGetMessagesRequest{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[12]
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BatchGetMessagesRequest) String() string {
+func (x *SearchResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BatchGetMessagesRequest) ProtoMessage() {}
+func (*SearchResult) ProtoMessage() {}
 
-func (x *BatchGetMessagesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[12]
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -677,40 +1623,56 @@ func (x *BatchGetMessagesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BatchGetMessagesRequest.ProtoReflect.Descriptor instead.
-func (*BatchGetMessagesRequest) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{28}
 }
 
-func (x *BatchGetMessagesRequest) GetMessageIds() []string {
+func (x *SearchResult) GetMessage() *WSMessage {
 	if x != nil {
-		return x.MessageIds
+		return x.Message
 	}
 	return nil
 }
 
-type BatchGetMessagesResponse struct {
+func (x *SearchResult) GetRank() float64 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *SearchResult) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
+}
+
+type SearchMessagesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Messages      []*WSMessage           `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	Results       []*SearchResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"` // 按相关度倒序
+	HasMore       bool                   `protobuf:"varint,2,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	NextOffset    int32                  `protobuf:"varint,3,opt,name=next_offset,json=nextOffset,proto3" json:"next_offset,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *BatchGetMessagesResponse) Reset() {
-	*x = BatchGetMessagesResponse{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[13]
+func (x *SearchMessagesResponse) Reset() {
+	*x = SearchMessagesResponse{}
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BatchGetMessagesResponse) String() string {
+func (x *SearchMessagesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BatchGetMessagesResponse) ProtoMessage() {}
+func (*SearchMessagesResponse) ProtoMessage() {}
 
-func (x *BatchGetMessagesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[13]
+func (x *SearchMessagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -721,18 +1683,32 @@ func (x *BatchGetMessagesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BatchGetMessagesResponse.ProtoReflect.Descriptor instead.
-func (*BatchGetMessagesResponse) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use SearchMessagesResponse.ProtoReflect.Descriptor instead.
+func (*SearchMessagesResponse) Descriptor() ([]byte, []int) {
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{29}
 }
 
-func (x *BatchGetMessagesResponse) GetMessages() []*WSMessage {
+func (x *SearchMessagesResponse) GetResults() []*SearchResult {
 	if x != nil {
-		return x.Messages
+		return x.Results
 	}
 	return nil
 }
 
+func (x *SearchMessagesResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+func (x *SearchMessagesResponse) GetNextOffset() int32 {
+	if x != nil {
+		return x.NextOffset
+	}
+	return 0
+}
+
 type GetUserRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
@@ -742,7 +1718,7 @@ type GetUserRequest struct {
 
 func (x *GetUserRequest) Reset() {
 	*x = GetUserRequest{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[14]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -754,7 +1730,7 @@ func (x *GetUserRequest) String() string {
 func (*GetUserRequest) ProtoMessage() {}
 
 func (x *GetUserRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[14]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -767,7 +1743,7 @@ func (x *GetUserRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetUserRequest.ProtoReflect.Descriptor instead.
 func (*GetUserRequest) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{14}
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *GetUserRequest) GetUserId() string {
@@ -789,7 +1765,7 @@ type GetUserResponse struct {
 
 func (x *GetUserResponse) Reset() {
 	*x = GetUserResponse{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[15]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -801,7 +1777,7 @@ func (x *GetUserResponse) String() string {
 func (*GetUserResponse) ProtoMessage() {}
 
 func (x *GetUserResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[15]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -814,7 +1790,7 @@ func (x *GetUserResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetUserResponse.ProtoReflect.Descriptor instead.
 func (*GetUserResponse) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{15}
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *GetUserResponse) GetUserId() string {
@@ -854,7 +1830,7 @@ type BatchGetUsersRequest struct {
 
 func (x *BatchGetUsersRequest) Reset() {
 	*x = BatchGetUsersRequest{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[16]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -866,7 +1842,7 @@ func (x *BatchGetUsersRequest) String() string {
 func (*BatchGetUsersRequest) ProtoMessage() {}
 
 func (x *BatchGetUsersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[16]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -879,7 +1855,7 @@ func (x *BatchGetUsersRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BatchGetUsersRequest.ProtoReflect.Descriptor instead.
 func (*BatchGetUsersRequest) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{16}
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *BatchGetUsersRequest) GetUserIds() []string {
@@ -898,7 +1874,7 @@ type BatchGetUsersResponse struct {
 
 func (x *BatchGetUsersResponse) Reset() {
 	*x = BatchGetUsersResponse{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[17]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -910,7 +1886,7 @@ func (x *BatchGetUsersResponse) String() string {
 func (*BatchGetUsersResponse) ProtoMessage() {}
 
 func (x *BatchGetUsersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[17]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -923,7 +1899,7 @@ func (x *BatchGetUsersResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BatchGetUsersResponse.ProtoReflect.Descriptor instead.
 func (*BatchGetUsersResponse) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{17}
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *BatchGetUsersResponse) GetUsers() []*GetUserResponse {
@@ -933,18 +1909,21 @@ func (x *BatchGetUsersResponse) GetUsers() []*GetUserResponse {
 	return nil
 }
 
+// status 非空时设置用户选择的状态，否则按 online 上报连接建立或断开
 type UpdateUserStatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	Online        bool                   `protobuf:"varint,2,opt,name=online,proto3" json:"online,omitempty"`
 	GatewayNode   string                 `protobuf:"bytes,3,opt,name=gateway_node,json=gatewayNode,proto3" json:"gateway_node,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"` // online, idle, dnd, invisible
+	CustomStatus  string                 `protobuf:"bytes,5,opt,name=custom_status,json=customStatus,proto3" json:"custom_status,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UpdateUserStatusRequest) Reset() {
 	*x = UpdateUserStatusRequest{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[18]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -956,7 +1935,7 @@ func (x *UpdateUserStatusRequest) String() string {
 func (*UpdateUserStatusRequest) ProtoMessage() {}
 
 func (x *UpdateUserStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[18]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -969,7 +1948,7 @@ func (x *UpdateUserStatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateUserStatusRequest.ProtoReflect.Descriptor instead.
 func (*UpdateUserStatusRequest) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{18}
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *UpdateUserStatusRequest) GetUserId() string {
@@ -993,16 +1972,32 @@ func (x *UpdateUserStatusRequest) GetGatewayNode() string {
 	return ""
 }
 
+func (x *UpdateUserStatusRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *UpdateUserStatusRequest) GetCustomStatus() string {
+	if x != nil {
+		return x.CustomStatus
+	}
+	return ""
+}
+
 type UpdateUserStatusResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // 更新后对外可见的状态
+	CustomStatus  string                 `protobuf:"bytes,3,opt,name=custom_status,json=customStatus,proto3" json:"custom_status,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UpdateUserStatusResponse) Reset() {
 	*x = UpdateUserStatusResponse{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[19]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1014,7 +2009,7 @@ func (x *UpdateUserStatusResponse) String() string {
 func (*UpdateUserStatusResponse) ProtoMessage() {}
 
 func (x *UpdateUserStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[19]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1027,7 +2022,7 @@ func (x *UpdateUserStatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateUserStatusResponse.ProtoReflect.Descriptor instead.
 func (*UpdateUserStatusResponse) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{19}
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *UpdateUserStatusResponse) GetSuccess() bool {
@@ -1037,6 +2032,20 @@ func (x *UpdateUserStatusResponse) GetSuccess() bool {
 	return false
 }
 
+func (x *UpdateUserStatusResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *UpdateUserStatusResponse) GetCustomStatus() string {
+	if x != nil {
+		return x.CustomStatus
+	}
+	return ""
+}
+
 type GetGuildRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	GuildId       string                 `protobuf:"bytes,1,opt,name=guild_id,json=guildId,proto3" json:"guild_id,omitempty"`
@@ -1046,7 +2055,7 @@ type GetGuildRequest struct {
 
 func (x *GetGuildRequest) Reset() {
 	*x = GetGuildRequest{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[20]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1058,7 +2067,7 @@ func (x *GetGuildRequest) String() string {
 func (*GetGuildRequest) ProtoMessage() {}
 
 func (x *GetGuildRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[20]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1071,7 +2080,7 @@ func (x *GetGuildRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetGuildRequest.ProtoReflect.Descriptor instead.
 func (*GetGuildRequest) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{20}
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{36}
 }
 
 func (x *GetGuildRequest) GetGuildId() string {
@@ -1094,7 +2103,7 @@ type GetGuildResponse struct {
 
 func (x *GetGuildResponse) Reset() {
 	*x = GetGuildResponse{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[21]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1106,7 +2115,7 @@ func (x *GetGuildResponse) String() string {
 func (*GetGuildResponse) ProtoMessage() {}
 
 func (x *GetGuildResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[21]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1119,7 +2128,7 @@ func (x *GetGuildResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetGuildResponse.ProtoReflect.Descriptor instead.
 func (*GetGuildResponse) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{21}
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{37}
 }
 
 func (x *GetGuildResponse) GetGuildId() string {
@@ -1168,7 +2177,7 @@ type GetGuildMembersRequest struct {
 
 func (x *GetGuildMembersRequest) Reset() {
 	*x = GetGuildMembersRequest{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[22]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1180,7 +2189,7 @@ func (x *GetGuildMembersRequest) String() string {
 func (*GetGuildMembersRequest) ProtoMessage() {}
 
 func (x *GetGuildMembersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[22]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1193,7 +2202,7 @@ func (x *GetGuildMembersRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetGuildMembersRequest.ProtoReflect.Descriptor instead.
 func (*GetGuildMembersRequest) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{22}
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{38}
 }
 
 func (x *GetGuildMembersRequest) GetGuildId() string {
@@ -1227,7 +2236,7 @@ type GetGuildMembersResponse struct {
 
 func (x *GetGuildMembersResponse) Reset() {
 	*x = GetGuildMembersResponse{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[23]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1239,7 +2248,7 @@ func (x *GetGuildMembersResponse) String() string {
 func (*GetGuildMembersResponse) ProtoMessage() {}
 
 func (x *GetGuildMembersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[23]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1252,7 +2261,7 @@ func (x *GetGuildMembersResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetGuildMembersResponse.ProtoReflect.Descriptor instead.
 func (*GetGuildMembersResponse) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{23}
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{39}
 }
 
 func (x *GetGuildMembersResponse) GetUserIds() []string {
@@ -1279,7 +2288,7 @@ type CheckMembershipRequest struct {
 
 func (x *CheckMembershipRequest) Reset() {
 	*x = CheckMembershipRequest{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[24]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1291,7 +2300,7 @@ func (x *CheckMembershipRequest) String() string {
 func (*CheckMembershipRequest) ProtoMessage() {}
 
 func (x *CheckMembershipRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[24]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1304,7 +2313,7 @@ func (x *CheckMembershipRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CheckMembershipRequest.ProtoReflect.Descriptor instead.
 func (*CheckMembershipRequest) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{24}
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{40}
 }
 
 func (x *CheckMembershipRequest) GetUserId() string {
@@ -1331,7 +2340,7 @@ type CheckMembershipResponse struct {
 
 func (x *CheckMembershipResponse) Reset() {
 	*x = CheckMembershipResponse{}
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[25]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1343,7 +2352,7 @@ func (x *CheckMembershipResponse) String() string {
 func (*CheckMembershipResponse) ProtoMessage() {}
 
 func (x *CheckMembershipResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_pkg_proto_service_proto_msgTypes[25]
+	mi := &file_internal_pkg_proto_service_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1356,7 +2365,7 @@ func (x *CheckMembershipResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CheckMembershipResponse.ProtoReflect.Descriptor instead.
 func (*CheckMembershipResponse) Descriptor() ([]byte, []int) {
-	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{25}
+	return file_internal_pkg_proto_service_proto_rawDescGZIP(), []int{41}
 }
 
 func (x *CheckMembershipResponse) GetIsMember() bool {
@@ -1383,7 +2392,13 @@ const file_internal_pkg_proto_service_proto_rawDesc = "" +
 	"\amessage\x18\x02 \x01(\v2\x0f.chat.WSMessageR\amessage\"E\n" +
 	"\x13PushMessageResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"\x82\x01\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"Z\n" +
+	"\x12PushToUsersRequest\x12\x19\n" +
+	"\buser_ids\x18\x01 \x03(\tR\auserIds\x12)\n" +
+	"\amessage\x18\x02 \x01(\v2\x0f.chat.WSMessageR\amessage\"p\n" +
+	"\x13PushToUsersResponse\x12'\n" +
+	"\x0fdelivered_count\x18\x01 \x01(\x05R\x0edeliveredCount\x120\n" +
+	"\x14undelivered_user_ids\x18\x02 \x03(\tR\x12undeliveredUserIds\"\x82\x01\n" +
 	"\x10BroadcastRequest\x12\x19\n" +
 	"\bguild_id\x18\x01 \x01(\tR\aguildId\x12)\n" +
 	"\amessage\x18\x02 \x01(\v2\x0f.chat.WSMessageR\amessage\x12(\n" +
@@ -1396,21 +2411,38 @@ const file_internal_pkg_proto_service_proto_rawDesc = "" +
 	"\x12UserStatusResponse\x12\x16\n" +
 	"\x06online\x18\x01 \x01(\bR\x06online\x12!\n" +
 	"\fgateway_node\x18\x02 \x01(\tR\vgatewayNode\"\x11\n" +
-	"\x0fNodeInfoRequest\"\x97\x01\n" +
+	"\x0fNodeInfoRequest\"\xfa\x01\n" +
 	"\x10NodeInfoResponse\x12\x17\n" +
 	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12\x18\n" +
 	"\aaddress\x18\x02 \x01(\tR\aaddress\x12)\n" +
 	"\x10connection_count\x18\x03 \x01(\x05R\x0fconnectionCount\x12%\n" +
-	"\x0euptime_seconds\x18\x04 \x01(\x03R\ruptimeSeconds\"\x14\n" +
+	"\x0euptime_seconds\x18\x04 \x01(\x03R\ruptimeSeconds\x12%\n" +
+	"\x0edropped_frames\x18\x05 \x01(\x04R\rdroppedFrames\x12:\n" +
+	"\x19slow_consumer_disconnects\x18\x06 \x01(\x04R\x17slowConsumerDisconnects\"I\n" +
+	"\fDrainRequest\x12%\n" +
+	"\x0ewindow_seconds\x18\x01 \x01(\x05R\rwindowSeconds\x12\x12\n" +
+	"\x04wait\x18\x02 \x01(\bR\x04wait\"T\n" +
+	"\rDrainResponse\x12\x18\n" +
+	"\adrained\x18\x01 \x01(\bR\adrained\x12)\n" +
+	"\x10connection_count\x18\x02 \x01(\x05R\x0fconnectionCount\"v\n" +
+	"\x16SubscribeGuildsRequest\x12\x1b\n" +
+	"\tguild_ids\x18\x01 \x03(\tR\bguildIds\x12?\n" +
+	"\x10resume_positions\x18\x02 \x03(\v2\x14.chat.ResumePositionR\x0fresumePositions\"\x14\n" +
 	"\x12HealthCheckRequest\"G\n" +
 	"\x13HealthCheckResponse\x12\x18\n" +
 	"\ahealthy\x18\x01 \x01(\bR\ahealthy\x12\x16\n" +
-	"\x06status\x18\x02 \x01(\tR\x06status\"\x89\x01\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"\xae\x02\n" +
 	"\x12SendMessageRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x19\n" +
 	"\bguild_id\x18\x02 \x01(\tR\aguildId\x12\x18\n" +
 	"\acontent\x18\x03 \x01(\tR\acontent\x12%\n" +
-	"\x04type\x18\x04 \x01(\x0e2\x11.chat.MessageTypeR\x04type\"V\n" +
+	"\x04type\x18\x04 \x01(\x0e2\x11.chat.MessageTypeR\x04type\x12\x1e\n" +
+	"\vreply_to_id\x18\x05 \x01(\tR\treplyToId\x12\x1d\n" +
+	"\n" +
+	"channel_id\x18\x06 \x01(\tR\tchannelId\x12'\n" +
+	"\x0fconversation_id\x18\a \x01(\tR\x0econversationId\x12%\n" +
+	"\x0eattachment_ids\x18\b \x03(\tR\rattachmentIds\x12\x14\n" +
+	"\x05nonce\x18\t \x01(\tR\x05nonce\"V\n" +
 	"\x13SendMessageResponse\x12)\n" +
 	"\amessage\x18\x01 \x01(\v2\x0f.chat.WSMessageR\amessage\x12\x14\n" +
 	"\x05error\x18\x02 \x01(\tR\x05error\":\n" +
@@ -1418,7 +2450,57 @@ const file_internal_pkg_proto_service_proto_rawDesc = "" +
 	"\vmessage_ids\x18\x01 \x03(\tR\n" +
 	"messageIds\"G\n" +
 	"\x18BatchGetMessagesResponse\x12+\n" +
-	"\bmessages\x18\x01 \x03(\v2\x0f.chat.WSMessageR\bmessages\")\n" +
+	"\bmessages\x18\x01 \x03(\v2\x0f.chat.WSMessageR\bmessages\"f\n" +
+	"\x12EditMessageRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x02 \x01(\tR\tmessageId\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\"V\n" +
+	"\x13EditMessageResponse\x12)\n" +
+	"\amessage\x18\x01 \x01(\v2\x0f.chat.WSMessageR\amessage\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"N\n" +
+	"\x14DeleteMessageRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x02 \x01(\tR\tmessageId\"G\n" +
+	"\x15DeleteMessageResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"_\n" +
+	"\x0fReactionRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x02 \x01(\tR\tmessageId\x12\x14\n" +
+	"\x05emoji\x18\x03 \x01(\tR\x05emoji\"B\n" +
+	"\x10ReactionResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"K\n" +
+	"\x15PinnedMessagesRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x19\n" +
+	"\bguild_id\x18\x02 \x01(\tR\aguildId\"E\n" +
+	"\x16PinnedMessagesResponse\x12+\n" +
+	"\bmessages\x18\x01 \x03(\v2\x0f.chat.WSMessageR\bmessages\"\xa0\x02\n" +
+	"\x15SearchMessagesRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x19\n" +
+	"\bguild_id\x18\x02 \x01(\tR\aguildId\x12\x14\n" +
+	"\x05query\x18\x03 \x01(\tR\x05query\x12\x1d\n" +
+	"\n" +
+	"channel_id\x18\x04 \x01(\tR\tchannelId\x12\x1b\n" +
+	"\tauthor_id\x18\x05 \x01(\tR\bauthorId\x12\x14\n" +
+	"\x05after\x18\x06 \x01(\x03R\x05after\x12\x16\n" +
+	"\x06before\x18\a \x01(\x03R\x06before\x12%\n" +
+	"\x0ehas_attachment\x18\b \x01(\bR\rhasAttachment\x12\x16\n" +
+	"\x06offset\x18\t \x01(\x05R\x06offset\x12\x14\n" +
+	"\x05limit\x18\n" +
+	" \x01(\x05R\x05limit\"g\n" +
+	"\fSearchResult\x12)\n" +
+	"\amessage\x18\x01 \x01(\v2\x0f.chat.WSMessageR\amessage\x12\x12\n" +
+	"\x04rank\x18\x02 \x01(\x01R\x04rank\x12\x18\n" +
+	"\asnippet\x18\x03 \x01(\tR\asnippet\"\x82\x01\n" +
+	"\x16SearchMessagesResponse\x12,\n" +
+	"\aresults\x18\x01 \x03(\v2\x12.chat.SearchResultR\aresults\x12\x19\n" +
+	"\bhas_more\x18\x02 \x01(\bR\ahasMore\x12\x1f\n" +
+	"\vnext_offset\x18\x03 \x01(\x05R\n" +
+	"nextOffset\")\n" +
 	"\x0eGetUserRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\"|\n" +
 	"\x0fGetUserResponse\x12\x17\n" +
@@ -1430,13 +2512,17 @@ const file_internal_pkg_proto_service_proto_rawDesc = "" +
 	"\x14BatchGetUsersRequest\x12\x19\n" +
 	"\buser_ids\x18\x01 \x03(\tR\auserIds\"D\n" +
 	"\x15BatchGetUsersResponse\x12+\n" +
-	"\x05users\x18\x01 \x03(\v2\x15.chat.GetUserResponseR\x05users\"m\n" +
+	"\x05users\x18\x01 \x03(\v2\x15.chat.GetUserResponseR\x05users\"\xaa\x01\n" +
 	"\x17UpdateUserStatusRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x16\n" +
 	"\x06online\x18\x02 \x01(\bR\x06online\x12!\n" +
-	"\fgateway_node\x18\x03 \x01(\tR\vgatewayNode\"4\n" +
+	"\fgateway_node\x18\x03 \x01(\tR\vgatewayNode\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12#\n" +
+	"\rcustom_status\x18\x05 \x01(\tR\fcustomStatus\"q\n" +
 	"\x18UpdateUserStatusResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\",\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12#\n" +
+	"\rcustom_status\x18\x03 \x01(\tR\fcustomStatus\",\n" +
 	"\x0fGetGuildRequest\x12\x19\n" +
 	"\bguild_id\x18\x01 \x01(\tR\aguildId\"\x9c\x01\n" +
 	"\x10GetGuildResponse\x12\x19\n" +
@@ -1460,18 +2546,28 @@ const file_internal_pkg_proto_service_proto_rawDesc = "" +
 	"\bguild_id\x18\x02 \x01(\tR\aguildId\"S\n" +
 	"\x17CheckMembershipResponse\x12\x1b\n" +
 	"\tis_member\x18\x01 \x01(\bR\bisMember\x12\x1b\n" +
-	"\tjoined_at\x18\x02 \x01(\x03R\bjoinedAt2\xe1\x02\n" +
+	"\tjoined_at\x18\x02 \x01(\x03R\bjoinedAt2\x9b\x04\n" +
 	"\x0eGatewayService\x12B\n" +
-	"\vPushMessage\x12\x18.chat.PushMessageRequest\x1a\x19.chat.PushMessageResponse\x12C\n" +
+	"\vPushMessage\x12\x18.chat.PushMessageRequest\x1a\x19.chat.PushMessageResponse\x12B\n" +
+	"\vPushToUsers\x12\x18.chat.PushToUsersRequest\x1a\x19.chat.PushToUsersResponse\x12C\n" +
 	"\x10BroadcastToGuild\x12\x16.chat.BroadcastRequest\x1a\x17.chat.BroadcastResponse\x12D\n" +
 	"\x0fCheckUserOnline\x12\x17.chat.UserStatusRequest\x1a\x18.chat.UserStatusResponse\x12<\n" +
 	"\vGetNodeInfo\x12\x15.chat.NodeInfoRequest\x1a\x16.chat.NodeInfoResponse\x12B\n" +
-	"\vHealthCheck\x12\x18.chat.HealthCheckRequest\x1a\x19.chat.HealthCheckResponse2\xe2\x01\n" +
+	"\vHealthCheck\x12\x18.chat.HealthCheckRequest\x1a\x19.chat.HealthCheckResponse\x120\n" +
+	"\x05Drain\x12\x12.chat.DrainRequest\x1a\x13.chat.DrainResponse\x12B\n" +
+	"\x0fSubscribeGuilds\x12\x1c.chat.SubscribeGuildsRequest\x1a\x0f.chat.WSMessage0\x012\xc4\x05\n" +
 	"\x0eMessageService\x12B\n" +
 	"\vSendMessage\x12\x18.chat.SendMessageRequest\x1a\x19.chat.SendMessageResponse\x129\n" +
 	"\n" +
-	"GetHistory\x12\x14.chat.HistoryRequest\x1a\x15.chat.HistoryResponse\x12Q\n" +
-	"\x10BatchGetMessages\x12\x1d.chat.BatchGetMessagesRequest\x1a\x1e.chat.BatchGetMessagesResponse2\xe2\x01\n" +
+	"GetHistory\x12\x14.chat.HistoryRequest\x1a\x15.chat.HistoryResponse\x126\n" +
+	"\tGetThread\x12\x13.chat.ThreadRequest\x1a\x14.chat.ThreadResponse\x12Q\n" +
+	"\x10BatchGetMessages\x12\x1d.chat.BatchGetMessagesRequest\x1a\x1e.chat.BatchGetMessagesResponse\x12B\n" +
+	"\vEditMessage\x12\x18.chat.EditMessageRequest\x1a\x19.chat.EditMessageResponse\x12H\n" +
+	"\rDeleteMessage\x12\x1a.chat.DeleteMessageRequest\x1a\x1b.chat.DeleteMessageResponse\x12<\n" +
+	"\vAddReaction\x12\x15.chat.ReactionRequest\x1a\x16.chat.ReactionResponse\x12?\n" +
+	"\x0eRemoveReaction\x12\x15.chat.ReactionRequest\x1a\x16.chat.ReactionResponse\x12N\n" +
+	"\x11GetPinnedMessages\x12\x1b.chat.PinnedMessagesRequest\x1a\x1c.chat.PinnedMessagesResponse\x12K\n" +
+	"\x0eSearchMessages\x12\x1b.chat.SearchMessagesRequest\x1a\x1c.chat.SearchMessagesResponse2\xe2\x01\n" +
 	"\vUserService\x126\n" +
 	"\aGetUser\x12\x14.chat.GetUserRequest\x1a\x15.chat.GetUserResponse\x12H\n" +
 	"\rBatchGetUsers\x12\x1a.chat.BatchGetUsersRequest\x1a\x1b.chat.BatchGetUsersResponse\x12Q\n" +
@@ -1493,79 +2589,124 @@ func file_internal_pkg_proto_service_proto_rawDescGZIP() []byte {
 	return file_internal_pkg_proto_service_proto_rawDescData
 }
 
-var file_internal_pkg_proto_service_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
+var file_internal_pkg_proto_service_proto_msgTypes = make([]protoimpl.MessageInfo, 42)
 var file_internal_pkg_proto_service_proto_goTypes = []any{
 	(*PushMessageRequest)(nil),       // 0: chat.PushMessageRequest
 	(*PushMessageResponse)(nil),      // 1: chat.PushMessageResponse
-	(*BroadcastRequest)(nil),         // 2: chat.BroadcastRequest
-	(*BroadcastResponse)(nil),        // 3: chat.BroadcastResponse
-	(*UserStatusRequest)(nil),        // 4: chat.UserStatusRequest
-	(*UserStatusResponse)(nil),       // 5: chat.UserStatusResponse
-	(*NodeInfoRequest)(nil),          // 6: chat.NodeInfoRequest
-	(*NodeInfoResponse)(nil),         // 7: chat.NodeInfoResponse
-	(*HealthCheckRequest)(nil),       // 8: chat.HealthCheckRequest
-	(*HealthCheckResponse)(nil),      // 9: chat.HealthCheckResponse
-	(*SendMessageRequest)(nil),       // 10: chat.SendMessageRequest
-	(*SendMessageResponse)(nil),      // 11: chat.SendMessageResponse
-	(*BatchGetMessagesRequest)(nil),  // 12: chat.BatchGetMessagesRequest
-	(*BatchGetMessagesResponse)(nil), // 13: chat.BatchGetMessagesResponse
-	(*GetUserRequest)(nil),           // 14: chat.GetUserRequest
-	(*GetUserResponse)(nil),          // 15: chat.GetUserResponse
-	(*BatchGetUsersRequest)(nil),     // 16: chat.BatchGetUsersRequest
-	(*BatchGetUsersResponse)(nil),    // 17: chat.BatchGetUsersResponse
-	(*UpdateUserStatusRequest)(nil),  // 18: chat.UpdateUserStatusRequest
-	(*UpdateUserStatusResponse)(nil), // 19: chat.UpdateUserStatusResponse
-	(*GetGuildRequest)(nil),          // 20: chat.GetGuildRequest
-	(*GetGuildResponse)(nil),         // 21: chat.GetGuildResponse
-	(*GetGuildMembersRequest)(nil),   // 22: chat.GetGuildMembersRequest
-	(*GetGuildMembersResponse)(nil),  // 23: chat.GetGuildMembersResponse
-	(*CheckMembershipRequest)(nil),   // 24: chat.CheckMembershipRequest
-	(*CheckMembershipResponse)(nil),  // 25: chat.CheckMembershipResponse
-	(*WSMessage)(nil),                // 26: chat.WSMessage
-	(MessageType)(0),                 // 27: chat.MessageType
-	(*HistoryRequest)(nil),           // 28: chat.HistoryRequest
-	(*HistoryResponse)(nil),          // 29: chat.HistoryResponse
+	(*PushToUsersRequest)(nil),       // 2: chat.PushToUsersRequest
+	(*PushToUsersResponse)(nil),      // 3: chat.PushToUsersResponse
+	(*BroadcastRequest)(nil),         // 4: chat.BroadcastRequest
+	(*BroadcastResponse)(nil),        // 5: chat.BroadcastResponse
+	(*UserStatusRequest)(nil),        // 6: chat.UserStatusRequest
+	(*UserStatusResponse)(nil),       // 7: chat.UserStatusResponse
+	(*NodeInfoRequest)(nil),          // 8: chat.NodeInfoRequest
+	(*NodeInfoResponse)(nil),         // 9: chat.NodeInfoResponse
+	(*DrainRequest)(nil),             // 10: chat.DrainRequest
+	(*DrainResponse)(nil),            // 11: chat.DrainResponse
+	(*SubscribeGuildsRequest)(nil),   // 12: chat.SubscribeGuildsRequest
+	(*HealthCheckRequest)(nil),       // 13: chat.HealthCheckRequest
+	(*HealthCheckResponse)(nil),      // 14: chat.HealthCheckResponse
+	(*SendMessageRequest)(nil),       // 15: chat.SendMessageRequest
+	(*SendMessageResponse)(nil),      // 16: chat.SendMessageResponse
+	(*BatchGetMessagesRequest)(nil),  // 17: chat.BatchGetMessagesRequest
+	(*BatchGetMessagesResponse)(nil), // 18: chat.BatchGetMessagesResponse
+	(*EditMessageRequest)(nil),       // 19: chat.EditMessageRequest
+	(*EditMessageResponse)(nil),      // 20: chat.EditMessageResponse
+	(*DeleteMessageRequest)(nil),     // 21: chat.DeleteMessageRequest
+	(*DeleteMessageResponse)(nil),    // 22: chat.DeleteMessageResponse
+	(*ReactionRequest)(nil),          // 23: chat.ReactionRequest
+	(*ReactionResponse)(nil),         // 24: chat.ReactionResponse
+	(*PinnedMessagesRequest)(nil),    // 25: chat.PinnedMessagesRequest
+	(*PinnedMessagesResponse)(nil),   // 26: chat.PinnedMessagesResponse
+	(*SearchMessagesRequest)(nil),    // 27: chat.SearchMessagesRequest
+	(*SearchResult)(nil),             // 28: chat.SearchResult
+	(*SearchMessagesResponse)(nil),   // 29: chat.SearchMessagesResponse
+	(*GetUserRequest)(nil),           // 30: chat.GetUserRequest
+	(*GetUserResponse)(nil),          // 31: chat.GetUserResponse
+	(*BatchGetUsersRequest)(nil),     // 32: chat.BatchGetUsersRequest
+	(*BatchGetUsersResponse)(nil),    // 33: chat.BatchGetUsersResponse
+	(*UpdateUserStatusRequest)(nil),  // 34: chat.UpdateUserStatusRequest
+	(*UpdateUserStatusResponse)(nil), // 35: chat.UpdateUserStatusResponse
+	(*GetGuildRequest)(nil),          // 36: chat.GetGuildRequest
+	(*GetGuildResponse)(nil),         // 37: chat.GetGuildResponse
+	(*GetGuildMembersRequest)(nil),   // 38: chat.GetGuildMembersRequest
+	(*GetGuildMembersResponse)(nil),  // 39: chat.GetGuildMembersResponse
+	(*CheckMembershipRequest)(nil),   // 40: chat.CheckMembershipRequest
+	(*CheckMembershipResponse)(nil),  // 41: chat.CheckMembershipResponse
+	(*WSMessage)(nil),                // 42: chat.WSMessage
+	(*ResumePosition)(nil),           // 43: chat.ResumePosition
+	(MessageType)(0),                 // 44: chat.MessageType
+	(*HistoryRequest)(nil),           // 45: chat.HistoryRequest
+	(*ThreadRequest)(nil),            // 46: chat.ThreadRequest
+	(*HistoryResponse)(nil),          // 47: chat.HistoryResponse
+	(*ThreadResponse)(nil),           // 48: chat.ThreadResponse
 }
 var file_internal_pkg_proto_service_proto_depIdxs = []int32{
-	26, // 0: chat.PushMessageRequest.message:type_name -> chat.WSMessage
-	26, // 1: chat.BroadcastRequest.message:type_name -> chat.WSMessage
-	27, // 2: chat.SendMessageRequest.type:type_name -> chat.MessageType
-	26, // 3: chat.SendMessageResponse.message:type_name -> chat.WSMessage
-	26, // 4: chat.BatchGetMessagesResponse.messages:type_name -> chat.WSMessage
-	15, // 5: chat.BatchGetUsersResponse.users:type_name -> chat.GetUserResponse
-	0,  // 6: chat.GatewayService.PushMessage:input_type -> chat.PushMessageRequest
-	2,  // 7: chat.GatewayService.BroadcastToGuild:input_type -> chat.BroadcastRequest
-	4,  // 8: chat.GatewayService.CheckUserOnline:input_type -> chat.UserStatusRequest
-	6,  // 9: chat.GatewayService.GetNodeInfo:input_type -> chat.NodeInfoRequest
-	8,  // 10: chat.GatewayService.HealthCheck:input_type -> chat.HealthCheckRequest
-	10, // 11: chat.MessageService.SendMessage:input_type -> chat.SendMessageRequest
-	28, // 12: chat.MessageService.GetHistory:input_type -> chat.HistoryRequest
-	12, // 13: chat.MessageService.BatchGetMessages:input_type -> chat.BatchGetMessagesRequest
-	14, // 14: chat.UserService.GetUser:input_type -> chat.GetUserRequest
-	16, // 15: chat.UserService.BatchGetUsers:input_type -> chat.BatchGetUsersRequest
-	18, // 16: chat.UserService.UpdateUserStatus:input_type -> chat.UpdateUserStatusRequest
-	20, // 17: chat.GuildService.GetGuild:input_type -> chat.GetGuildRequest
-	22, // 18: chat.GuildService.GetGuildMembers:input_type -> chat.GetGuildMembersRequest
-	24, // 19: chat.GuildService.CheckMembership:input_type -> chat.CheckMembershipRequest
-	1,  // 20: chat.GatewayService.PushMessage:output_type -> chat.PushMessageResponse
-	3,  // 21: chat.GatewayService.BroadcastToGuild:output_type -> chat.BroadcastResponse
-	5,  // 22: chat.GatewayService.CheckUserOnline:output_type -> chat.UserStatusResponse
-	7,  // 23: chat.GatewayService.GetNodeInfo:output_type -> chat.NodeInfoResponse
-	9,  // 24: chat.GatewayService.HealthCheck:output_type -> chat.HealthCheckResponse
-	11, // 25: chat.MessageService.SendMessage:output_type -> chat.SendMessageResponse
-	29, // 26: chat.MessageService.GetHistory:output_type -> chat.HistoryResponse
-	13, // 27: chat.MessageService.BatchGetMessages:output_type -> chat.BatchGetMessagesResponse
-	15, // 28: chat.UserService.GetUser:output_type -> chat.GetUserResponse
-	17, // 29: chat.UserService.BatchGetUsers:output_type -> chat.BatchGetUsersResponse
-	19, // 30: chat.UserService.UpdateUserStatus:output_type -> chat.UpdateUserStatusResponse
-	21, // 31: chat.GuildService.GetGuild:output_type -> chat.GetGuildResponse
-	23, // 32: chat.GuildService.GetGuildMembers:output_type -> chat.GetGuildMembersResponse
-	25, // 33: chat.GuildService.CheckMembership:output_type -> chat.CheckMembershipResponse
-	20, // [20:34] is the sub-list for method output_type
-	6,  // [6:20] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	42, // 0: chat.PushMessageRequest.message:type_name -> chat.WSMessage
+	42, // 1: chat.PushToUsersRequest.message:type_name -> chat.WSMessage
+	42, // 2: chat.BroadcastRequest.message:type_name -> chat.WSMessage
+	43, // 3: chat.SubscribeGuildsRequest.resume_positions:type_name -> chat.ResumePosition
+	44, // 4: chat.SendMessageRequest.type:type_name -> chat.MessageType
+	42, // 5: chat.SendMessageResponse.message:type_name -> chat.WSMessage
+	42, // 6: chat.BatchGetMessagesResponse.messages:type_name -> chat.WSMessage
+	42, // 7: chat.EditMessageResponse.message:type_name -> chat.WSMessage
+	42, // 8: chat.PinnedMessagesResponse.messages:type_name -> chat.WSMessage
+	42, // 9: chat.SearchResult.message:type_name -> chat.WSMessage
+	28, // 10: chat.SearchMessagesResponse.results:type_name -> chat.SearchResult
+	31, // 11: chat.BatchGetUsersResponse.users:type_name -> chat.GetUserResponse
+	0,  // 12: chat.GatewayService.PushMessage:input_type -> chat.PushMessageRequest
+	2,  // 13: chat.GatewayService.PushToUsers:input_type -> chat.PushToUsersRequest
+	4,  // 14: chat.GatewayService.BroadcastToGuild:input_type -> chat.BroadcastRequest
+	6,  // 15: chat.GatewayService.CheckUserOnline:input_type -> chat.UserStatusRequest
+	8,  // 16: chat.GatewayService.GetNodeInfo:input_type -> chat.NodeInfoRequest
+	13, // 17: chat.GatewayService.HealthCheck:input_type -> chat.HealthCheckRequest
+	10, // 18: chat.GatewayService.Drain:input_type -> chat.DrainRequest
+	12, // 19: chat.GatewayService.SubscribeGuilds:input_type -> chat.SubscribeGuildsRequest
+	15, // 20: chat.MessageService.SendMessage:input_type -> chat.SendMessageRequest
+	45, // 21: chat.MessageService.GetHistory:input_type -> chat.HistoryRequest
+	46, // 22: chat.MessageService.GetThread:input_type -> chat.ThreadRequest
+	17, // 23: chat.MessageService.BatchGetMessages:input_type -> chat.BatchGetMessagesRequest
+	19, // 24: chat.MessageService.EditMessage:input_type -> chat.EditMessageRequest
+	21, // 25: chat.MessageService.DeleteMessage:input_type -> chat.DeleteMessageRequest
+	23, // 26: chat.MessageService.AddReaction:input_type -> chat.ReactionRequest
+	23, // 27: chat.MessageService.RemoveReaction:input_type -> chat.ReactionRequest
+	25, // 28: chat.MessageService.GetPinnedMessages:input_type -> chat.PinnedMessagesRequest
+	27, // 29: chat.MessageService.SearchMessages:input_type -> chat.SearchMessagesRequest
+	30, // 30: chat.UserService.GetUser:input_type -> chat.GetUserRequest
+	32, // 31: chat.UserService.BatchGetUsers:input_type -> chat.BatchGetUsersRequest
+	34, // 32: chat.UserService.UpdateUserStatus:input_type -> chat.UpdateUserStatusRequest
+	36, // 33: chat.GuildService.GetGuild:input_type -> chat.GetGuildRequest
+	38, // 34: chat.GuildService.GetGuildMembers:input_type -> chat.GetGuildMembersRequest
+	40, // 35: chat.GuildService.CheckMembership:input_type -> chat.CheckMembershipRequest
+	1,  // 36: chat.GatewayService.PushMessage:output_type -> chat.PushMessageResponse
+	3,  // 37: chat.GatewayService.PushToUsers:output_type -> chat.PushToUsersResponse
+	5,  // 38: chat.GatewayService.BroadcastToGuild:output_type -> chat.BroadcastResponse
+	7,  // 39: chat.GatewayService.CheckUserOnline:output_type -> chat.UserStatusResponse
+	9,  // 40: chat.GatewayService.GetNodeInfo:output_type -> chat.NodeInfoResponse
+	14, // 41: chat.GatewayService.HealthCheck:output_type -> chat.HealthCheckResponse
+	11, // 42: chat.GatewayService.Drain:output_type -> chat.DrainResponse
+	42, // 43: chat.GatewayService.SubscribeGuilds:output_type -> chat.WSMessage
+	16, // 44: chat.MessageService.SendMessage:output_type -> chat.SendMessageResponse
+	47, // 45: chat.MessageService.GetHistory:output_type -> chat.HistoryResponse
+	48, // 46: chat.MessageService.GetThread:output_type -> chat.ThreadResponse
+	18, // 47: chat.MessageService.BatchGetMessages:output_type -> chat.BatchGetMessagesResponse
+	20, // 48: chat.MessageService.EditMessage:output_type -> chat.EditMessageResponse
+	22, // 49: chat.MessageService.DeleteMessage:output_type -> chat.DeleteMessageResponse
+	24, // 50: chat.MessageService.AddReaction:output_type -> chat.ReactionResponse
+	24, // 51: chat.MessageService.RemoveReaction:output_type -> chat.ReactionResponse
+	26, // 52: chat.MessageService.GetPinnedMessages:output_type -> chat.PinnedMessagesResponse
+	29, // 53: chat.MessageService.SearchMessages:output_type -> chat.SearchMessagesResponse
+	31, // 54: chat.UserService.GetUser:output_type -> chat.GetUserResponse
+	33, // 55: chat.UserService.BatchGetUsers:output_type -> chat.BatchGetUsersResponse
+	35, // 56: chat.UserService.UpdateUserStatus:output_type -> chat.UpdateUserStatusResponse
+	37, // 57: chat.GuildService.GetGuild:output_type -> chat.GetGuildResponse
+	39, // 58: chat.GuildService.GetGuildMembers:output_type -> chat.GetGuildMembersResponse
+	41, // 59: chat.GuildService.CheckMembership:output_type -> chat.CheckMembershipResponse
+	36, // [36:60] is the sub-list for method output_type
+	12, // [12:36] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_internal_pkg_proto_service_proto_init() }
@@ -1580,7 +2721,7 @@ func file_internal_pkg_proto_service_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_pkg_proto_service_proto_rawDesc), len(file_internal_pkg_proto_service_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   26,
+			NumMessages:   42,
 			NumExtensions: 0,
 			NumServices:   4,
 		},
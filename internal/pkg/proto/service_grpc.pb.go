@@ -20,10 +20,13 @@ const _ = grpc.SupportPackageIsVersion9
 
 const (
 	GatewayService_PushMessage_FullMethodName      = "/chat.GatewayService/PushMessage"
+	GatewayService_PushToUsers_FullMethodName      = "/chat.GatewayService/PushToUsers"
 	GatewayService_BroadcastToGuild_FullMethodName = "/chat.GatewayService/BroadcastToGuild"
 	GatewayService_CheckUserOnline_FullMethodName  = "/chat.GatewayService/CheckUserOnline"
 	GatewayService_GetNodeInfo_FullMethodName      = "/chat.GatewayService/GetNodeInfo"
 	GatewayService_HealthCheck_FullMethodName      = "/chat.GatewayService/HealthCheck"
+	GatewayService_Drain_FullMethodName            = "/chat.GatewayService/Drain"
+	GatewayService_SubscribeGuilds_FullMethodName  = "/chat.GatewayService/SubscribeGuilds"
 )
 
 // GatewayServiceClient is the client API for GatewayService service.
@@ -35,6 +38,8 @@ const (
 type GatewayServiceClient interface {
 	// 推送消息到指定用户的连接
 	PushMessage(ctx context.Context, in *PushMessageRequest, opts ...grpc.CallOption) (*PushMessageResponse, error)
+	// 批量推送消息到本节点上的多个用户，用于按节点分组的事件路由
+	PushToUsers(ctx context.Context, in *PushToUsersRequest, opts ...grpc.CallOption) (*PushToUsersResponse, error)
 	// 推送消息到 Guild 的所有在线成员
 	BroadcastToGuild(ctx context.Context, in *BroadcastRequest, opts ...grpc.CallOption) (*BroadcastResponse, error)
 	// 检查用户是否在线
@@ -43,6 +48,11 @@ type GatewayServiceClient interface {
 	GetNodeInfo(ctx context.Context, in *NodeInfoRequest, opts ...grpc.CallOption) (*NodeInfoResponse, error)
 	// 健康检查
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
+	// 排空节点：停止接受新连接，并分批通知客户端重连到其他节点
+	Drain(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*DrainResponse, error)
+	// 订阅 Guild 的实时事件，供机器人、归档、统计等内部服务使用
+	// 事件与 WebSocket 会话收到的一致，设置恢复位置时先补发之后的消息
+	SubscribeGuilds(ctx context.Context, in *SubscribeGuildsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WSMessage], error)
 }
 
 type gatewayServiceClient struct {
@@ -63,6 +73,16 @@ func (c *gatewayServiceClient) PushMessage(ctx context.Context, in *PushMessageR
 	return out, nil
 }
 
+func (c *gatewayServiceClient) PushToUsers(ctx context.Context, in *PushToUsersRequest, opts ...grpc.CallOption) (*PushToUsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PushToUsersResponse)
+	err := c.cc.Invoke(ctx, GatewayService_PushToUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *gatewayServiceClient) BroadcastToGuild(ctx context.Context, in *BroadcastRequest, opts ...grpc.CallOption) (*BroadcastResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(BroadcastResponse)
@@ -103,6 +123,35 @@ func (c *gatewayServiceClient) HealthCheck(ctx context.Context, in *HealthCheckR
 	return out, nil
 }
 
+func (c *gatewayServiceClient) Drain(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*DrainResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DrainResponse)
+	err := c.cc.Invoke(ctx, GatewayService_Drain_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayServiceClient) SubscribeGuilds(ctx context.Context, in *SubscribeGuildsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WSMessage], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GatewayService_ServiceDesc.Streams[0], GatewayService_SubscribeGuilds_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeGuildsRequest, WSMessage]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GatewayService_SubscribeGuildsClient = grpc.ServerStreamingClient[WSMessage]
+
 // GatewayServiceServer is the server API for GatewayService service.
 // All implementations must embed UnimplementedGatewayServiceServer
 // for forward compatibility.
@@ -112,6 +161,8 @@ func (c *gatewayServiceClient) HealthCheck(ctx context.Context, in *HealthCheckR
 type GatewayServiceServer interface {
 	// 推送消息到指定用户的连接
 	PushMessage(context.Context, *PushMessageRequest) (*PushMessageResponse, error)
+	// 批量推送消息到本节点上的多个用户，用于按节点分组的事件路由
+	PushToUsers(context.Context, *PushToUsersRequest) (*PushToUsersResponse, error)
 	// 推送消息到 Guild 的所有在线成员
 	BroadcastToGuild(context.Context, *BroadcastRequest) (*BroadcastResponse, error)
 	// 检查用户是否在线
@@ -120,6 +171,11 @@ type GatewayServiceServer interface {
 	GetNodeInfo(context.Context, *NodeInfoRequest) (*NodeInfoResponse, error)
 	// 健康检查
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
+	// 排空节点：停止接受新连接，并分批通知客户端重连到其他节点
+	Drain(context.Context, *DrainRequest) (*DrainResponse, error)
+	// 订阅 Guild 的实时事件，供机器人、归档、统计等内部服务使用
+	// 事件与 WebSocket 会话收到的一致，设置恢复位置时先补发之后的消息
+	SubscribeGuilds(*SubscribeGuildsRequest, grpc.ServerStreamingServer[WSMessage]) error
 	mustEmbedUnimplementedGatewayServiceServer()
 }
 
@@ -133,6 +189,9 @@ type UnimplementedGatewayServiceServer struct{}
 func (UnimplementedGatewayServiceServer) PushMessage(context.Context, *PushMessageRequest) (*PushMessageResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method PushMessage not implemented")
 }
+func (UnimplementedGatewayServiceServer) PushToUsers(context.Context, *PushToUsersRequest) (*PushToUsersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PushToUsers not implemented")
+}
 func (UnimplementedGatewayServiceServer) BroadcastToGuild(context.Context, *BroadcastRequest) (*BroadcastResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method BroadcastToGuild not implemented")
 }
@@ -145,6 +204,12 @@ func (UnimplementedGatewayServiceServer) GetNodeInfo(context.Context, *NodeInfoR
 func (UnimplementedGatewayServiceServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method HealthCheck not implemented")
 }
+func (UnimplementedGatewayServiceServer) Drain(context.Context, *DrainRequest) (*DrainResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Drain not implemented")
+}
+func (UnimplementedGatewayServiceServer) SubscribeGuilds(*SubscribeGuildsRequest, grpc.ServerStreamingServer[WSMessage]) error {
+	return status.Error(codes.Unimplemented, "method SubscribeGuilds not implemented")
+}
 func (UnimplementedGatewayServiceServer) mustEmbedUnimplementedGatewayServiceServer() {}
 func (UnimplementedGatewayServiceServer) testEmbeddedByValue()                        {}
 
@@ -184,6 +249,24 @@ func _GatewayService_PushMessage_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GatewayService_PushToUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushToUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServiceServer).PushToUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayService_PushToUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServiceServer).PushToUsers(ctx, req.(*PushToUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GatewayService_BroadcastToGuild_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(BroadcastRequest)
 	if err := dec(in); err != nil {
@@ -256,6 +339,35 @@ func _GatewayService_HealthCheck_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GatewayService_Drain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DrainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServiceServer).Drain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayService_Drain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServiceServer).Drain(ctx, req.(*DrainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayService_SubscribeGuilds_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeGuildsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GatewayServiceServer).SubscribeGuilds(m, &grpc.GenericServerStream[SubscribeGuildsRequest, WSMessage]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GatewayService_SubscribeGuildsServer = grpc.ServerStreamingServer[WSMessage]
+
 // GatewayService_ServiceDesc is the grpc.ServiceDesc for GatewayService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -267,6 +379,10 @@ var GatewayService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "PushMessage",
 			Handler:    _GatewayService_PushMessage_Handler,
 		},
+		{
+			MethodName: "PushToUsers",
+			Handler:    _GatewayService_PushToUsers_Handler,
+		},
 		{
 			MethodName: "BroadcastToGuild",
 			Handler:    _GatewayService_BroadcastToGuild_Handler,
@@ -283,15 +399,32 @@ var GatewayService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "HealthCheck",
 			Handler:    _GatewayService_HealthCheck_Handler,
 		},
+		{
+			MethodName: "Drain",
+			Handler:    _GatewayService_Drain_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeGuilds",
+			Handler:       _GatewayService_SubscribeGuilds_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/pkg/proto/service.proto",
 }
 
 const (
-	MessageService_SendMessage_FullMethodName      = "/chat.MessageService/SendMessage"
-	MessageService_GetHistory_FullMethodName       = "/chat.MessageService/GetHistory"
-	MessageService_BatchGetMessages_FullMethodName = "/chat.MessageService/BatchGetMessages"
+	MessageService_SendMessage_FullMethodName       = "/chat.MessageService/SendMessage"
+	MessageService_GetHistory_FullMethodName        = "/chat.MessageService/GetHistory"
+	MessageService_GetThread_FullMethodName         = "/chat.MessageService/GetThread"
+	MessageService_BatchGetMessages_FullMethodName  = "/chat.MessageService/BatchGetMessages"
+	MessageService_EditMessage_FullMethodName       = "/chat.MessageService/EditMessage"
+	MessageService_DeleteMessage_FullMethodName     = "/chat.MessageService/DeleteMessage"
+	MessageService_AddReaction_FullMethodName       = "/chat.MessageService/AddReaction"
+	MessageService_RemoveReaction_FullMethodName    = "/chat.MessageService/RemoveReaction"
+	MessageService_GetPinnedMessages_FullMethodName = "/chat.MessageService/GetPinnedMessages"
+	MessageService_SearchMessages_FullMethodName    = "/chat.MessageService/SearchMessages"
 )
 
 // MessageServiceClient is the client API for MessageService service.
@@ -305,8 +438,22 @@ type MessageServiceClient interface {
 	SendMessage(ctx context.Context, in *SendMessageRequest, opts ...grpc.CallOption) (*SendMessageResponse, error)
 	// 获取历史消息
 	GetHistory(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*HistoryResponse, error)
+	// 获取话题消息
+	GetThread(ctx context.Context, in *ThreadRequest, opts ...grpc.CallOption) (*ThreadResponse, error)
 	// 批量获取消息
 	BatchGetMessages(ctx context.Context, in *BatchGetMessagesRequest, opts ...grpc.CallOption) (*BatchGetMessagesResponse, error)
+	// 编辑消息（仅作者）
+	EditMessage(ctx context.Context, in *EditMessageRequest, opts ...grpc.CallOption) (*EditMessageResponse, error)
+	// 删除消息（仅作者，软删除）
+	DeleteMessage(ctx context.Context, in *DeleteMessageRequest, opts ...grpc.CallOption) (*DeleteMessageResponse, error)
+	// 添加表情回应
+	AddReaction(ctx context.Context, in *ReactionRequest, opts ...grpc.CallOption) (*ReactionResponse, error)
+	// 移除表情回应
+	RemoveReaction(ctx context.Context, in *ReactionRequest, opts ...grpc.CallOption) (*ReactionResponse, error)
+	// 获取 Guild 的置顶消息
+	GetPinnedMessages(ctx context.Context, in *PinnedMessagesRequest, opts ...grpc.CallOption) (*PinnedMessagesResponse, error)
+	// 在 Guild 内全文检索消息
+	SearchMessages(ctx context.Context, in *SearchMessagesRequest, opts ...grpc.CallOption) (*SearchMessagesResponse, error)
 }
 
 type messageServiceClient struct {
@@ -337,6 +484,16 @@ func (c *messageServiceClient) GetHistory(ctx context.Context, in *HistoryReques
 	return out, nil
 }
 
+func (c *messageServiceClient) GetThread(ctx context.Context, in *ThreadRequest, opts ...grpc.CallOption) (*ThreadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ThreadResponse)
+	err := c.cc.Invoke(ctx, MessageService_GetThread_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *messageServiceClient) BatchGetMessages(ctx context.Context, in *BatchGetMessagesRequest, opts ...grpc.CallOption) (*BatchGetMessagesResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(BatchGetMessagesResponse)
@@ -347,6 +504,66 @@ func (c *messageServiceClient) BatchGetMessages(ctx context.Context, in *BatchGe
 	return out, nil
 }
 
+func (c *messageServiceClient) EditMessage(ctx context.Context, in *EditMessageRequest, opts ...grpc.CallOption) (*EditMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EditMessageResponse)
+	err := c.cc.Invoke(ctx, MessageService_EditMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) DeleteMessage(ctx context.Context, in *DeleteMessageRequest, opts ...grpc.CallOption) (*DeleteMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteMessageResponse)
+	err := c.cc.Invoke(ctx, MessageService_DeleteMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) AddReaction(ctx context.Context, in *ReactionRequest, opts ...grpc.CallOption) (*ReactionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReactionResponse)
+	err := c.cc.Invoke(ctx, MessageService_AddReaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) RemoveReaction(ctx context.Context, in *ReactionRequest, opts ...grpc.CallOption) (*ReactionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReactionResponse)
+	err := c.cc.Invoke(ctx, MessageService_RemoveReaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) GetPinnedMessages(ctx context.Context, in *PinnedMessagesRequest, opts ...grpc.CallOption) (*PinnedMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PinnedMessagesResponse)
+	err := c.cc.Invoke(ctx, MessageService_GetPinnedMessages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) SearchMessages(ctx context.Context, in *SearchMessagesRequest, opts ...grpc.CallOption) (*SearchMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchMessagesResponse)
+	err := c.cc.Invoke(ctx, MessageService_SearchMessages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MessageServiceServer is the server API for MessageService service.
 // All implementations must embed UnimplementedMessageServiceServer
 // for forward compatibility.
@@ -358,8 +575,22 @@ type MessageServiceServer interface {
 	SendMessage(context.Context, *SendMessageRequest) (*SendMessageResponse, error)
 	// 获取历史消息
 	GetHistory(context.Context, *HistoryRequest) (*HistoryResponse, error)
+	// 获取话题消息
+	GetThread(context.Context, *ThreadRequest) (*ThreadResponse, error)
 	// 批量获取消息
 	BatchGetMessages(context.Context, *BatchGetMessagesRequest) (*BatchGetMessagesResponse, error)
+	// 编辑消息（仅作者）
+	EditMessage(context.Context, *EditMessageRequest) (*EditMessageResponse, error)
+	// 删除消息（仅作者，软删除）
+	DeleteMessage(context.Context, *DeleteMessageRequest) (*DeleteMessageResponse, error)
+	// 添加表情回应
+	AddReaction(context.Context, *ReactionRequest) (*ReactionResponse, error)
+	// 移除表情回应
+	RemoveReaction(context.Context, *ReactionRequest) (*ReactionResponse, error)
+	// 获取 Guild 的置顶消息
+	GetPinnedMessages(context.Context, *PinnedMessagesRequest) (*PinnedMessagesResponse, error)
+	// 在 Guild 内全文检索消息
+	SearchMessages(context.Context, *SearchMessagesRequest) (*SearchMessagesResponse, error)
 	mustEmbedUnimplementedMessageServiceServer()
 }
 
@@ -376,9 +607,30 @@ func (UnimplementedMessageServiceServer) SendMessage(context.Context, *SendMessa
 func (UnimplementedMessageServiceServer) GetHistory(context.Context, *HistoryRequest) (*HistoryResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetHistory not implemented")
 }
+func (UnimplementedMessageServiceServer) GetThread(context.Context, *ThreadRequest) (*ThreadResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetThread not implemented")
+}
 func (UnimplementedMessageServiceServer) BatchGetMessages(context.Context, *BatchGetMessagesRequest) (*BatchGetMessagesResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method BatchGetMessages not implemented")
 }
+func (UnimplementedMessageServiceServer) EditMessage(context.Context, *EditMessageRequest) (*EditMessageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EditMessage not implemented")
+}
+func (UnimplementedMessageServiceServer) DeleteMessage(context.Context, *DeleteMessageRequest) (*DeleteMessageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteMessage not implemented")
+}
+func (UnimplementedMessageServiceServer) AddReaction(context.Context, *ReactionRequest) (*ReactionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddReaction not implemented")
+}
+func (UnimplementedMessageServiceServer) RemoveReaction(context.Context, *ReactionRequest) (*ReactionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RemoveReaction not implemented")
+}
+func (UnimplementedMessageServiceServer) GetPinnedMessages(context.Context, *PinnedMessagesRequest) (*PinnedMessagesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetPinnedMessages not implemented")
+}
+func (UnimplementedMessageServiceServer) SearchMessages(context.Context, *SearchMessagesRequest) (*SearchMessagesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SearchMessages not implemented")
+}
 func (UnimplementedMessageServiceServer) mustEmbedUnimplementedMessageServiceServer() {}
 func (UnimplementedMessageServiceServer) testEmbeddedByValue()                        {}
 
@@ -436,6 +688,24 @@ func _MessageService_GetHistory_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MessageService_GetThread_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ThreadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).GetThread(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_GetThread_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).GetThread(ctx, req.(*ThreadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _MessageService_BatchGetMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(BatchGetMessagesRequest)
 	if err := dec(in); err != nil {
@@ -454,6 +724,114 @@ func _MessageService_BatchGetMessages_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MessageService_EditMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EditMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).EditMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_EditMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).EditMessage(ctx, req.(*EditMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_DeleteMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).DeleteMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_DeleteMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).DeleteMessage(ctx, req.(*DeleteMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_AddReaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).AddReaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_AddReaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).AddReaction(ctx, req.(*ReactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_RemoveReaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).RemoveReaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_RemoveReaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).RemoveReaction(ctx, req.(*ReactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_GetPinnedMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PinnedMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).GetPinnedMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_GetPinnedMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).GetPinnedMessages(ctx, req.(*PinnedMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_SearchMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).SearchMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_SearchMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).SearchMessages(ctx, req.(*SearchMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MessageService_ServiceDesc is the grpc.ServiceDesc for MessageService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -469,10 +847,38 @@ var MessageService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetHistory",
 			Handler:    _MessageService_GetHistory_Handler,
 		},
+		{
+			MethodName: "GetThread",
+			Handler:    _MessageService_GetThread_Handler,
+		},
 		{
 			MethodName: "BatchGetMessages",
 			Handler:    _MessageService_BatchGetMessages_Handler,
 		},
+		{
+			MethodName: "EditMessage",
+			Handler:    _MessageService_EditMessage_Handler,
+		},
+		{
+			MethodName: "DeleteMessage",
+			Handler:    _MessageService_DeleteMessage_Handler,
+		},
+		{
+			MethodName: "AddReaction",
+			Handler:    _MessageService_AddReaction_Handler,
+		},
+		{
+			MethodName: "RemoveReaction",
+			Handler:    _MessageService_RemoveReaction_Handler,
+		},
+		{
+			MethodName: "GetPinnedMessages",
+			Handler:    _MessageService_GetPinnedMessages_Handler,
+		},
+		{
+			MethodName: "SearchMessages",
+			Handler:    _MessageService_SearchMessages_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/pkg/proto/service.proto",
@@ -2,7 +2,9 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	redis "github.com/redis/go-redis/v9"
@@ -15,19 +17,80 @@ type RedisClient interface {
 	GetClient() *redis.Client
 	Ping(ctx context.Context) error
 	GenerateSeqID(ctx context.Context, guildID string) (int64, error)
-	SetUserOnline(ctx context.Context, userID string, gatewayID string, ttl time.Duration) error
+	GenerateChannelSeqID(ctx context.Context, channelID string) (int64, error)
+	GenerateConversationSeqID(ctx context.Context, conversationID string) (int64, error)
+	SetUserSessions(ctx context.Context, userID string, nodeID string, count int, ttl time.Duration) error
 	IsUserOnline(ctx context.Context, userID string) (bool, error)
-	GetUserOnlineStatus(ctx context.Context, userID string) (string, error)
-	RemoveUserOnline(ctx context.Context, userID string) error
+	GetUserSessions(ctx context.Context, userID string) (map[string]int, error)
 	Publish(ctx context.Context, channel string, message any) error
 	Subscribe(ctx context.Context, channels ...string) (*redis.PubSub, error)
 	PSubscribe(ctx context.Context, patterns ...string) (*redis.PubSub, error)
 	Set(ctx context.Context, key string, value any, expiration time.Duration) error
+	SetNX(ctx context.Context, key string, value any, expiration time.Duration) (bool, error)
 	Get(ctx context.Context, key string) (string, error)
+	GetDel(ctx context.Context, key string) (string, error)
 	Del(ctx context.Context, keys ...string) error
 	Exists(ctx context.Context, keys ...string) (int64, error)
+	GetGuildSeqIDs(ctx context.Context, guildIDs []string) (map[string]int64, error)
+	GetChannelSeqIDs(ctx context.Context, channelIDs []string) (map[string]int64, error)
+	AdvanceReadState(ctx context.Context, userID, timeline string, seqID int64) (bool, error)
+	GetReadStates(ctx context.Context, userID string) (map[string]int64, error)
+	PopDirtyReadStates(ctx context.Context, count int64) ([]string, error)
+	MarkReadStatesDirty(ctx context.Context, userIDs ...string) error
+	SetPresence(ctx context.Context, userID string, presence map[string]string, ttl time.Duration) error
+	RefreshPresence(ctx context.Context, userIDs []string, ttl time.Duration) error
+	GetPresence(ctx context.Context, userID string) (map[string]string, error)
+	RegisterGatewayNode(ctx context.Context, node *GatewayNode, ttl time.Duration) error
+	GetGatewayNode(ctx context.Context, nodeID string) (*GatewayNode, error)
+	GetGatewayNodes(ctx context.Context) ([]*GatewayNode, error)
+	RemoveGatewayNode(ctx context.Context, nodeID string) error
+	ClaimMessageNonce(ctx context.Context, userID, nonce, messageID string, ttl time.Duration) (string, error)
+	ReleaseMessageNonce(ctx context.Context, userID, nonce, messageID string) error
 }
 
+// GatewayNode 注册在 Redis 中的 Gateway 节点
+type GatewayNode struct {
+	ID          string
+	Address     string    // gRPC 地址，其他节点通过它推送消息
+	Connections int       // 节点上的连接数
+	Heartbeat   time.Time // 最近一次心跳时间
+}
+
+// readStateDirtyKey 记录已读位置有变化、等待落库的用户
+const readStateDirtyKey = "read_state:dirty"
+
+// gatewayNodesKey 记录注册过的 Gateway 节点 ID，节点信息存放在各自带 TTL 的 Hash 中
+const gatewayNodesKey = "gateway:nodes"
+
+// advanceReadStateScript 只在新位置更大时更新已读位置，并将用户加入待落库集合
+var advanceReadStateScript = redis.NewScript(`
+local current = tonumber(redis.call('HGET', KEYS[1], ARGV[1]) or '0')
+if tonumber(ARGV[2]) <= current then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+redis.call('SADD', KEYS[2], ARGV[3])
+return 1
+`)
+
+// claimMessageNonceScript 原子地占用消息 nonce，已被占用时返回占用者的消息 ID
+var claimMessageNonceScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current then
+	return current
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return ARGV[1]
+`)
+
+// releaseMessageNonceScript 只在 nonce 仍由该消息占用时释放
+var releaseMessageNonceScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
 type Client struct {
 	client *redis.Client
 	config *config.RedisConfig
@@ -76,40 +139,100 @@ func (c *Client) GenerateSeqID(ctx context.Context, guildID string) (int64, erro
 	return result, nil
 }
 
-func (c *Client) SetUserOnline(ctx context.Context, userID string, gatewayID string, ttl time.Duration) error {
-	key := fmt.Sprintf("user:%s:online", userID)
-	err := c.client.Set(ctx, key, gatewayID, ttl).Err()
+func (c *Client) GenerateChannelSeqID(ctx context.Context, channelID string) (int64, error) {
+	key := fmt.Sprintf("channel:%s:seq_id", channelID)
+	result, err := c.client.Incr(ctx, key).Result()
 	if err != nil {
-		return fmt.Errorf("failed to set user %s online: %w", userID, err)
+		return 0, fmt.Errorf("failed to generate seq id for channel %s: %w", channelID, err)
+	}
+	return result, nil
+}
+
+func (c *Client) GenerateConversationSeqID(ctx context.Context, conversationID string) (int64, error) {
+	key := fmt.Sprintf("dm:%s:seq_id", conversationID)
+	result, err := c.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate seq id for conversation %s: %w", conversationID, err)
+	}
+	return result, nil
+}
+
+func userNodesKey(userID string) string {
+	return fmt.Sprintf("user:%s:nodes", userID)
+}
+
+func userSessionsKey(userID, nodeID string) string {
+	return fmt.Sprintf("user:%s:online:%s", userID, nodeID)
+}
+
+// SetUserSessions 记录用户在某个 Gateway 节点上的会话数，count 为 0 时移除该节点
+// 每个节点的会话数存放在各自带 TTL 的 Key 中，节点宕机停止续期后随 TTL 过期，不受其他节点续期影响
+func (c *Client) SetUserSessions(ctx context.Context, userID string, nodeID string, count int, ttl time.Duration) error {
+	pipe := c.client.TxPipeline()
+	if count > 0 {
+		pipe.Set(ctx, userSessionsKey(userID, nodeID), count, ttl)
+		pipe.SAdd(ctx, userNodesKey(userID), nodeID)
+		pipe.Expire(ctx, userNodesKey(userID), ttl)
+	} else {
+		pipe.Del(ctx, userSessionsKey(userID, nodeID))
+		pipe.SRem(ctx, userNodesKey(userID), nodeID)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set sessions of user %s: %w", userID, err)
 	}
 	return nil
 }
 
+// IsUserOnline 判断用户是否在任一 Gateway 节点上有会话
 func (c *Client) IsUserOnline(ctx context.Context, userID string) (bool, error) {
-	key := fmt.Sprintf("user:%s:online", userID)
-	result, err := c.client.Exists(ctx, key).Result()
+	sessions, err := c.GetUserSessions(ctx, userID)
 	if err != nil {
 		return false, fmt.Errorf("failed to check if user %s is online: %w", userID, err)
 	}
-	return result > 0, nil
+	return len(sessions) > 0, nil
 }
 
-func (c *Client) GetUserOnlineStatus(ctx context.Context, userID string) (string, error) {
-	key := fmt.Sprintf("user:%s:online", userID)
-	result, err := c.client.Get(ctx, key).Result()
+// GetUserSessions 读取用户在各 Gateway 节点上的会话数，并清理已过期节点的 ID
+func (c *Client) GetUserSessions(ctx context.Context, userID string) (map[string]int, error) {
+	nodeIDs, err := c.client.SMembers(ctx, userNodesKey(userID)).Result()
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to get sessions of user %s: %w", userID, err)
+	}
+	if len(nodeIDs) == 0 {
+		return map[string]int{}, nil
 	}
-	return result, nil
-}
 
-func (c *Client) RemoveUserOnline(ctx context.Context, userID string) error {
-	key := fmt.Sprintf("user:%s:online", userID)
-	err := c.client.Del(ctx, key).Err()
-	if err != nil {
-		return fmt.Errorf("failed to remove user %s online status: %w", userID, err)
+	pipe := c.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(nodeIDs))
+	for i, nodeID := range nodeIDs {
+		cmds[i] = pipe.Get(ctx, userSessionsKey(userID, nodeID))
 	}
-	return nil
+	// 已过期的节点返回 redis.Nil，逐个检查
+	pipe.Exec(ctx)
+
+	result := make(map[string]int, len(nodeIDs))
+	var expired []any
+	for i, cmd := range cmds {
+		value, err := cmd.Result()
+		if errors.Is(err, redis.Nil) {
+			expired = append(expired, nodeIDs[i])
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sessions of user %s: %w", userID, err)
+		}
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid session count of user %s: %w", userID, err)
+		}
+		result[nodeIDs[i]] = count
+	}
+	if len(expired) > 0 {
+		if err := c.client.SRem(ctx, userNodesKey(userID), expired...).Err(); err != nil {
+			return nil, fmt.Errorf("failed to remove expired nodes of user %s: %w", userID, err)
+		}
+	}
+	return result, nil
 }
 
 func (c *Client) Publish(ctx context.Context, channel string, message any) error {
@@ -122,6 +245,10 @@ func (c *Client) Publish(ctx context.Context, channel string, message any) error
 
 func (c *Client) Subscribe(ctx context.Context, channels ...string) (*redis.PubSub, error) {
 	pubsub := c.client.Subscribe(ctx, channels...)
+	// Without channels nothing is sent, channels are subscribed later on the returned subscription
+	if len(channels) == 0 {
+		return pubsub, nil
+	}
 	// Wait for confirmation that subscription is created
 	_, err := pubsub.Receive(ctx)
 	if err != nil {
@@ -144,10 +271,19 @@ func (c *Client) Set(ctx context.Context, key string, value any, expiration time
 	return c.client.Set(ctx, key, value, expiration).Err()
 }
 
+func (c *Client) SetNX(ctx context.Context, key string, value any, expiration time.Duration) (bool, error) {
+	return c.client.SetNX(ctx, key, value, expiration).Result()
+}
+
 func (c *Client) Get(ctx context.Context, key string) (string, error) {
 	return c.client.Get(ctx, key).Result()
 }
 
+// GetDel 读取并删除 key，用于只能使用一次的值
+func (c *Client) GetDel(ctx context.Context, key string) (string, error) {
+	return c.client.GetDel(ctx, key).Result()
+}
+
 func (c *Client) Del(ctx context.Context, keys ...string) error {
 	return c.client.Del(ctx, keys...).Err()
 }
@@ -155,3 +291,256 @@ func (c *Client) Del(ctx context.Context, keys ...string) error {
 func (c *Client) Exists(ctx context.Context, keys ...string) (int64, error) {
 	return c.client.Exists(ctx, keys...).Result()
 }
+
+// GetGuildSeqIDs 批量读取 Guild 默认时间线当前的 Seq ID，没有消息的 Guild 为 0
+func (c *Client) GetGuildSeqIDs(ctx context.Context, guildIDs []string) (map[string]int64, error) {
+	keys := make([]string, len(guildIDs))
+	for i, guildID := range guildIDs {
+		keys[i] = fmt.Sprintf("guild:%s:seq_id", guildID)
+	}
+	return c.getSeqIDs(ctx, guildIDs, keys)
+}
+
+// GetChannelSeqIDs 批量读取频道当前的 Seq ID，没有消息的频道为 0
+func (c *Client) GetChannelSeqIDs(ctx context.Context, channelIDs []string) (map[string]int64, error) {
+	keys := make([]string, len(channelIDs))
+	for i, channelID := range channelIDs {
+		keys[i] = fmt.Sprintf("channel:%s:seq_id", channelID)
+	}
+	return c.getSeqIDs(ctx, channelIDs, keys)
+}
+
+func (c *Client) getSeqIDs(ctx context.Context, ids, keys []string) (map[string]int64, error) {
+	result := make(map[string]int64, len(ids))
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	values, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seq ids: %w", err)
+	}
+	for i, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+		seqID, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seq id for %s: %w", ids[i], err)
+		}
+		result[ids[i]] = seqID
+	}
+	return result, nil
+}
+
+// AdvanceReadState 前移用户在某条时间线上的已读位置，返回位置是否发生变化
+// 已读位置只增不减，变化后的用户会被定期落库到 PostgreSQL
+func (c *Client) AdvanceReadState(ctx context.Context, userID, timeline string, seqID int64) (bool, error) {
+	key := fmt.Sprintf("read_state:%s", userID)
+	result, err := advanceReadStateScript.Run(ctx, c.client, []string{key, readStateDirtyKey}, timeline, seqID, userID).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to advance read state for user %s: %w", userID, err)
+	}
+	return result == 1, nil
+}
+
+// GetReadStates 读取用户在所有时间线上的已读位置
+func (c *Client) GetReadStates(ctx context.Context, userID string) (map[string]int64, error) {
+	key := fmt.Sprintf("read_state:%s", userID)
+	values, err := c.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get read states for user %s: %w", userID, err)
+	}
+
+	result := make(map[string]int64, len(values))
+	for timeline, value := range values {
+		seqID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid read state for user %s: %w", userID, err)
+		}
+		result[timeline] = seqID
+	}
+	return result, nil
+}
+
+// PopDirtyReadStates 取出至多 count 个已读位置待落库的用户
+func (c *Client) PopDirtyReadStates(ctx context.Context, count int64) ([]string, error) {
+	userIDs, err := c.client.SPopN(ctx, readStateDirtyKey, count).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to pop dirty read states: %w", err)
+	}
+	return userIDs, nil
+}
+
+// MarkReadStatesDirty 将用户重新加入待落库集合，用于落库失败后重试
+func (c *Client) MarkReadStatesDirty(ctx context.Context, userIDs ...string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	members := make([]any, len(userIDs))
+	for i, userID := range userIDs {
+		members[i] = userID
+	}
+	if err := c.client.SAdd(ctx, readStateDirtyKey, members...).Err(); err != nil {
+		return fmt.Errorf("failed to mark read states dirty: %w", err)
+	}
+	return nil
+}
+
+// SetPresence 保存用户当前对外可见的在线状态，在线用户所在节点定期续期，节点宕机后随 TTL 过期
+func (c *Client) SetPresence(ctx context.Context, userID string, presence map[string]string, ttl time.Duration) error {
+	key := fmt.Sprintf("presence:%s", userID)
+	pipe := c.client.TxPipeline()
+	pipe.HSet(ctx, key, presence)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set presence for user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// RefreshPresence 续期用户的在线状态
+func (c *Client) RefreshPresence(ctx context.Context, userIDs []string, ttl time.Duration) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	pipe := c.client.Pipeline()
+	for _, userID := range userIDs {
+		pipe.Expire(ctx, fmt.Sprintf("presence:%s", userID), ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to refresh presence of %d users: %w", len(userIDs), err)
+	}
+	return nil
+}
+
+// GetPresence 读取用户当前对外可见的在线状态，不存在时返回空 map
+func (c *Client) GetPresence(ctx context.Context, userID string) (map[string]string, error) {
+	key := fmt.Sprintf("presence:%s", userID)
+	presence, err := c.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get presence for user %s: %w", userID, err)
+	}
+	return presence, nil
+}
+
+func gatewayNodeKey(nodeID string) string {
+	return fmt.Sprintf("gateway:node:%s", nodeID)
+}
+
+// RegisterGatewayNode 注册或续期 Gateway 节点，节点停止心跳后随 TTL 过期
+func (c *Client) RegisterGatewayNode(ctx context.Context, node *GatewayNode, ttl time.Duration) error {
+	key := gatewayNodeKey(node.ID)
+	pipe := c.client.TxPipeline()
+	pipe.HSet(ctx, key, map[string]any{
+		"address":     node.Address,
+		"connections": node.Connections,
+		"heartbeat":   node.Heartbeat.UnixMilli(),
+	})
+	pipe.Expire(ctx, key, ttl)
+	pipe.SAdd(ctx, gatewayNodesKey, node.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to register gateway node %s: %w", node.ID, err)
+	}
+	return nil
+}
+
+// GetGatewayNode 读取 Gateway 节点，节点不存在或已过期时返回 nil
+func (c *Client) GetGatewayNode(ctx context.Context, nodeID string) (*GatewayNode, error) {
+	values, err := c.client.HGetAll(ctx, gatewayNodeKey(nodeID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gateway node %s: %w", nodeID, err)
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return parseGatewayNode(nodeID, values)
+}
+
+// GetGatewayNodes 列出存活的 Gateway 节点，并清理已过期节点的 ID
+func (c *Client) GetGatewayNodes(ctx context.Context) ([]*GatewayNode, error) {
+	nodeIDs, err := c.client.SMembers(ctx, gatewayNodesKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list gateway nodes: %w", err)
+	}
+
+	pipe := c.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(nodeIDs))
+	for i, nodeID := range nodeIDs {
+		cmds[i] = pipe.HGetAll(ctx, gatewayNodeKey(nodeID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get gateway nodes: %w", err)
+	}
+
+	nodes := make([]*GatewayNode, 0, len(nodeIDs))
+	var expired []any
+	for i, cmd := range cmds {
+		if len(cmd.Val()) == 0 {
+			expired = append(expired, nodeIDs[i])
+			continue
+		}
+		node, err := parseGatewayNode(nodeIDs[i], cmd.Val())
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if len(expired) > 0 {
+		if err := c.client.SRem(ctx, gatewayNodesKey, expired...).Err(); err != nil {
+			return nil, fmt.Errorf("failed to remove expired gateway nodes: %w", err)
+		}
+	}
+	return nodes, nil
+}
+
+// RemoveGatewayNode 注销 Gateway 节点
+func (c *Client) RemoveGatewayNode(ctx context.Context, nodeID string) error {
+	pipe := c.client.TxPipeline()
+	pipe.Del(ctx, gatewayNodeKey(nodeID))
+	pipe.SRem(ctx, gatewayNodesKey, nodeID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to remove gateway node %s: %w", nodeID, err)
+	}
+	return nil
+}
+
+func parseGatewayNode(nodeID string, values map[string]string) (*GatewayNode, error) {
+	connections, err := strconv.Atoi(values["connections"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection count of gateway node %s: %w", nodeID, err)
+	}
+	heartbeat, err := strconv.ParseInt(values["heartbeat"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid heartbeat of gateway node %s: %w", nodeID, err)
+	}
+	return &GatewayNode{
+		ID:          nodeID,
+		Address:     values["address"],
+		Connections: connections,
+		Heartbeat:   time.UnixMilli(heartbeat),
+	}, nil
+}
+
+func messageNonceKey(userID, nonce string) string {
+	return fmt.Sprintf("message:nonce:%s:%s", userID, nonce)
+}
+
+// ClaimMessageNonce 为消息占用用户的 nonce，在 TTL 内同一用户重复使用该 nonce 时返回最先占用的消息 ID
+// 返回值等于 messageID 表示占用成功
+func (c *Client) ClaimMessageNonce(ctx context.Context, userID, nonce, messageID string, ttl time.Duration) (string, error) {
+	owner, err := claimMessageNonceScript.Run(ctx, c.client, []string{messageNonceKey(userID, nonce)}, messageID, ttl.Milliseconds()).Text()
+	if err != nil {
+		return "", fmt.Errorf("failed to claim message nonce for user %s: %w", userID, err)
+	}
+	return owner, nil
+}
+
+// ReleaseMessageNonce 释放消息占用的 nonce，消息发送失败后客户端可以用同一 nonce 重试
+func (c *Client) ReleaseMessageNonce(ctx context.Context, userID, nonce, messageID string) error {
+	if err := releaseMessageNonceScript.Run(ctx, c.client, []string{messageNonceKey(userID, nonce)}, messageID).Err(); err != nil {
+		return fmt.Errorf("failed to release message nonce for user %s: %w", userID, err)
+	}
+	return nil
+}
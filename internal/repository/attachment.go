@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/Gopher0727/ChatRoom/internal/model"
+)
+
+// errPartialLink rolls back a link that did not cover every attachment
+var errPartialLink = errors.New("attachments cannot be linked")
+
+// IAttachmentRepository defines the interface for attachment data operations
+type IAttachmentRepository interface {
+	Create(ctx context.Context, attachment *model.Attachment) error
+	FindByID(ctx context.Context, id string) (*model.Attachment, error)
+	FindByMessages(ctx context.Context, messageIDs []string) ([]*model.Attachment, error)
+	LinkToMessage(ctx context.Context, ids []string, uploaderID, messageID string) (bool, error)
+	Unlink(ctx context.Context, messageID string) error
+}
+
+// AttachmentRepository implements IAttachmentRepository interface
+type AttachmentRepository struct {
+	db *gorm.DB
+}
+
+// NewAttachmentRepository creates a new IAttachmentRepository instance
+func NewAttachmentRepository(db *gorm.DB) IAttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+// Create stores the metadata of an uploaded attachment
+func (r *AttachmentRepository) Create(ctx context.Context, attachment *model.Attachment) error {
+	return r.db.WithContext(ctx).Create(attachment).Error
+}
+
+// FindByID finds an attachment by ID
+func (r *AttachmentRepository) FindByID(ctx context.Context, id string) (*model.Attachment, error) {
+	var attachment model.Attachment
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&attachment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// FindByMessages retrieves the attachments of several messages in upload order
+func (r *AttachmentRepository) FindByMessages(ctx context.Context, messageIDs []string) ([]*model.Attachment, error) {
+	var attachments []*model.Attachment
+	err := r.db.WithContext(ctx).
+		Where("message_id IN ?", messageIDs).
+		Order("created_at ASC").
+		Find(&attachments).Error
+	if err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// LinkToMessage binds unlinked attachments of the uploader to a message
+// Either every attachment is linked or none is, it reports false if any of them cannot be linked
+func (r *AttachmentRepository) LinkToMessage(ctx context.Context, ids []string, uploaderID, messageID string) (bool, error) {
+	linked := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Attachment{}).
+			Where("id IN ? AND uploader_id = ? AND message_id = ''", ids, uploaderID).
+			Update("message_id", messageID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(ids)) {
+			return errPartialLink
+		}
+		linked = true
+		return nil
+	})
+	if err != nil && !errors.Is(err, errPartialLink) {
+		return false, err
+	}
+	return linked, nil
+}
+
+// Unlink releases the attachments of a message that could not be stored
+func (r *AttachmentRepository) Unlink(ctx context.Context, messageID string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.Attachment{}).
+		Where("message_id = ?", messageID).
+		Update("message_id", "").Error
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/Gopher0727/ChatRoom/internal/model"
+)
+
+// IChannelRepository defines the interface for channel data operations
+type IChannelRepository interface {
+	Create(ctx context.Context, channel *model.Channel) error
+	FindByID(ctx context.Context, id string) (*model.Channel, error)
+	FindByGuild(ctx context.Context, guildID string) ([]*model.Channel, error)
+	FindByGuildIDs(ctx context.Context, guildIDs []string) ([]*model.Channel, error)
+	CountByGuild(ctx context.Context, guildID string) (int64, error)
+	Update(ctx context.Context, channel *model.Channel) error
+	UpdatePositions(ctx context.Context, guildID string, channelIDs []string) error
+	Delete(ctx context.Context, id string) error
+}
+
+// ChannelRepository implements IChannelRepository interface
+type ChannelRepository struct {
+	db *gorm.DB
+}
+
+// NewChannelRepository creates a new IChannelRepository instance
+func NewChannelRepository(db *gorm.DB) IChannelRepository {
+	return &ChannelRepository{db: db}
+}
+
+// Create creates a new channel in the database
+func (r *ChannelRepository) Create(ctx context.Context, channel *model.Channel) error {
+	return r.db.WithContext(ctx).Create(channel).Error
+}
+
+// FindByID finds a channel by ID
+func (r *ChannelRepository) FindByID(ctx context.Context, id string) (*model.Channel, error) {
+	var channel model.Channel
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&channel).Error
+	if err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+// FindByGuild retrieves all channels of a guild in display order
+func (r *ChannelRepository) FindByGuild(ctx context.Context, guildID string) ([]*model.Channel, error) {
+	var channels []*model.Channel
+	err := r.db.WithContext(ctx).
+		Where("guild_id = ?", guildID).
+		Order("position ASC, created_at ASC").
+		Find(&channels).Error
+	if err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+// FindByGuildIDs retrieves the channels of several guilds
+func (r *ChannelRepository) FindByGuildIDs(ctx context.Context, guildIDs []string) ([]*model.Channel, error) {
+	var channels []*model.Channel
+	if len(guildIDs) == 0 {
+		return channels, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("guild_id IN ?", guildIDs).
+		Find(&channels).Error
+	if err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+// CountByGuild counts the channels of a guild
+func (r *ChannelRepository) CountByGuild(ctx context.Context, guildID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.Channel{}).
+		Where("guild_id = ?", guildID).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Update saves changes to a channel
+func (r *ChannelRepository) Update(ctx context.Context, channel *model.Channel) error {
+	return r.db.WithContext(ctx).Save(channel).Error
+}
+
+// UpdatePositions sets each channel's position to its index in channelIDs within a single transaction
+func (r *ChannelRepository) UpdatePositions(ctx context.Context, guildID string, channelIDs []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for position, id := range channelIDs {
+			err := tx.Model(&model.Channel{}).
+				Where("id = ? AND guild_id = ?", id, guildID).
+				Update("position", position).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes a channel
+func (r *ChannelRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Channel{}).Error
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/Gopher0727/ChatRoom/internal/model"
+)
+
+// IDMRepository defines the interface for direct message conversation data operations
+type IDMRepository interface {
+	Create(ctx context.Context, conversation *model.DMConversation, participantIDs []string) error
+	FindByID(ctx context.Context, id string) (*model.DMConversation, error)
+	FindByDirectKey(ctx context.Context, key string) (*model.DMConversation, error)
+	FindByUser(ctx context.Context, userID string) ([]*model.DMConversation, error)
+	GetParticipantIDs(ctx context.Context, conversationID string) ([]string, error)
+	IsParticipant(ctx context.Context, conversationID, userID string) (bool, error)
+}
+
+// DMRepository implements IDMRepository interface
+type DMRepository struct {
+	db *gorm.DB
+}
+
+// NewDMRepository creates a new IDMRepository instance
+func NewDMRepository(db *gorm.DB) IDMRepository {
+	return &DMRepository{db: db}
+}
+
+// Create stores a conversation together with its participants in a single transaction
+func (r *DMRepository) Create(ctx context.Context, conversation *model.DMConversation, participantIDs []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(conversation).Error; err != nil {
+			return err
+		}
+		participants := make([]*model.DMParticipant, len(participantIDs))
+		for i, userID := range participantIDs {
+			participants[i] = &model.DMParticipant{
+				ConversationID: conversation.ID,
+				UserID:         userID,
+			}
+		}
+		return tx.Create(&participants).Error
+	})
+}
+
+// FindByID finds a conversation by ID
+func (r *DMRepository) FindByID(ctx context.Context, id string) (*model.DMConversation, error) {
+	var conversation model.DMConversation
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&conversation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// FindByDirectKey finds the 1:1 conversation between two users
+func (r *DMRepository) FindByDirectKey(ctx context.Context, key string) (*model.DMConversation, error) {
+	var conversation model.DMConversation
+	err := r.db.WithContext(ctx).Where("direct_key = ?", key).First(&conversation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// FindByUser retrieves all conversations a user participates in, most recently updated first
+func (r *DMRepository) FindByUser(ctx context.Context, userID string) ([]*model.DMConversation, error) {
+	var conversations []*model.DMConversation
+	err := r.db.WithContext(ctx).
+		Table("dm_conversations").
+		Joins("JOIN dm_participants ON dm_conversations.id = dm_participants.conversation_id").
+		Where("dm_participants.user_id = ?", userID).
+		Order("dm_conversations.updated_at DESC").
+		Find(&conversations).Error
+	if err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
+
+// GetParticipantIDs retrieves the user IDs of a conversation's participants
+func (r *DMRepository) GetParticipantIDs(ctx context.Context, conversationID string) ([]string, error) {
+	var userIDs []string
+	err := r.db.WithContext(ctx).
+		Model(&model.DMParticipant{}).
+		Where("conversation_id = ?", conversationID).
+		Order("joined_at ASC").
+		Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// IsParticipant checks if a user participates in a conversation
+func (r *DMRepository) IsParticipant(ctx context.Context, conversationID, userID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.DMParticipant{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
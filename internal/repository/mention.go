@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Gopher0727/ChatRoom/internal/model"
+)
+
+// IMentionRepository defines the interface for mention data operations
+type IMentionRepository interface {
+	CreateBatch(ctx context.Context, mentions []*model.Mention) error
+	ReplaceForMessage(ctx context.Context, messageID string, mentions []*model.Mention) error
+	FindMessagesByUser(ctx context.Context, userID string, before time.Time, limit int) ([]*model.Message, error)
+	FindUnreadPositions(ctx context.Context, userID string, guildIDs []string) ([]*model.MentionPosition, error)
+}
+
+// MentionRepository implements IMentionRepository interface
+type MentionRepository struct {
+	db *gorm.DB
+}
+
+// NewMentionRepository creates a new IMentionRepository instance
+func NewMentionRepository(db *gorm.DB) IMentionRepository {
+	return &MentionRepository{db: db}
+}
+
+// CreateBatch stores mention rows, rows that already exist are skipped
+func (r *MentionRepository) CreateBatch(ctx context.Context, mentions []*model.Mention) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(mentions, 500).Error
+}
+
+// ReplaceForMessage replaces the mention rows of a message within a single transaction
+func (r *MentionRepository) ReplaceForMessage(ctx context.Context, messageID string, mentions []*model.Mention) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("message_id = ?", messageID).Delete(&model.Mention{}).Error; err != nil {
+			return err
+		}
+		if len(mentions) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(mentions, 500).Error
+	})
+}
+
+// FindMessagesByUser retrieves messages mentioning a user, newest first, created strictly before the cursor
+// A zero cursor starts from the most recent mention, deleted messages are skipped
+func (r *MentionRepository) FindMessagesByUser(ctx context.Context, userID string, before time.Time, limit int) ([]*model.Message, error) {
+	var messages []*model.Message
+
+	query := r.db.WithContext(ctx).
+		Joins("JOIN mentions ON mentions.message_id = messages.id").
+		Where("mentions.user_id = ?", userID)
+	if !before.IsZero() {
+		query = query.Where("mentions.created_at < ?", before)
+	}
+	err := query.Order("mentions.created_at DESC").Limit(limit).Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// FindUnreadPositions retrieves where the user was mentioned in the given guilds after the stored read positions
+// Stored read positions can lag behind the live ones, so callers must filter the result again
+func (r *MentionRepository) FindUnreadPositions(ctx context.Context, userID string, guildIDs []string) ([]*model.MentionPosition, error) {
+	var positions []*model.MentionPosition
+	err := r.db.WithContext(ctx).
+		Table("mentions").
+		Select("messages.guild_id, messages.channel_id, messages.seq_id").
+		Joins("JOIN messages ON messages.id = mentions.message_id AND messages.deleted_at IS NULL").
+		Joins("LEFT JOIN read_states ON read_states.user_id = mentions.user_id AND read_states.guild_id = messages.guild_id AND read_states.channel_id = messages.channel_id").
+		Where("mentions.user_id = ? AND mentions.guild_id IN ?", userID, guildIDs).
+		Where("messages.seq_id > COALESCE(read_states.last_read_seq_id, 0)").
+		Scan(&positions).Error
+	if err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -10,8 +11,48 @@ import (
 
 type IMessageRepository interface {
 	Create(ctx context.Context, message *model.Message) error
-	FindByGuild(ctx context.Context, guildID string, afterSeqID int64, limit int) ([]*model.Message, error)
+	FindByGuild(ctx context.Context, guildID, channelID string, cursor SeqCursor, limit int) ([]*model.Message, error)
+	FindByConversation(ctx context.Context, conversationID string, cursor SeqCursor, limit int) ([]*model.Message, error)
 	FindByID(ctx context.Context, id string) (*model.Message, error)
+	FindByThread(ctx context.Context, rootID string, afterSeqID int64, limit int) ([]*model.Message, error)
+	Update(ctx context.Context, message *model.Message) error
+	Delete(ctx context.Context, id string) error
+	Search(ctx context.Context, filter *MessageSearchFilter) ([]*model.MessageSearchHit, error)
+}
+
+// SeqCursor selects one side of a position in a timeline
+// After returns seq_id > After oldest first, otherwise Before returns seq_id < Before newest first,
+// the zero cursor returns the latest messages newest first
+type SeqCursor struct {
+	Before int64
+	After  int64
+}
+
+// searchConfig is the PostgreSQL text search configuration used for message content
+// "simple" does no stemming, so it behaves the same for every language
+const searchConfig = "simple"
+
+// searchHeadlineOptions marks matched terms with \x02 and \x03 so that the caller can escape the snippet safely
+const searchHeadlineOptions = "StartSel=\x02, StopSel=\x03, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=\" ... \""
+
+// MessageSearchFilter narrows a full-text search to a guild and optional criteria
+type MessageSearchFilter struct {
+	GuildID   string
+	ChannelID string // empty searches every channel of the guild
+	Query     string // websearch syntax: "phrase", or, -exclude
+	AuthorID  string
+
+	After         *time.Time
+	Before        *time.Time
+	HasAttachment bool
+
+	Offset int
+	Limit  int
+}
+
+// MigrateMessageSearch creates the GIN index backing full-text search on message content
+func MigrateMessageSearch(db *gorm.DB) error {
+	return db.Exec("CREATE INDEX IF NOT EXISTS idx_messages_content_search ON messages USING GIN (to_tsvector('" + searchConfig + "', content))").Error
 }
 
 type MessageRepository struct {
@@ -26,13 +67,28 @@ func (r *MessageRepository) Create(ctx context.Context, message *model.Message)
 	return r.db.WithContext(ctx).Create(message).Error
 }
 
-func (r *MessageRepository) FindByGuild(ctx context.Context, guildID string, afterSeqID int64, limit int) ([]*model.Message, error) {
+// FindByGuild returns messages of one channel of a guild, an empty channelID selects the guild's default timeline
+func (r *MessageRepository) FindByGuild(ctx context.Context, guildID, channelID string, cursor SeqCursor, limit int) ([]*model.Message, error) {
+	query := r.db.WithContext(ctx).Where("guild_id = ? AND channel_id = ?", guildID, channelID)
+	return findBySeq(query, cursor, limit)
+}
+
+// FindByConversation returns messages of a direct message conversation
+func (r *MessageRepository) FindByConversation(ctx context.Context, conversationID string, cursor SeqCursor, limit int) ([]*model.Message, error) {
+	query := r.db.WithContext(ctx).Where("conversation_id = ?", conversationID)
+	return findBySeq(query, cursor, limit)
+}
+
+// findBySeq pages a timeline query from a seq_id cursor
+func findBySeq(query *gorm.DB, cursor SeqCursor, limit int) ([]*model.Message, error) {
 	var messages []*model.Message
 
-	query := r.db.WithContext(ctx).Where("guild_id = ?", guildID)
-	if afterSeqID > 0 {
-		query = query.Where("seq_id > ?", afterSeqID).Order("seq_id ASC")
-	} else {
+	switch {
+	case cursor.After > 0:
+		query = query.Where("seq_id > ?", cursor.After).Order("seq_id ASC")
+	case cursor.Before > 0:
+		query = query.Where("seq_id < ?", cursor.Before).Order("seq_id DESC")
+	default:
 		query = query.Order("seq_id DESC")
 	}
 	err := query.Limit(limit).Find(&messages).Error
@@ -49,3 +105,69 @@ func (r *MessageRepository) FindByID(ctx context.Context, id string) (*model.Mes
 	}
 	return &message, nil
 }
+
+// FindByThread returns the replies in a thread in ascending seq_id order, starting after afterSeqID
+func (r *MessageRepository) FindByThread(ctx context.Context, rootID string, afterSeqID int64, limit int) ([]*model.Message, error) {
+	var messages []*model.Message
+
+	err := r.db.WithContext(ctx).
+		Where("thread_root_id = ? AND seq_id > ?", rootID, afterSeqID).
+		Order("seq_id ASC").
+		Limit(limit).
+		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *MessageRepository) Update(ctx context.Context, message *model.Message) error {
+	return r.db.WithContext(ctx).Save(message).Error
+}
+
+// Delete soft-deletes a message, it will no longer be returned by queries
+func (r *MessageRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Message{}).Error
+}
+
+// Search runs a ranked full-text search over the messages of a guild
+func (r *MessageRepository) Search(ctx context.Context, filter *MessageSearchFilter) ([]*model.MessageSearchHit, error) {
+	vector := "to_tsvector('" + searchConfig + "', messages.content)"
+	tsquery := "websearch_to_tsquery('" + searchConfig + "', ?)"
+
+	query := r.db.WithContext(ctx).
+		Table("messages").
+		Select(
+			"messages.*, ts_rank("+vector+", "+tsquery+") AS rank, ts_headline('"+searchConfig+"', messages.content, "+tsquery+", ?) AS snippet",
+			filter.Query, filter.Query, searchHeadlineOptions,
+		).
+		Where("messages.guild_id = ? AND messages.deleted_at IS NULL", filter.GuildID).
+		Where(vector+" @@ "+tsquery, filter.Query)
+
+	if filter.ChannelID != "" {
+		query = query.Where("messages.channel_id = ?", filter.ChannelID)
+	}
+	if filter.AuthorID != "" {
+		query = query.Where("messages.user_id = ?", filter.AuthorID)
+	}
+	if filter.After != nil {
+		query = query.Where("messages.created_at >= ?", *filter.After)
+	}
+	if filter.Before != nil {
+		query = query.Where("messages.created_at < ?", *filter.Before)
+	}
+	if filter.HasAttachment {
+		query = query.Where("EXISTS (SELECT 1 FROM attachments WHERE attachments.message_id = messages.id)")
+	}
+
+	var hits []*model.MessageSearchHit
+	err := query.
+		Order("rank DESC, messages.created_at DESC").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Scan(&hits).Error
+	if err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Gopher0727/ChatRoom/internal/model"
+)
+
+// IPinRepository defines the interface for pinned message data operations
+type IPinRepository interface {
+	Add(ctx context.Context, pin *model.Pin) (bool, error)
+	Remove(ctx context.Context, guildID, messageID string) (bool, error)
+	CountByGuild(ctx context.Context, guildID string) (int64, error)
+	FindMessagesByGuild(ctx context.Context, guildID string) ([]*model.Message, error)
+}
+
+// PinRepository implements IPinRepository interface
+type PinRepository struct {
+	db *gorm.DB
+}
+
+// NewPinRepository creates a new IPinRepository instance
+func NewPinRepository(db *gorm.DB) IPinRepository {
+	return &PinRepository{db: db}
+}
+
+// Add pins a message, it reports false if the message was already pinned
+func (r *PinRepository) Add(ctx context.Context, pin *model.Pin) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(pin)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Remove unpins a message, it reports false if the message was not pinned
+func (r *PinRepository) Remove(ctx context.Context, guildID, messageID string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("guild_id = ? AND message_id = ?", guildID, messageID).
+		Delete(&model.Pin{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CountByGuild counts the pinned messages of a guild, deleted messages are not counted
+func (r *PinRepository) CountByGuild(ctx context.Context, guildID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.Message{}).
+		Joins("JOIN pins ON pins.message_id = messages.id").
+		Where("pins.guild_id = ?", guildID).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// FindMessagesByGuild retrieves the pinned messages of a guild, most recently pinned first
+func (r *PinRepository) FindMessagesByGuild(ctx context.Context, guildID string) ([]*model.Message, error) {
+	var messages []*model.Message
+	err := r.db.WithContext(ctx).
+		Joins("JOIN pins ON pins.message_id = messages.id").
+		Where("pins.guild_id = ?", guildID).
+		Order("pins.pinned_at DESC").
+		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Gopher0727/ChatRoom/internal/model"
+)
+
+// IReactionRepository defines the interface for reaction data operations
+type IReactionRepository interface {
+	Add(ctx context.Context, reaction *model.Reaction) (bool, error)
+	Remove(ctx context.Context, messageID, userID, emoji string) (bool, error)
+	CountByMessages(ctx context.Context, messageIDs []string) ([]*model.ReactionCount, error)
+}
+
+// ReactionRepository implements IReactionRepository interface
+type ReactionRepository struct {
+	db *gorm.DB
+}
+
+// NewReactionRepository creates a new IReactionRepository instance
+func NewReactionRepository(db *gorm.DB) IReactionRepository {
+	return &ReactionRepository{db: db}
+}
+
+// Add stores a reaction, it reports false if the user already reacted with this emoji
+func (r *ReactionRepository) Add(ctx context.Context, reaction *model.Reaction) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(reaction)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Remove deletes a reaction, it reports false if the reaction did not exist
+func (r *ReactionRepository) Remove(ctx context.Context, messageID, userID, emoji string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("message_id = ? AND user_id = ? AND emoji = ?", messageID, userID, emoji).
+		Delete(&model.Reaction{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CountByMessages aggregates reaction counts per message and emoji
+func (r *ReactionRepository) CountByMessages(ctx context.Context, messageIDs []string) ([]*model.ReactionCount, error) {
+	var counts []*model.ReactionCount
+	err := r.db.WithContext(ctx).
+		Model(&model.Reaction{}).
+		Select("message_id, emoji, COUNT(*) AS count").
+		Where("message_id IN ?", messageIDs).
+		Group("message_id, emoji").
+		Order("MIN(created_at) ASC").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Gopher0727/ChatRoom/internal/model"
+)
+
+// IReadStateRepository defines the interface for read state data operations
+type IReadStateRepository interface {
+	Upsert(ctx context.Context, states []*model.ReadState) error
+	FindByUser(ctx context.Context, userID string) ([]*model.ReadState, error)
+}
+
+// ReadStateRepository implements IReadStateRepository interface
+type ReadStateRepository struct {
+	db *gorm.DB
+}
+
+// NewReadStateRepository creates a new IReadStateRepository instance
+func NewReadStateRepository(db *gorm.DB) IReadStateRepository {
+	return &ReadStateRepository{db: db}
+}
+
+// Upsert stores read positions, an existing position is never moved backwards
+func (r *ReadStateRepository) Upsert(ctx context.Context, states []*model.ReadState) error {
+	if len(states) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}, {Name: "guild_id"}, {Name: "channel_id"}},
+			DoUpdates: clause.Set{
+				{Column: clause.Column{Name: "last_read_seq_id"}, Value: gorm.Expr("GREATEST(read_states.last_read_seq_id, excluded.last_read_seq_id)")},
+				{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("excluded.updated_at")},
+			},
+		}).
+		CreateInBatches(states, 500).Error
+}
+
+// FindByUser retrieves the stored read positions of a user
+func (r *ReadStateRepository) FindByUser(ctx context.Context, userID string) ([]*model.ReadState, error) {
+	var states []*model.ReadState
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&states).Error
+	if err != nil {
+		return nil, err
+	}
+	return states, nil
+}
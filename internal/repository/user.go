@@ -14,6 +14,8 @@ type IUserRepository interface {
 	FindByID(ctx context.Context, id string) (*model.User, error)
 	FindByIDs(ctx context.Context, ids []string) (map[string]*model.User, error)
 	FindByUsername(ctx context.Context, username string) (*model.User, error)
+	UpdateStatus(ctx context.Context, userID, status string) error
+	UpdatePreferredStatus(ctx context.Context, userID, preferredStatus, customStatus string) error
 	Update(ctx context.Context, user *model.User) error
 }
 
@@ -67,6 +69,25 @@ func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*
 	return &user, nil
 }
 
+// UpdateStatus updates the presence status shown to other users
+func (r *UserRepository) UpdateStatus(ctx context.Context, userID, status string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.User{}).
+		Where("id = ?", userID).
+		Update("status", status).Error
+}
+
+// UpdatePreferredStatus updates the status chosen by the user and their custom status text
+func (r *UserRepository) UpdatePreferredStatus(ctx context.Context, userID, preferredStatus, customStatus string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]any{
+			"preferred_status": preferredStatus,
+			"custom_status":    customStatus,
+		}).Error
+}
+
 // Update updates an existing user
 func (r *UserRepository) Update(ctx context.Context, user *model.User) error {
 	return r.db.WithContext(ctx).Save(user).Error
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/Gopher0727/ChatRoom/config"
+	"github.com/Gopher0727/ChatRoom/internal/model"
+	"github.com/Gopher0727/ChatRoom/internal/pkg/blob"
+	"github.com/Gopher0727/ChatRoom/internal/repository"
+)
+
+// maxAttachmentsPerMessage caps how many attachments a single message can carry
+const maxAttachmentsPerMessage = 10
+
+var (
+	ErrAttachmentNotFound        = errors.New("attachment not found")
+	ErrAttachmentTooLarge        = errors.New("attachment exceeds the maximum size")
+	ErrEmptyAttachment           = errors.New("attachment is empty")
+	ErrUnsupportedAttachmentType = errors.New("attachment type is not allowed")
+	ErrInvalidAttachment         = errors.New("attachments must be uploaded by the sender and not sent yet")
+	ErrInvalidDownloadSignature  = errors.New("download link is invalid or expired")
+)
+
+// SignedDownload is a time-limited capability to download an attachment
+type SignedDownload struct {
+	AttachmentID string `json:"attachment_id"`
+	Expires      int64  `json:"expires"`
+	Signature    string `json:"signature"`
+}
+
+// IAttachmentService defines the interface for attachment operations
+type IAttachmentService interface {
+	Upload(ctx context.Context, userID, filename string, r io.Reader) (*model.Attachment, error)
+	SignDownload(ctx context.Context, userID, attachmentID string) (*SignedDownload, error)
+	OpenDownload(ctx context.Context, attachmentID string, expires int64, signature string) (*model.Attachment, io.ReadCloser, error)
+}
+
+// AttachmentService implements the IAttachmentService interface
+type AttachmentService struct {
+	attachmentRepo repository.IAttachmentRepository
+	messageRepo    repository.IMessageRepository
+	dmRepo         repository.IDMRepository
+	guildService   IGuildService
+	store          blob.BlobStore
+	signer         *blob.Signer
+	config         *config.AttachmentConfig
+}
+
+// NewAttachmentService creates a new IAttachmentService instance
+func NewAttachmentService(
+	attachmentRepo repository.IAttachmentRepository,
+	messageRepo repository.IMessageRepository,
+	dmRepo repository.IDMRepository,
+	guildService IGuildService,
+	store blob.BlobStore,
+	cfg *config.AttachmentConfig,
+) IAttachmentService {
+	return &AttachmentService{
+		attachmentRepo: attachmentRepo,
+		messageRepo:    messageRepo,
+		dmRepo:         dmRepo,
+		guildService:   guildService,
+		store:          store,
+		signer:         blob.NewSigner(cfg.SigningSecret),
+		config:         cfg,
+	}
+}
+
+// Upload stores a file in the blob store and records its metadata
+// The content type is sniffed from the data instead of trusting the client,
+// the attachment stays private to the uploader until it is sent with a message
+func (s *AttachmentService) Upload(ctx context.Context, userID, filename string, r io.Reader) (*model.Attachment, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	if n == 0 {
+		return nil, ErrEmptyAttachment
+	}
+	head = head[:n]
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil || !slices.Contains(s.config.AllowedTypes, contentType) {
+		return nil, ErrUnsupportedAttachmentType
+	}
+
+	id := uuid.New().String()
+	maxSize := int64(s.config.MaxSizeMB) << 20
+
+	// Read one byte past the limit to detect oversized files without buffering them
+	body := io.LimitReader(io.MultiReader(bytes.NewReader(head), r), maxSize+1)
+	size, err := s.store.Put(ctx, id, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+	if size > maxSize {
+		s.deleteBlob(ctx, id)
+		return nil, ErrAttachmentTooLarge
+	}
+
+	attachment := &model.Attachment{
+		ID:          id,
+		UploaderID:  userID,
+		Filename:    cleanFilename(filename),
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  id,
+	}
+	if err := s.attachmentRepo.Create(ctx, attachment); err != nil {
+		s.deleteBlob(ctx, id)
+		return nil, fmt.Errorf("failed to save attachment: %w", err)
+	}
+
+	return attachment, nil
+}
+
+// SignDownload issues a signed download link after checking that the user can see the attachment
+// Sent attachments follow the access rules of their message, unsent ones are visible to the uploader only
+func (s *AttachmentService) SignDownload(ctx context.Context, userID, attachmentID string) (*SignedDownload, error) {
+	attachment, err := s.findAttachment(ctx, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if attachment.MessageID == "" {
+		if attachment.UploaderID != userID {
+			return nil, ErrAttachmentNotFound
+		}
+	} else {
+		message, err := s.messageRepo.FindByID(ctx, attachment.MessageID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrAttachmentNotFound
+			}
+			return nil, fmt.Errorf("failed to find message: %w", err)
+		}
+		if err := checkMessageAccess(ctx, s.guildService, s.dmRepo, userID, message); err != nil {
+			return nil, err
+		}
+	}
+
+	expires := time.Now().Add(time.Duration(s.config.URLExpireMinutes) * time.Minute).Unix()
+	return &SignedDownload{
+		AttachmentID: attachment.ID,
+		Expires:      expires,
+		Signature:    s.signer.Sign(attachment.ID, expires),
+	}, nil
+}
+
+// OpenDownload verifies a signed download link and opens the attachment content
+// The caller must close the returned reader
+func (s *AttachmentService) OpenDownload(ctx context.Context, attachmentID string, expires int64, signature string) (*model.Attachment, io.ReadCloser, error) {
+	if !s.signer.Verify(attachmentID, expires, signature, time.Now()) {
+		return nil, nil, ErrInvalidDownloadSignature
+	}
+
+	attachment, err := s.findAttachment(ctx, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.store.Open(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			return nil, nil, ErrAttachmentNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to open attachment: %w", err)
+	}
+
+	return attachment, content, nil
+}
+
+// findAttachment loads an attachment and maps a missing record to ErrAttachmentNotFound
+func (s *AttachmentService) findAttachment(ctx context.Context, attachmentID string) (*model.Attachment, error) {
+	attachment, err := s.attachmentRepo.FindByID(ctx, attachmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, fmt.Errorf("failed to find attachment: %w", err)
+	}
+	return attachment, nil
+}
+
+// deleteBlob removes a stored file that has no metadata, failures only leave an orphan behind
+func (s *AttachmentService) deleteBlob(ctx context.Context, key string) {
+	if err := s.store.Delete(ctx, key); err != nil {
+		fmt.Printf("WARNING: failed to delete attachment blob %s: %v\n", key, err)
+	}
+}
+
+// cleanFilename keeps the base name of a client supplied filename
+func cleanFilename(filename string) string {
+	name := strings.TrimSpace(filepath.Base(strings.ReplaceAll(filename, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	if len(name) > 255 {
+		name = strings.ToValidUTF8(name[:255], "")
+	}
+	return name
+}
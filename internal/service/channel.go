@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/Gopher0727/ChatRoom/internal/model"
+	"github.com/Gopher0727/ChatRoom/internal/repository"
+)
+
+// maxChannelsPerGuild caps how many channels a single guild can hold
+const maxChannelsPerGuild = 100
+
+var (
+	ErrChannelNotFound     = errors.New("channel not found")
+	ErrTooManyChannels     = errors.New("guild has reached the maximum number of channels")
+	ErrInvalidChannelOrder = errors.New("channel order must list every channel of the guild exactly once")
+)
+
+// CreateChannelRequest represents a request to create a channel
+type CreateChannelRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=100"`
+}
+
+// RenameChannelRequest represents a request to rename a channel
+type RenameChannelRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=100"`
+}
+
+// ReorderChannelsRequest represents the new display order of a guild's channels
+type ReorderChannelsRequest struct {
+	ChannelIDs []string `json:"channel_ids" binding:"required"`
+}
+
+// IChannelService defines the interface for channel management operations
+type IChannelService interface {
+	CreateChannel(ctx context.Context, userID, guildID, name string) (*model.Channel, error)
+	GetChannel(ctx context.Context, userID, channelID string) (*model.Channel, error)
+	ListChannels(ctx context.Context, userID, guildID string) ([]*model.Channel, error)
+	RenameChannel(ctx context.Context, userID, channelID, name string) (*model.Channel, error)
+	ReorderChannels(ctx context.Context, userID, guildID string, channelIDs []string) ([]*model.Channel, error)
+	DeleteChannel(ctx context.Context, userID, channelID string) error
+}
+
+// ChannelService implements the IChannelService interface
+type ChannelService struct {
+	channelRepo  repository.IChannelRepository
+	guildRepo    repository.IGuildRepository
+	guildService IGuildService
+}
+
+// NewChannelService creates a new IChannelService instance
+func NewChannelService(
+	channelRepo repository.IChannelRepository,
+	guildRepo repository.IGuildRepository,
+	guildService IGuildService,
+) IChannelService {
+	return &ChannelService{
+		channelRepo:  channelRepo,
+		guildRepo:    guildRepo,
+		guildService: guildService,
+	}
+}
+
+// CreateChannel appends a new channel to the end of the guild's channel list
+// Only the guild owner can create channels
+func (s *ChannelService) CreateChannel(ctx context.Context, userID, guildID, name string) (*model.Channel, error) {
+	if err := checkGuildOwner(ctx, s.guildRepo, userID, guildID); err != nil {
+		return nil, err
+	}
+
+	count, err := s.channelRepo.CountByGuild(ctx, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count channels: %w", err)
+	}
+	if count >= maxChannelsPerGuild {
+		return nil, ErrTooManyChannels
+	}
+
+	channel := &model.Channel{
+		ID:       uuid.New().String(),
+		GuildID:  guildID,
+		Name:     name,
+		Position: int(count),
+	}
+	if err := s.channelRepo.Create(ctx, channel); err != nil {
+		return nil, fmt.Errorf("failed to create channel: %w", err)
+	}
+
+	return channel, nil
+}
+
+// GetChannel retrieves a channel the user can access through guild membership
+func (s *ChannelService) GetChannel(ctx context.Context, userID, channelID string) (*model.Channel, error) {
+	channel, err := s.findChannel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkMember(ctx, userID, channel.GuildID); err != nil {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+// ListChannels retrieves all channels of a guild in display order
+func (s *ChannelService) ListChannels(ctx context.Context, userID, guildID string) ([]*model.Channel, error) {
+	if err := s.checkMember(ctx, userID, guildID); err != nil {
+		return nil, err
+	}
+
+	channels, err := s.channelRepo.FindByGuild(ctx, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channels: %w", err)
+	}
+
+	return channels, nil
+}
+
+// RenameChannel changes the name of a channel
+// Only the guild owner can rename channels
+func (s *ChannelService) RenameChannel(ctx context.Context, userID, channelID, name string) (*model.Channel, error) {
+	channel, err := s.findChannel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkGuildOwner(ctx, s.guildRepo, userID, channel.GuildID); err != nil {
+		return nil, err
+	}
+
+	channel.Name = name
+	if err := s.channelRepo.Update(ctx, channel); err != nil {
+		return nil, fmt.Errorf("failed to update channel: %w", err)
+	}
+
+	return channel, nil
+}
+
+// ReorderChannels rewrites the positions of a guild's channels
+// channelIDs must contain every channel of the guild exactly once, in the new order
+func (s *ChannelService) ReorderChannels(ctx context.Context, userID, guildID string, channelIDs []string) ([]*model.Channel, error) {
+	if err := checkGuildOwner(ctx, s.guildRepo, userID, guildID); err != nil {
+		return nil, err
+	}
+
+	channels, err := s.channelRepo.FindByGuild(ctx, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channels: %w", err)
+	}
+	if len(channelIDs) != len(channels) {
+		return nil, ErrInvalidChannelOrder
+	}
+
+	existing := make(map[string]bool, len(channels))
+	for _, channel := range channels {
+		existing[channel.ID] = true
+	}
+	for _, id := range channelIDs {
+		if !existing[id] {
+			return nil, ErrInvalidChannelOrder
+		}
+		// Each ID may appear only once
+		delete(existing, id)
+	}
+
+	if err := s.channelRepo.UpdatePositions(ctx, guildID, channelIDs); err != nil {
+		return nil, fmt.Errorf("failed to reorder channels: %w", err)
+	}
+
+	return s.channelRepo.FindByGuild(ctx, guildID)
+}
+
+// DeleteChannel removes a channel, its messages are no longer reachable
+// Only the guild owner can delete channels
+func (s *ChannelService) DeleteChannel(ctx context.Context, userID, channelID string) error {
+	channel, err := s.findChannel(ctx, channelID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkGuildOwner(ctx, s.guildRepo, userID, channel.GuildID); err != nil {
+		return err
+	}
+
+	if err := s.channelRepo.Delete(ctx, channel.ID); err != nil {
+		return fmt.Errorf("failed to delete channel: %w", err)
+	}
+
+	return nil
+}
+
+// findChannel loads a channel and maps a missing record to ErrChannelNotFound
+func (s *ChannelService) findChannel(ctx context.Context, channelID string) (*model.Channel, error) {
+	channel, err := s.channelRepo.FindByID(ctx, channelID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrChannelNotFound
+		}
+		return nil, fmt.Errorf("failed to find channel: %w", err)
+	}
+	return channel, nil
+}
+
+// checkMember verifies that the user belongs to the guild
+func (s *ChannelService) checkMember(ctx context.Context, userID, guildID string) error {
+	isMember, err := s.guildService.IsMember(ctx, userID, guildID)
+	if err != nil {
+		return fmt.Errorf("failed to check guild membership: %w", err)
+	}
+	if !isMember {
+		return ErrUserNotInGuild
+	}
+	return nil
+}
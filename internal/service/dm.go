@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/Gopher0727/ChatRoom/internal/model"
+	"github.com/Gopher0727/ChatRoom/internal/repository"
+)
+
+// maxGroupDMParticipants caps group DMs, larger groups should use a guild
+const maxGroupDMParticipants = 10
+
+var (
+	ErrNotParticipant      = errors.New("user is not a participant of this conversation")
+	ErrInvalidParticipants = errors.New("invalid conversation participants")
+)
+
+// OpenDirectConversationRequest represents a request to open a 1:1 conversation
+type OpenDirectConversationRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// CreateGroupConversationRequest represents a request to create a group DM
+type CreateGroupConversationRequest struct {
+	Name    string   `json:"name" binding:"max=100"`
+	UserIDs []string `json:"user_ids" binding:"required,min=1"`
+}
+
+// SendDirectMessageRequest represents a request to send a message in a conversation
+type SendDirectMessageRequest struct {
+	Content       string   `json:"content" binding:"required_without=AttachmentIDs,max=2000"`
+	ReplyToID     string   `json:"reply_to_id"`
+	AttachmentIDs []string `json:"attachment_ids" binding:"max=10"`
+	Nonce         string   `json:"nonce" binding:"max=64"`
+}
+
+// IDMService defines the interface for direct message conversation operations
+type IDMService interface {
+	OpenDirectConversation(ctx context.Context, userID, peerID string) (*model.DMConversation, error)
+	CreateGroupConversation(ctx context.Context, userID, name string, memberIDs []string) (*model.DMConversation, error)
+	ListConversations(ctx context.Context, userID string) ([]*model.DMConversation, error)
+}
+
+// DMService implements the IDMService interface
+type DMService struct {
+	dmRepo   repository.IDMRepository
+	userRepo repository.IUserRepository
+}
+
+// NewDMService creates a new IDMService instance
+func NewDMService(dmRepo repository.IDMRepository, userRepo repository.IUserRepository) IDMService {
+	return &DMService{
+		dmRepo:   dmRepo,
+		userRepo: userRepo,
+	}
+}
+
+// OpenDirectConversation returns the 1:1 conversation between two users, creating it on first use
+func (s *DMService) OpenDirectConversation(ctx context.Context, userID, peerID string) (*model.DMConversation, error) {
+	if peerID == "" || peerID == userID {
+		return nil, ErrInvalidParticipants
+	}
+	if err := s.checkUsersExist(ctx, []string{userID, peerID}); err != nil {
+		return nil, err
+	}
+
+	key := directKey(userID, peerID)
+	conversation, err := s.dmRepo.FindByDirectKey(ctx, key)
+	if err == nil {
+		conversation.ParticipantIDs = []string{userID, peerID}
+		return conversation, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to find conversation: %w", err)
+	}
+
+	conversation = &model.DMConversation{
+		ID:        uuid.New().String(),
+		OwnerID:   userID,
+		DirectKey: &key,
+	}
+	participantIDs := []string{userID, peerID}
+	if err := s.dmRepo.Create(ctx, conversation, participantIDs); err != nil {
+		// Another request may have created the same conversation concurrently
+		if existing, findErr := s.dmRepo.FindByDirectKey(ctx, key); findErr == nil {
+			existing.ParticipantIDs = participantIDs
+			return existing, nil
+		}
+		return nil, fmt.Errorf("failed to create conversation: %w", err)
+	}
+
+	conversation.ParticipantIDs = participantIDs
+	return conversation, nil
+}
+
+// CreateGroupConversation creates a group DM owned by the creator
+// The creator is always a participant, duplicate IDs are ignored
+func (s *DMService) CreateGroupConversation(ctx context.Context, userID, name string, memberIDs []string) (*model.DMConversation, error) {
+	participantIDs := []string{userID}
+	for _, id := range memberIDs {
+		if id != "" && !slices.Contains(participantIDs, id) {
+			participantIDs = append(participantIDs, id)
+		}
+	}
+	if len(participantIDs) < 2 || len(participantIDs) > maxGroupDMParticipants {
+		return nil, ErrInvalidParticipants
+	}
+	if err := s.checkUsersExist(ctx, participantIDs); err != nil {
+		return nil, err
+	}
+
+	conversation := &model.DMConversation{
+		ID:      uuid.New().String(),
+		Name:    name,
+		OwnerID: userID,
+		IsGroup: true,
+	}
+	if err := s.dmRepo.Create(ctx, conversation, participantIDs); err != nil {
+		return nil, fmt.Errorf("failed to create conversation: %w", err)
+	}
+
+	conversation.ParticipantIDs = participantIDs
+	return conversation, nil
+}
+
+// ListConversations retrieves the user's conversations with their participants
+func (s *DMService) ListConversations(ctx context.Context, userID string) ([]*model.DMConversation, error) {
+	conversations, err := s.dmRepo.FindByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversations: %w", err)
+	}
+
+	for _, conversation := range conversations {
+		participantIDs, err := s.dmRepo.GetParticipantIDs(ctx, conversation.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get conversation participants: %w", err)
+		}
+		conversation.ParticipantIDs = participantIDs
+	}
+
+	return conversations, nil
+}
+
+// checkUsersExist verifies that every user ID refers to a registered user
+func (s *DMService) checkUsersExist(ctx context.Context, userIDs []string) error {
+	users, err := s.userRepo.FindByIDs(ctx, userIDs)
+	if err != nil {
+		return fmt.Errorf("failed to find users: %w", err)
+	}
+	if len(users) != len(userIDs) {
+		return ErrInvalidParticipants
+	}
+	return nil
+}
+
+// directKey builds the order-independent key of the 1:1 conversation between two users
+func directKey(userA, userB string) string {
+	ids := []string{userA, userB}
+	slices.Sort(ids)
+	return strings.Join(ids, ":")
+}
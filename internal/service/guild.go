@@ -19,6 +19,7 @@ var (
 	ErrInvalidInviteCode = errors.New("invalid invite code")
 	ErrAlreadyMember     = errors.New("user is already a member of this guild")
 	ErrNotMember         = errors.New("user is not a member of this guild")
+	ErrNotGuildOwner     = errors.New("only the guild owner can perform this action")
 )
 
 // CreateGuildRequest represents a request to create a new guild
@@ -184,6 +185,21 @@ func (s *GuildService) IsMember(ctx context.Context, userID string, guildID stri
 	return false, nil
 }
 
+// checkGuildOwner verifies that the user owns the guild
+func checkGuildOwner(ctx context.Context, guildRepo repository.IGuildRepository, userID, guildID string) error {
+	guild, err := guildRepo.FindByID(ctx, guildID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrGuildNotFound
+		}
+		return fmt.Errorf("failed to find guild: %w", err)
+	}
+	if guild.OwnerID != userID {
+		return ErrNotGuildOwner
+	}
+	return nil
+}
+
 // generateUniqueInviteCode generates a unique invite code for a guild
 // It ensures uniqueness by checking against existing codes
 func (s *GuildService) generateUniqueInviteCode(ctx context.Context) (string, error) {
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Gopher0727/ChatRoom/internal/model"
+	"github.com/Gopher0727/ChatRoom/internal/repository"
+	"github.com/Gopher0727/ChatRoom/utils/mention"
+)
+
+// MentionPage is a page of the user's mentions inbox
+type MentionPage struct {
+	Messages   []*MessageWithUser `json:"messages"`
+	HasMore    bool               `json:"has_more"`
+	NextBefore int64              `json:"next_before"`
+}
+
+// IMentionService defines the interface for the mentions inbox
+type IMentionService interface {
+	GetMentions(ctx context.Context, userID string, before int64, limit int) (*MentionPage, error)
+}
+
+// MentionService implements the IMentionService interface
+type MentionService struct {
+	mentionRepo    repository.IMentionRepository
+	userRepo       repository.IUserRepository
+	reactionRepo   repository.IReactionRepository
+	attachmentRepo repository.IAttachmentRepository
+}
+
+// NewMentionService creates a new IMentionService instance
+func NewMentionService(
+	mentionRepo repository.IMentionRepository,
+	userRepo repository.IUserRepository,
+	reactionRepo repository.IReactionRepository,
+	attachmentRepo repository.IAttachmentRepository,
+) IMentionService {
+	return &MentionService{
+		mentionRepo:    mentionRepo,
+		userRepo:       userRepo,
+		reactionRepo:   reactionRepo,
+		attachmentRepo: attachmentRepo,
+	}
+}
+
+// GetMentions retrieves messages that mentioned the user across all guilds and conversations
+// before is a cursor in Unix microseconds (0 for the latest page), NextBefore continues the listing
+func (s *MentionService) GetMentions(ctx context.Context, userID string, before int64, limit int) (*MentionPage, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	var cursor time.Time
+	if before > 0 {
+		cursor = time.UnixMicro(before)
+	}
+
+	messages, err := s.mentionRepo.FindMessagesByUser(ctx, userID, cursor, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve mentions: %w", err)
+	}
+
+	hasMore := len(messages) > limit
+	if hasMore {
+		messages = messages[:limit]
+	}
+
+	page := &MentionPage{
+		Messages:   []*MessageWithUser{},
+		HasMore:    hasMore,
+		NextBefore: before,
+	}
+	if len(messages) > 0 {
+		page.Messages = attachUsers(ctx, s.userRepo, s.reactionRepo, s.attachmentRepo, messages)
+		page.NextBefore = messages[len(messages)-1].CreatedAt.UnixMicro()
+	}
+
+	return page, nil
+}
+
+// resolveMentions parses the mentions of a message and expands them to the users that can see it
+// Explicit mentions of users outside the guild or conversation are dropped, the author is never mentioned
+func (s *MessageService) resolveMentions(ctx context.Context, message *model.Message) ([]*model.Mention, error) {
+	parsed := mention.Parse(message.Content)
+	if parsed.Empty() {
+		return nil, nil
+	}
+
+	// Everyone who can see the message
+	var audience []string
+	if message.ConversationID != "" {
+		participantIDs, err := s.dmRepo.GetParticipantIDs(ctx, message.ConversationID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get conversation participants: %w", err)
+		}
+		audience = participantIDs
+	} else {
+		members, err := s.guildService.GetGuildMembers(ctx, message.GuildID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get guild members: %w", err)
+		}
+		audience = make([]string, len(members))
+		for i, member := range members {
+			audience[i] = member.ID
+		}
+	}
+
+	var explicit []string
+	for _, userID := range parsed.UserIDs {
+		if slices.Contains(audience, userID) {
+			explicit = append(explicit, userID)
+		}
+	}
+	message.MentionUserIDs = explicit
+	message.MentionEveryone = parsed.Everyone
+	message.MentionHere = parsed.Here
+
+	// Expand to the set of mentioned users
+	var mentioned []string
+	switch {
+	case parsed.Everyone:
+		mentioned = audience
+	case parsed.Here:
+		mentioned = explicit
+		for _, userID := range audience {
+			if slices.Contains(mentioned, userID) {
+				continue
+			}
+			online, err := s.redisClient.IsUserOnline(ctx, userID)
+			if err != nil {
+				fmt.Printf("WARNING: failed to check online status for @here: %v\n", err)
+				continue
+			}
+			if online {
+				mentioned = append(mentioned, userID)
+			}
+		}
+	default:
+		mentioned = explicit
+	}
+
+	mentions := make([]*model.Mention, 0, len(mentioned))
+	for _, userID := range mentioned {
+		if userID == message.UserID {
+			continue
+		}
+		mentions = append(mentions, &model.Mention{
+			MessageID:      message.ID,
+			UserID:         userID,
+			GuildID:        message.GuildID,
+			ConversationID: message.ConversationID,
+			CreatedAt:      message.CreatedAt,
+		})
+	}
+
+	return mentions, nil
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"sync"
 	"time"
 
 	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
 
 	"github.com/Gopher0727/ChatRoom/internal/model"
 	pb "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
@@ -21,81 +23,234 @@ var (
 	ErrMessageNotFound       = errors.New("message not found")
 	ErrInvalidMessageContent = errors.New("invalid message content")
 	ErrUserNotInGuild        = errors.New("user is not a member of this guild")
+	ErrNotMessageAuthor      = errors.New("only the author can modify this message")
+	ErrInvalidReplyTarget    = errors.New("reply target must be a message in the same guild")
+	ErrInvalidMessageTarget  = errors.New("message must target either a guild or a conversation")
+	ErrInvalidSearchQuery    = errors.New("search query cannot be empty")
+	ErrInvalidHistoryCursor  = errors.New("only one of before, after and around can be set")
+	ErrInvalidNonce          = errors.New("nonce must be at most 64 characters")
+	ErrMessageInFlight       = errors.New("a message with this nonce is still being sent")
 )
 
+const (
+	// maxNonceLength caps the client supplied nonce of a message
+	maxNonceLength = 64
+
+	// messageNonceTTL is the window in which a retried send with the same nonce is deduplicated
+	messageNonceTTL = 10 * time.Minute
+
+	// nonceWaitTimeout bounds how long a duplicate send waits for the original one to be persisted
+	nonceWaitTimeout = 3 * time.Second
+
+	// nonceRetryInterval is how often a duplicate send checks whether the original one was persisted
+	nonceRetryInterval = 100 * time.Millisecond
+)
+
+// EventRouter delivers events to the gateway nodes holding the sessions of the given users
+type EventRouter interface {
+	PushToUsers(ctx context.Context, userIDs []string, event *pb.WSMessage) error
+}
+
 // SendMessageRequest represents a request to send a message
 type SendMessageRequest struct {
-	UserID  string `json:"user_id" binding:"required"`
-	GuildID string `json:"guild_id" binding:"required"`
+	UserID    string `json:"user_id" binding:"required"`
+	GuildID   string `json:"guild_id" binding:"required"`
+	ChannelID string `json:"channel_id"`
+	Content   string `json:"content" binding:"required_without=AttachmentIDs,max=2000"`
+	ReplyToID string `json:"reply_to_id"`
+
+	// AttachmentIDs are previously uploaded attachments, the content may be empty when set
+	AttachmentIDs []string `json:"attachment_ids" binding:"max=10"`
+
+	// Nonce is an optional client generated key, a retried send with the same nonce
+	// returns the original message instead of creating another one
+	Nonce string `json:"nonce" binding:"max=64"`
+
+	// ConversationID targets a direct message conversation instead of a guild
+	ConversationID string `json:"-"`
+}
+
+// EditMessageRequest represents a request to edit a message
+type EditMessageRequest struct {
 	Content string `json:"content" binding:"required,max=2000"`
 }
 
 // GetMessagesRequest represents a request to retrieve messages
 type GetMessagesRequest struct {
+	// UserID is the requesting user, when set the guild membership is verified
+	UserID    string `json:"-"`
 	GuildID   string `json:"guild_id" binding:"required"`
+	ChannelID string `json:"channel_id"`
 	LastSeqID int64  `json:"last_seq_id"`
 	Limit     int    `json:"limit"`
+
+	// Cursors on seq_id, at most one can be set
+	// BeforeSeqID pages towards older messages, AfterSeqID towards newer ones,
+	// AroundSeqID centers the window on a message, for example to jump to a search result
+	BeforeSeqID int64 `json:"before_seq_id"`
+	AfterSeqID  int64 `json:"after_seq_id"`
+	AroundSeqID int64 `json:"around_seq_id"`
+
+	// ConversationID selects a direct message conversation instead of a guild
+	ConversationID string `json:"-"`
 }
 
 // MessageWithUser represents a message with user info
 type MessageWithUser struct {
 	*model.Message
-	Username string `json:"username"`
+	Username  string                 `json:"username"`
+	Reactions []*model.ReactionCount `json:"reactions,omitempty"`
+}
+
+// MessagePage is a window of a timeline in ascending seq_id order
+type MessagePage struct {
+	Messages []*model.Message
+	// HasMore reports more messages in the paging direction
+	HasMore       bool
+	HasMoreBefore bool
+	HasMoreAfter  bool
+}
+
+// HistoryPage is a window of a timeline with user info, in ascending seq_id order
+type HistoryPage struct {
+	Messages []*MessageWithUser `json:"messages"`
+	// HasMore reports more messages in the paging direction
+	HasMore       bool `json:"has_more"`
+	HasMoreBefore bool `json:"has_more_before"`
+	HasMoreAfter  bool `json:"has_more_after"`
+}
+
+// ThreadPage is a page of replies in a thread
+type ThreadPage struct {
+	Root      *MessageWithUser   `json:"root"`
+	Messages  []*MessageWithUser `json:"messages"`
+	HasMore   bool               `json:"has_more"`
+	NextSeqID int64              `json:"next_seq_id"`
 }
 
 // IMessageService defines the interface for message operations
 type IMessageService interface {
-	SendMessage(ctx context.Context, userID, guildID, content string) (*model.Message, error)
-	GetMessages(ctx context.Context, guildID string, lastSeqID int64, limit int) ([]*model.Message, bool, error)
-	GetMessagesWithUser(ctx context.Context, guildID string, lastSeqID int64, limit int) ([]*MessageWithUser, bool, error)
+	SendMessage(ctx context.Context, req *SendMessageRequest) (*model.Message, error)
+	GetMessages(ctx context.Context, req *GetMessagesRequest) (*MessagePage, error)
+	GetMessagesWithUser(ctx context.Context, req *GetMessagesRequest) (*HistoryPage, error)
 	BatchGetMessages(ctx context.Context, messageIDs []string) ([]*model.Message, error)
+	GetThread(ctx context.Context, userID, rootID string, afterSeqID int64, limit int) (*ThreadPage, error)
+	EditMessage(ctx context.Context, userID, messageID, content string) (*model.Message, error)
+	DeleteMessage(ctx context.Context, userID, messageID string) error
+	SearchMessages(ctx context.Context, req *SearchMessagesRequest) (*SearchPage, error)
 }
 
 // MessageService implements the MessageService interface
 type MessageService struct {
-	messageRepo  repository.IMessageRepository
-	userRepo     repository.IUserRepository
-	guildService IGuildService
-	snowflakeGen *snowflake.Generator
-	redisClient  redis.RedisClient
+	messageRepo    repository.IMessageRepository
+	userRepo       repository.IUserRepository
+	reactionRepo   repository.IReactionRepository
+	channelRepo    repository.IChannelRepository
+	dmRepo         repository.IDMRepository
+	mentionRepo    repository.IMentionRepository
+	attachmentRepo repository.IAttachmentRepository
+	guildService   IGuildService
+	snowflakeGen   *snowflake.Generator
+	redisClient    redis.RedisClient
+	router         EventRouter
 }
 
 // NewMessageService creates a new MessageService instance
 func NewMessageService(
 	messageRepo repository.IMessageRepository,
 	userRepo repository.IUserRepository,
+	reactionRepo repository.IReactionRepository,
+	channelRepo repository.IChannelRepository,
+	dmRepo repository.IDMRepository,
+	mentionRepo repository.IMentionRepository,
+	attachmentRepo repository.IAttachmentRepository,
 	guildService IGuildService,
 	snowflakeGen *snowflake.Generator,
 	redisClient redis.RedisClient,
+	router EventRouter,
 ) IMessageService {
 	return &MessageService{
-		messageRepo:  messageRepo,
-		userRepo:     userRepo,
-		guildService: guildService,
-		snowflakeGen: snowflakeGen,
-		redisClient:  redisClient,
+		messageRepo:    messageRepo,
+		userRepo:       userRepo,
+		reactionRepo:   reactionRepo,
+		channelRepo:    channelRepo,
+		dmRepo:         dmRepo,
+		mentionRepo:    mentionRepo,
+		attachmentRepo: attachmentRepo,
+		guildService:   guildService,
+		snowflakeGen:   snowflakeGen,
+		redisClient:    redisClient,
+		router:         router,
 	}
 }
 
-// SendMessage sends a message to a guild
+// SendMessage sends a message to a guild, one of its channels, or a direct message conversation
 // It generates a Snowflake ID, obtains a Seq ID from Redis,
 // and writes to both PostgreSQL and Redis Pub/Sub in parallel
-func (s *MessageService) SendMessage(ctx context.Context, userID, guildID, content string) (*model.Message, error) {
-	// Validate message content
-	if len(content) == 0 {
+func (s *MessageService) SendMessage(ctx context.Context, req *SendMessageRequest) (*model.Message, error) {
+	userID, guildID, channelID, content := req.UserID, req.GuildID, req.ChannelID, req.Content
+	conversationID := req.ConversationID
+
+	// Validate message content, a message with attachments may have no text
+	attachmentIDs := uniqueIDs(req.AttachmentIDs)
+	if len(content) == 0 && len(attachmentIDs) == 0 {
 		return nil, ErrInvalidMessageContent
 	}
 	if len(content) > 2000 {
 		return nil, ErrInvalidMessageContent
 	}
+	if len(attachmentIDs) > maxAttachmentsPerMessage {
+		return nil, ErrInvalidAttachment
+	}
+	if len(req.Nonce) > maxNonceLength {
+		return nil, ErrInvalidNonce
+	}
 
-	// Verify user is a member of the guild
-	isMember, err := s.guildService.IsMember(ctx, userID, guildID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check guild membership: %w", err)
+	if conversationID != "" {
+		// Direct messages are scoped by the conversation's participants only
+		if guildID != "" || channelID != "" {
+			return nil, ErrInvalidMessageTarget
+		}
+		if err := checkParticipant(ctx, s.dmRepo, userID, conversationID); err != nil {
+			return nil, err
+		}
+	} else {
+		if guildID == "" {
+			return nil, ErrInvalidMessageTarget
+		}
+
+		// Verify user is a member of the guild
+		isMember, err := s.guildService.IsMember(ctx, userID, guildID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check guild membership: %w", err)
+		}
+		if !isMember {
+			return nil, ErrUserNotInGuild
+		}
+
+		// The channel must belong to the guild, membership is checked at the guild level
+		if err := s.checkChannel(ctx, guildID, channelID); err != nil {
+			return nil, err
+		}
 	}
-	if !isMember {
-		return nil, ErrUserNotInGuild
+
+	// Resolve the thread when replying to another message
+	var threadRootID string
+	if req.ReplyToID != "" {
+		parent, err := s.messageRepo.FindByID(ctx, req.ReplyToID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrInvalidReplyTarget
+			}
+			return nil, fmt.Errorf("failed to find reply target: %w", err)
+		}
+		if parent.GuildID != guildID || parent.ChannelID != channelID || parent.ConversationID != conversationID {
+			return nil, ErrInvalidReplyTarget
+		}
+		threadRootID = parent.ThreadRootID
+		if threadRootID == "" {
+			threadRootID = parent.ID
+		}
 	}
 
 	// Generate Snowflake ID for the message
@@ -105,8 +260,40 @@ func (s *MessageService) SendMessage(ctx context.Context, userID, guildID, conte
 	}
 	messageID := strconv.FormatInt(snowflakeID, 10)
 
+	// A retried send carries the same nonce, it gets the original message back instead of a duplicate
+	sent := false
+	if req.Nonce != "" {
+		original, err := s.claimNonce(ctx, userID, req.Nonce, messageID)
+		if err != nil {
+			return nil, err
+		}
+		if original != nil {
+			s.echoOriginal(ctx, original)
+			return original, nil
+		}
+
+		// Release the nonce when the send fails, so that the client can retry with it
+		defer func() {
+			if sent {
+				return
+			}
+			if err := s.redisClient.ReleaseMessageNonce(context.WithoutCancel(ctx), userID, req.Nonce, messageID); err != nil {
+				fmt.Printf("WARNING: failed to release message nonce: %v\n", err)
+			}
+		}()
+	}
+
 	// Get Seq ID from Redis (atomic increment)
-	seqID, err := s.redisClient.GenerateSeqID(ctx, guildID)
+	// Channels and conversations have their own sequence, the guild's default timeline keeps the guild sequence
+	var seqID int64
+	switch {
+	case conversationID != "":
+		seqID, err = s.redisClient.GenerateConversationSeqID(ctx, conversationID)
+	case channelID != "":
+		seqID, err = s.redisClient.GenerateChannelSeqID(ctx, channelID)
+	default:
+		seqID, err = s.redisClient.GenerateSeqID(ctx, guildID)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate seq ID: %w", err)
 	}
@@ -116,22 +303,35 @@ func (s *MessageService) SendMessage(ctx context.Context, userID, guildID, conte
 		ID:        messageID,
 		UserID:    userID,
 		GuildID:   guildID,
+		ChannelID: channelID,
 		Content:   content,
 		SeqID:     seqID,
 		CreatedAt: time.Now(),
+
+		ConversationID: conversationID,
+
+		ReplyToID:    req.ReplyToID,
+		ThreadRootID: threadRootID,
+
+		Nonce: req.Nonce,
 	}
 
-	// Fetch username for real-time push
-	user, err := s.userRepo.FindByID(ctx, userID)
-	var username string
+	// Claim the attachments before publishing so that the event carries them
+	if len(attachmentIDs) > 0 {
+		if err := s.linkAttachments(ctx, message, attachmentIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	// Resolve mentions before publishing so that the event carries them
+	mentions, err := s.resolveMentions(ctx, message)
 	if err != nil {
-		// Log error but continue, username will be empty
-		fmt.Printf("WARNING: failed to fetch user info for message push: %v\n", err)
-		username = "Unknown"
-	} else {
-		username = user.UserName
+		return nil, err
 	}
 
+	// Fetch username for real-time push
+	username := s.fetchUsername(ctx, userID)
+
 	// Parallel write to PostgreSQL and Redis Pub/Sub
 	var wg sync.WaitGroup
 	var dbErr, pubsubErr error
@@ -147,13 +347,19 @@ func (s *MessageService) SendMessage(ctx context.Context, userID, guildID, conte
 	// Publish to Redis Pub/Sub
 	go func() {
 		defer wg.Done()
-		pubsubErr = s.publishMessage(ctx, message, username)
+		pubsubErr = s.publishMessage(ctx, message, username, pb.MessageType_TEXT)
 	}()
 
 	wg.Wait()
 
 	// Check for errors
 	if dbErr != nil {
+		if len(attachmentIDs) > 0 {
+			// Release the attachments so that the upload can be sent again
+			if err := s.attachmentRepo.Unlink(ctx, messageID); err != nil {
+				fmt.Printf("WARNING: failed to release message attachments: %v\n", err)
+			}
+		}
 		return nil, fmt.Errorf("failed to save message to database: %w", dbErr)
 	}
 	if pubsubErr != nil {
@@ -162,12 +368,73 @@ func (s *MessageService) SendMessage(ctx context.Context, userID, guildID, conte
 		fmt.Printf("WARNING: failed to publish message to Redis Pub/Sub: %v\n", pubsubErr)
 	}
 
+	// Mention rows only feed the mentions inbox, the message itself is already delivered
+	if len(mentions) > 0 {
+		if err := s.mentionRepo.CreateBatch(ctx, mentions); err != nil {
+			fmt.Printf("WARNING: failed to save message mentions: %v\n", err)
+		}
+	}
+
+	sent = true
 	return message, nil
 }
 
-// GetMessages retrieves messages for a guild with optional filtering by sequence ID
-// Supports incremental message queries and pagination
-func (s *MessageService) GetMessages(ctx context.Context, guildID string, lastSeqID int64, limit int) ([]*model.Message, bool, error) {
+// claimNonce claims the user's nonce for a new message within the deduplication window
+// When the nonce is already used, it waits for the original send to be persisted and returns its message,
+// nil means the nonce was claimed for messageID
+func (s *MessageService) claimNonce(ctx context.Context, userID, nonce, messageID string) (*model.Message, error) {
+	deadline := time.Now().Add(nonceWaitTimeout)
+	for {
+		owner, err := s.redisClient.ClaimMessageNonce(ctx, userID, nonce, messageID, messageNonceTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim message nonce: %w", err)
+		}
+		if owner == messageID {
+			return nil, nil
+		}
+
+		original, err := s.messageRepo.FindByID(ctx, owner)
+		if err == nil {
+			original.Nonce = nonce
+			return original, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to find original message: %w", err)
+		}
+
+		// The original send is still in flight, it either persists the message or releases the nonce
+		if time.Now().After(deadline) {
+			return nil, ErrMessageInFlight
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(nonceRetryInterval):
+		}
+	}
+}
+
+// echoOriginal pushes the original message of a retried send to the sender's sessions again
+// A send over the gateway gets no response, so the client reconciles its retry from the pushed nonce
+func (s *MessageService) echoOriginal(ctx context.Context, message *model.Message) {
+	attachments, err := s.attachmentRepo.FindByMessages(ctx, []string{message.ID})
+	if err != nil {
+		fmt.Printf("WARNING: failed to load attachments of original message: %v\n", err)
+	}
+	message.Attachments = attachments
+
+	pbMessage := ToWSMessage(message, s.fetchUsername(ctx, message.UserID), pb.MessageType_TEXT)
+	if err := publishToUser(ctx, s.router, message.UserID, pbMessage); err != nil {
+		fmt.Printf("WARNING: failed to echo original message: %v\n", err)
+	}
+}
+
+// GetMessages retrieves a window of a guild channel or conversation timeline
+// At most one of the before, after and around cursors can be set, without a cursor the latest messages are returned
+// Messages are always returned in ascending seq_id order
+func (s *MessageService) GetMessages(ctx context.Context, req *GetMessagesRequest) (*MessagePage, error) {
+	limit := req.Limit
+
 	// Set default limit if not provided
 	if limit <= 0 {
 		limit = 50 // Default page size
@@ -176,32 +443,231 @@ func (s *MessageService) GetMessages(ctx context.Context, guildID string, lastSe
 		limit = 100 // Maximum page size
 	}
 
-	// Query messages from database (fetch one extra to check if there are more)
-	messages, err := s.messageRepo.FindByGuild(ctx, guildID, lastSeqID, limit+1)
+	// LastSeqID predates the explicit cursors and means "after"
+	before, after, around := req.BeforeSeqID, req.AfterSeqID, req.AroundSeqID
+	if after == 0 {
+		after = req.LastSeqID
+	}
+	if before < 0 || after < 0 || around < 0 {
+		return nil, ErrInvalidHistoryCursor
+	}
+	cursors := 0
+	for _, cursor := range []int64{before, after, around} {
+		if cursor > 0 {
+			cursors++
+		}
+	}
+	if cursors > 1 {
+		return nil, ErrInvalidHistoryCursor
+	}
+
+	var find func(cursor repository.SeqCursor, limit int) ([]*model.Message, error)
+	if req.ConversationID != "" {
+		if req.UserID != "" {
+			if err := checkParticipant(ctx, s.dmRepo, req.UserID, req.ConversationID); err != nil {
+				return nil, err
+			}
+		}
+
+		find = func(cursor repository.SeqCursor, limit int) ([]*model.Message, error) {
+			return s.messageRepo.FindByConversation(ctx, req.ConversationID, cursor, limit)
+		}
+	} else {
+		if req.UserID != "" {
+			isMember, err := s.guildService.IsMember(ctx, req.UserID, req.GuildID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check guild membership: %w", err)
+			}
+			if !isMember {
+				return nil, ErrUserNotInGuild
+			}
+		}
+
+		if err := s.checkChannel(ctx, req.GuildID, req.ChannelID); err != nil {
+			return nil, err
+		}
+
+		find = func(cursor repository.SeqCursor, limit int) ([]*model.Message, error) {
+			return s.messageRepo.FindByGuild(ctx, req.GuildID, req.ChannelID, cursor, limit)
+		}
+	}
+
+	page, err := pageMessages(find, before, after, around, limit)
 	if err != nil {
-		return nil, false, fmt.Errorf("failed to retrieve messages: %w", err)
+		return nil, fmt.Errorf("failed to retrieve messages: %w", err)
 	}
+	return page, nil
+}
 
-	// Check if there are more messages
-	hasMore := len(messages) > limit
-	if hasMore {
-		messages = messages[:limit]
+// pageMessages queries the timeline around a cursor, fetching one extra message per side to detect more history
+func pageMessages(
+	find func(cursor repository.SeqCursor, limit int) ([]*model.Message, error),
+	before, after, around int64,
+	limit int,
+) (*MessagePage, error) {
+	page := &MessagePage{}
+
+	switch {
+	case around > 0:
+		// The older half includes the message at the cursor itself
+		olderLimit := limit - limit/2
+		newerLimit := limit / 2
+
+		older, err := find(repository.SeqCursor{Before: around + 1}, olderLimit+1)
+		if err != nil {
+			return nil, err
+		}
+		newer, err := find(repository.SeqCursor{After: around}, newerLimit+1)
+		if err != nil {
+			return nil, err
+		}
+
+		page.HasMoreBefore = len(older) > olderLimit
+		if page.HasMoreBefore {
+			older = older[:olderLimit]
+		}
+		page.HasMoreAfter = len(newer) > newerLimit
+		if page.HasMoreAfter {
+			newer = newer[:newerLimit]
+		}
+		slices.Reverse(older)
+		page.Messages = append(older, newer...)
+		page.HasMore = page.HasMoreBefore || page.HasMoreAfter
+
+	case after > 0:
+		newer, err := find(repository.SeqCursor{After: after}, limit+1)
+		if err != nil {
+			return nil, err
+		}
+		page.HasMoreAfter = len(newer) > limit
+		if page.HasMoreAfter {
+			newer = newer[:limit]
+		}
+		// Anything at or before the cursor is older history
+		probe, err := find(repository.SeqCursor{Before: after + 1}, 1)
+		if err != nil {
+			return nil, err
+		}
+		page.Messages = newer
+		page.HasMoreBefore = len(probe) > 0
+		page.HasMore = page.HasMoreAfter
+
+	default:
+		older, err := find(repository.SeqCursor{Before: before}, limit+1)
+		if err != nil {
+			return nil, err
+		}
+		page.HasMoreBefore = len(older) > limit
+		if page.HasMoreBefore {
+			older = older[:limit]
+		}
+		if before > 0 {
+			// Anything at or after the cursor is newer history
+			probe, err := find(repository.SeqCursor{After: before - 1}, 1)
+			if err != nil {
+				return nil, err
+			}
+			page.HasMoreAfter = len(probe) > 0
+		}
+		slices.Reverse(older)
+		page.Messages = older
+		page.HasMore = page.HasMoreBefore
 	}
 
-	return messages, hasMore, nil
+	return page, nil
 }
 
-// GetMessagesWithUser retrieves messages with user info
-func (s *MessageService) GetMessagesWithUser(ctx context.Context, guildID string, lastSeqID int64, limit int) ([]*MessageWithUser, bool, error) {
-	messages, hasMore, err := s.GetMessages(ctx, guildID, lastSeqID, limit)
+// GetMessagesWithUser retrieves a window of messages with user info
+func (s *MessageService) GetMessagesWithUser(ctx context.Context, req *GetMessagesRequest) (*HistoryPage, error) {
+	page, err := s.GetMessages(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &HistoryPage{
+		Messages:      []*MessageWithUser{},
+		HasMore:       page.HasMore,
+		HasMoreBefore: page.HasMoreBefore,
+		HasMoreAfter:  page.HasMoreAfter,
+	}
+	if len(page.Messages) > 0 {
+		history.Messages = s.withUsers(ctx, page.Messages)
+	}
+
+	return history, nil
+}
+
+// GetThread retrieves the root message of a thread and a page of its replies
+// Replies are returned oldest first, NextSeqID is the cursor for the following page
+func (s *MessageService) GetThread(ctx context.Context, userID, rootID string, afterSeqID int64, limit int) (*ThreadPage, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	root, err := s.messageRepo.FindByID(ctx, rootID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, fmt.Errorf("failed to find thread root: %w", err)
+	}
+
+	// Accept any message of the thread as the entry point
+	if root.ThreadRootID != "" {
+		root, err = s.messageRepo.FindByID(ctx, root.ThreadRootID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrMessageNotFound
+			}
+			return nil, fmt.Errorf("failed to find thread root: %w", err)
+		}
+	}
+
+	if err := checkMessageAccess(ctx, s.guildService, s.dmRepo, userID, root); err != nil {
+		return nil, err
+	}
+
+	replies, err := s.messageRepo.FindByThread(ctx, root.ID, afterSeqID, limit+1)
 	if err != nil {
-		return nil, false, err
+		return nil, fmt.Errorf("failed to retrieve thread messages: %w", err)
+	}
+
+	hasMore := len(replies) > limit
+	if hasMore {
+		replies = replies[:limit]
 	}
 
-	if len(messages) == 0 {
-		return []*MessageWithUser{}, false, nil
+	nextSeqID := afterSeqID
+	if len(replies) > 0 {
+		nextSeqID = replies[len(replies)-1].SeqID
 	}
 
+	withUsers := s.withUsers(ctx, append([]*model.Message{root}, replies...))
+
+	return &ThreadPage{
+		Root:      withUsers[0],
+		Messages:  withUsers[1:],
+		HasMore:   hasMore,
+		NextSeqID: nextSeqID,
+	}, nil
+}
+
+// withUsers attaches usernames and reaction counts to messages using batch lookups
+func (s *MessageService) withUsers(ctx context.Context, messages []*model.Message) []*MessageWithUser {
+	return attachUsers(ctx, s.userRepo, s.reactionRepo, s.attachmentRepo, messages)
+}
+
+// attachUsers attaches usernames, reaction counts and attachments to messages using batch lookups
+func attachUsers(
+	ctx context.Context,
+	userRepo repository.IUserRepository,
+	reactionRepo repository.IReactionRepository,
+	attachmentRepo repository.IAttachmentRepository,
+	messages []*model.Message,
+) []*MessageWithUser {
 	// Collect user IDs
 	userIDs := make([]string, 0, len(messages))
 	seen := make(map[string]bool)
@@ -213,13 +679,39 @@ func (s *MessageService) GetMessagesWithUser(ctx context.Context, guildID string
 	}
 
 	// Batch fetch users
-	userMap, err := s.userRepo.FindByIDs(ctx, userIDs)
+	userMap, err := userRepo.FindByIDs(ctx, userIDs)
 	if err != nil {
 		fmt.Printf("WARNING: failed to fetch users for messages: %v\n", err)
 		// Continue without usernames
 		userMap = make(map[string]*model.User)
 	}
 
+	// Batch fetch reaction counts
+	messageIDs := make([]string, len(messages))
+	for i, msg := range messages {
+		messageIDs[i] = msg.ID
+	}
+	reactionMap := make(map[string][]*model.ReactionCount)
+	counts, err := reactionRepo.CountByMessages(ctx, messageIDs)
+	if err != nil {
+		fmt.Printf("WARNING: failed to fetch reactions for messages: %v\n", err)
+		// Continue without reactions
+	}
+	for _, count := range counts {
+		reactionMap[count.MessageID] = append(reactionMap[count.MessageID], count)
+	}
+
+	// Batch fetch attachments
+	attachmentMap := make(map[string][]*model.Attachment)
+	attachments, err := attachmentRepo.FindByMessages(ctx, messageIDs)
+	if err != nil {
+		fmt.Printf("WARNING: failed to fetch attachments for messages: %v\n", err)
+		// Continue without attachments
+	}
+	for _, attachment := range attachments {
+		attachmentMap[attachment.MessageID] = append(attachmentMap[attachment.MessageID], attachment)
+	}
+
 	// Assemble result
 	result := make([]*MessageWithUser, len(messages))
 	for i, msg := range messages {
@@ -227,13 +719,15 @@ func (s *MessageService) GetMessagesWithUser(ctx context.Context, guildID string
 		if user, ok := userMap[msg.UserID]; ok {
 			username = user.UserName
 		}
+		msg.Attachments = attachmentMap[msg.ID]
 		result[i] = &MessageWithUser{
-			Message:  msg,
-			Username: username,
+			Message:   msg,
+			Username:  username,
+			Reactions: reactionMap[msg.ID],
 		}
 	}
 
-	return result, hasMore, nil
+	return result
 }
 
 // BatchGetMessages retrieves multiple messages by their IDs
@@ -255,21 +749,275 @@ func (s *MessageService) BatchGetMessages(ctx context.Context, messageIDs []stri
 	return messages, nil
 }
 
-// publishMessage publishes a message to Redis Pub/Sub
+// EditMessage replaces the content of a message
+// Only the author can edit, and the change is pushed to the guild as a MESSAGE_EDIT event
+func (s *MessageService) EditMessage(ctx context.Context, userID, messageID, content string) (*model.Message, error) {
+	// Validate message content
+	if len(content) == 0 || len(content) > 2000 {
+		return nil, ErrInvalidMessageContent
+	}
+
+	message, err := s.findOwnMessage(ctx, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	message.Content = content
+	message.EditedAt = &now
+
+	// The edit event replaces the clients' copy of the message, so it carries the attachments
+	// and the mentions of the new content like the original send
+	attachments, err := s.attachmentRepo.FindByMessages(ctx, []string{message.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load attachments: %w", err)
+	}
+	message.Attachments = attachments
+
+	mentions, err := s.resolveMentions(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.messageRepo.Update(ctx, message); err != nil {
+		return nil, fmt.Errorf("failed to update message: %w", err)
+	}
+
+	// Mentions added by the edit reach the inbox, removed ones leave it
+	if err := s.mentionRepo.ReplaceForMessage(ctx, message.ID, mentions); err != nil {
+		fmt.Printf("WARNING: failed to update message mentions: %v\n", err)
+	}
+
+	username := s.fetchUsername(ctx, userID)
+	if err := s.publishMessage(ctx, message, username, pb.MessageType_MESSAGE_EDIT); err != nil {
+		fmt.Printf("WARNING: failed to publish message edit to Redis Pub/Sub: %v\n", err)
+	}
+
+	return message, nil
+}
+
+// DeleteMessage soft-deletes a message
+// Only the author can delete, and the removal is pushed to the guild as a MESSAGE_DELETE event
+func (s *MessageService) DeleteMessage(ctx context.Context, userID, messageID string) error {
+	message, err := s.findOwnMessage(ctx, userID, messageID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.messageRepo.Delete(ctx, message.ID); err != nil {
+		return fmt.Errorf("failed to delete message: %w", err)
+	}
+
+	// Clients only need the identifiers to remove the message locally
+	message.Content = ""
+	if err := s.publishMessage(ctx, message, "", pb.MessageType_MESSAGE_DELETE); err != nil {
+		fmt.Printf("WARNING: failed to publish message deletion to Redis Pub/Sub: %v\n", err)
+	}
+
+	return nil
+}
+
+// linkAttachments binds the sender's unsent attachments to a message and loads their metadata
+func (s *MessageService) linkAttachments(ctx context.Context, message *model.Message, attachmentIDs []string) error {
+	linked, err := s.attachmentRepo.LinkToMessage(ctx, attachmentIDs, message.UserID, message.ID)
+	if err != nil {
+		return fmt.Errorf("failed to link attachments: %w", err)
+	}
+	if !linked {
+		return ErrInvalidAttachment
+	}
+
+	attachments, err := s.attachmentRepo.FindByMessages(ctx, []string{message.ID})
+	if err != nil {
+		return fmt.Errorf("failed to load attachments: %w", err)
+	}
+	message.Attachments = attachments
+	return nil
+}
+
+// uniqueIDs drops empty and duplicate IDs while keeping their order
+func uniqueIDs(ids []string) []string {
+	var result []string
+	for _, id := range ids {
+		if id != "" && !slices.Contains(result, id) {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// findOwnMessage loads a message and verifies that it was sent by userID
+func (s *MessageService) findOwnMessage(ctx context.Context, userID, messageID string) (*model.Message, error) {
+	message, err := s.messageRepo.FindByID(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, fmt.Errorf("failed to find message: %w", err)
+	}
+	if message.UserID != userID {
+		return nil, ErrNotMessageAuthor
+	}
+	return message, nil
+}
+
+// checkChannel verifies that a non-empty channelID refers to a channel of the guild
+func (s *MessageService) checkChannel(ctx context.Context, guildID, channelID string) error {
+	if channelID == "" {
+		return nil
+	}
+	channel, err := s.channelRepo.FindByID(ctx, channelID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrChannelNotFound
+		}
+		return fmt.Errorf("failed to find channel: %w", err)
+	}
+	if channel.GuildID != guildID {
+		return ErrChannelNotFound
+	}
+	return nil
+}
+
+// checkParticipant verifies that the user takes part in a direct message conversation
+func checkParticipant(ctx context.Context, dmRepo repository.IDMRepository, userID, conversationID string) error {
+	isParticipant, err := dmRepo.IsParticipant(ctx, conversationID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check conversation participants: %w", err)
+	}
+	if !isParticipant {
+		return ErrNotParticipant
+	}
+	return nil
+}
+
+// checkMessageAccess verifies that the user can see a message, through guild membership or DM participation
+func checkMessageAccess(ctx context.Context, guildService IGuildService, dmRepo repository.IDMRepository, userID string, message *model.Message) error {
+	if message.ConversationID != "" {
+		return checkParticipant(ctx, dmRepo, userID, message.ConversationID)
+	}
+
+	isMember, err := guildService.IsMember(ctx, userID, message.GuildID)
+	if err != nil {
+		return fmt.Errorf("failed to check guild membership: %w", err)
+	}
+	if !isMember {
+		return ErrUserNotInGuild
+	}
+	return nil
+}
+
+// fetchUsername returns the username used in real-time pushes, or "Unknown" if the lookup fails
+func (s *MessageService) fetchUsername(ctx context.Context, userID string) string {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		// Log error but continue
+		fmt.Printf("WARNING: failed to fetch user info for message push: %v\n", err)
+		return "Unknown"
+	}
+	return user.UserName
+}
+
+// publishMessage publishes a message event to Redis Pub/Sub
 // The message is serialized using Protobuf and published to a guild-specific channel
-func (s *MessageService) publishMessage(ctx context.Context, message *model.Message, username string) error {
-	// Convert to Protobuf message
+func (s *MessageService) publishMessage(ctx context.Context, message *model.Message, username string, msgType pb.MessageType) error {
+	return publishEvent(ctx, s.redisClient, s.router, s.dmRepo, ToWSMessage(message, username, msgType))
+}
+
+// ToWSMessage converts a message to the event pushed to clients, also used to replay it and by the internal API.
+// Timestamp is the creation time of the message, every timestamp is in milliseconds.
+func ToWSMessage(message *model.Message, username string, msgType pb.MessageType) *pb.WSMessage {
 	pbMessage := &pb.WSMessage{
 		MessageId: message.ID,
 		UserId:    message.UserID,
 		GuildId:   message.GuildID,
+		ChannelId: message.ChannelID,
 		Content:   message.Content,
 		SeqId:     message.SeqID,
-		Timestamp: time.Now().UnixMilli(),
-		Type:      pb.MessageType_TEXT,
+		Timestamp: message.CreatedAt.UnixMilli(),
+		Type:      msgType,
 		Username:  username,
+
+		ReplyToId:      message.ReplyToID,
+		ThreadRootId:   message.ThreadRootID,
+		ConversationId: message.ConversationID,
+
+		MentionUserIds:  message.MentionUserIDs,
+		MentionEveryone: message.MentionEveryone,
+		MentionHere:     message.MentionHere,
+
+		Attachments: toPBAttachments(message.Attachments),
+		Nonce:       message.Nonce,
+	}
+	if message.EditedAt != nil {
+		pbMessage.EditedAt = message.EditedAt.UnixMilli()
+	}
+	return pbMessage
+}
+
+// ToWSMessage converts a message loaded from the history to a new message event, with its author and reactions
+func (m *MessageWithUser) ToWSMessage() *pb.WSMessage {
+	pbMessage := ToWSMessage(m.Message, m.Username, pb.MessageType_TEXT)
+	pbMessage.Reactions = ToPBReactions(m.Reactions)
+	return pbMessage
+}
+
+// ToPBReactions converts reaction counts to their protobuf form
+func ToPBReactions(counts []*model.ReactionCount) []*pb.ReactionCount {
+	if len(counts) == 0 {
+		return nil
 	}
+	result := make([]*pb.ReactionCount, len(counts))
+	for i, count := range counts {
+		result[i] = &pb.ReactionCount{
+			Emoji: count.Emoji,
+			Count: count.Count,
+		}
+	}
+	return result
+}
 
+// toPBAttachments converts attachment metadata to the descriptors pushed to clients
+func toPBAttachments(attachments []*model.Attachment) []*pb.Attachment {
+	if len(attachments) == 0 {
+		return nil
+	}
+	result := make([]*pb.Attachment, len(attachments))
+	for i, attachment := range attachments {
+		result[i] = &pb.Attachment{
+			Id:          attachment.ID,
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+		}
+	}
+	return result
+}
+
+// publishEvent routes an event to the guild channel or, for direct messages, to the participants
+func publishEvent(ctx context.Context, redisClient redis.RedisClient, router EventRouter, dmRepo repository.IDMRepository, pbMessage *pb.WSMessage) error {
+	if pbMessage.ConversationId != "" {
+		return publishToConversation(ctx, router, dmRepo, pbMessage)
+	}
+	return publishToGuild(ctx, redisClient, pbMessage)
+}
+
+// publishToConversation pushes a direct message event to the gateway nodes holding the participants' sessions,
+// whatever guild they are connected to
+func publishToConversation(ctx context.Context, router EventRouter, dmRepo repository.IDMRepository, pbMessage *pb.WSMessage) error {
+	recipientIDs, err := dmRepo.GetParticipantIDs(ctx, pbMessage.ConversationId)
+	if err != nil {
+		return fmt.Errorf("failed to get conversation participants: %w", err)
+	}
+
+	if err := router.PushToUsers(ctx, recipientIDs, pbMessage); err != nil {
+		return fmt.Errorf("failed to push to conversation %s: %w", pbMessage.ConversationId, err)
+	}
+	return nil
+}
+
+// publishToGuild serializes an event with Protobuf and publishes it to the guild-specific channel
+func publishToGuild(ctx context.Context, redisClient redis.RedisClient, pbMessage *pb.WSMessage) error {
 	// Serialize to bytes
 	data, err := proto.Marshal(pbMessage)
 	if err != nil {
@@ -277,10 +1025,18 @@ func (s *MessageService) publishMessage(ctx context.Context, message *model.Mess
 	}
 
 	// Publish to guild-specific channel
-	channel := fmt.Sprintf("guild:%s", message.GuildID)
-	if err := s.redisClient.Publish(ctx, channel, data); err != nil {
+	channel := fmt.Sprintf("guild:%s", pbMessage.GuildId)
+	if err := redisClient.Publish(ctx, channel, data); err != nil {
 		return fmt.Errorf("failed to publish to channel %s: %w", channel, err)
 	}
 
 	return nil
 }
+
+// publishToUser pushes an event to the gateway nodes holding the user's sessions
+func publishToUser(ctx context.Context, router EventRouter, userID string, pbMessage *pb.WSMessage) error {
+	if err := router.PushToUsers(ctx, []string{userID}, pbMessage); err != nil {
+		return fmt.Errorf("failed to push to user %s: %w", userID, err)
+	}
+	return nil
+}
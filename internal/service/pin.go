@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Gopher0727/ChatRoom/internal/model"
+	pb "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
+	"github.com/Gopher0727/ChatRoom/internal/pkg/redis"
+	"github.com/Gopher0727/ChatRoom/internal/repository"
+)
+
+// maxPinsPerGuild caps how many messages a single guild can pin
+const maxPinsPerGuild = 50
+
+var (
+	ErrPinLimitReached = errors.New("guild has reached the maximum number of pinned messages")
+	ErrCannotPin       = errors.New("only guild messages can be pinned")
+)
+
+// IPinService defines the interface for pinned message operations
+type IPinService interface {
+	PinMessage(ctx context.Context, userID, messageID string) error
+	UnpinMessage(ctx context.Context, userID, messageID string) error
+	GetPinnedMessages(ctx context.Context, userID, guildID string) ([]*MessageWithUser, error)
+}
+
+// PinService implements the IPinService interface
+type PinService struct {
+	pinRepo        repository.IPinRepository
+	messageRepo    repository.IMessageRepository
+	guildRepo      repository.IGuildRepository
+	userRepo       repository.IUserRepository
+	reactionRepo   repository.IReactionRepository
+	attachmentRepo repository.IAttachmentRepository
+	guildService   IGuildService
+	redisClient    redis.RedisClient
+}
+
+// NewPinService creates a new IPinService instance
+func NewPinService(
+	pinRepo repository.IPinRepository,
+	messageRepo repository.IMessageRepository,
+	guildRepo repository.IGuildRepository,
+	userRepo repository.IUserRepository,
+	reactionRepo repository.IReactionRepository,
+	attachmentRepo repository.IAttachmentRepository,
+	guildService IGuildService,
+	redisClient redis.RedisClient,
+) IPinService {
+	return &PinService{
+		pinRepo:        pinRepo,
+		messageRepo:    messageRepo,
+		guildRepo:      guildRepo,
+		userRepo:       userRepo,
+		reactionRepo:   reactionRepo,
+		attachmentRepo: attachmentRepo,
+		guildService:   guildService,
+		redisClient:    redisClient,
+	}
+}
+
+// PinMessage pins a message in its guild and announces it with a SYSTEM message
+// Only the guild owner can pin, pinning an already pinned message is a no-op
+func (s *PinService) PinMessage(ctx context.Context, userID, messageID string) error {
+	message, err := s.findPinnableMessage(ctx, userID, messageID)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.pinRepo.CountByGuild(ctx, message.GuildID)
+	if err != nil {
+		return fmt.Errorf("failed to count pinned messages: %w", err)
+	}
+	if count >= maxPinsPerGuild {
+		return ErrPinLimitReached
+	}
+
+	pinned, err := s.pinRepo.Add(ctx, &model.Pin{
+		GuildID:   message.GuildID,
+		MessageID: message.ID,
+		PinnedBy:  userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to pin message: %w", err)
+	}
+	if !pinned {
+		return nil
+	}
+
+	username := "Unknown"
+	if user, err := s.userRepo.FindByID(ctx, userID); err == nil {
+		username = user.UserName
+	}
+
+	// Announce the pin to the guild, the message ID lets clients jump to it
+	announcement := &pb.WSMessage{
+		MessageId: message.ID,
+		UserId:    userID,
+		GuildId:   message.GuildID,
+		ChannelId: message.ChannelID,
+		Content:   fmt.Sprintf("%s pinned a message", username),
+		Timestamp: time.Now().UnixMilli(),
+		Type:      pb.MessageType_SYSTEM,
+		Username:  username,
+	}
+	if err := publishToGuild(ctx, s.redisClient, announcement); err != nil {
+		fmt.Printf("WARNING: failed to publish pin announcement to Redis Pub/Sub: %v\n", err)
+	}
+
+	return nil
+}
+
+// UnpinMessage removes a message from its guild's pins
+// Only the guild owner can unpin, unpinning a message that is not pinned is a no-op
+func (s *PinService) UnpinMessage(ctx context.Context, userID, messageID string) error {
+	message, err := s.findPinnableMessage(ctx, userID, messageID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.pinRepo.Remove(ctx, message.GuildID, message.ID); err != nil {
+		return fmt.Errorf("failed to unpin message: %w", err)
+	}
+
+	return nil
+}
+
+// GetPinnedMessages retrieves the pinned messages of a guild, most recently pinned first
+func (s *PinService) GetPinnedMessages(ctx context.Context, userID, guildID string) ([]*MessageWithUser, error) {
+	isMember, err := s.guildService.IsMember(ctx, userID, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check guild membership: %w", err)
+	}
+	if !isMember {
+		return nil, ErrUserNotInGuild
+	}
+
+	messages, err := s.pinRepo.FindMessagesByGuild(ctx, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve pinned messages: %w", err)
+	}
+	if len(messages) == 0 {
+		return []*MessageWithUser{}, nil
+	}
+
+	return attachUsers(ctx, s.userRepo, s.reactionRepo, s.attachmentRepo, messages), nil
+}
+
+// findPinnableMessage loads a guild message and checks that the user owns its guild
+func (s *PinService) findPinnableMessage(ctx context.Context, userID, messageID string) (*model.Message, error) {
+	message, err := s.messageRepo.FindByID(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, fmt.Errorf("failed to find message: %w", err)
+	}
+	if message.GuildID == "" {
+		return nil, ErrCannotPin
+	}
+
+	if err := checkGuildOwner(ctx, s.guildRepo, userID, message.GuildID); err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
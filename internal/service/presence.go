@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"github.com/Gopher0727/ChatRoom/internal/model"
+	pb "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
+	"github.com/Gopher0727/ChatRoom/internal/pkg/redis"
+	"github.com/Gopher0727/ChatRoom/internal/repository"
+)
+
+// Presence statuses, invisible is only ever chosen by the user and shown to others as offline
+const (
+	StatusOnline    = "online"
+	StatusIdle      = "idle"
+	StatusDND       = "dnd"
+	StatusInvisible = "invisible"
+	StatusOffline   = "offline"
+)
+
+// maxCustomStatusLength caps the custom status text in characters
+const maxCustomStatusLength = 128
+
+var (
+	ErrUserNotFound        = errors.New("user not found")
+	ErrInvalidStatus       = errors.New("status must be one of online, idle, dnd or invisible")
+	ErrCustomStatusTooLong = errors.New("custom status exceeds the maximum length of 128 characters")
+)
+
+// Presence is the status of a user as seen by other users
+type Presence struct {
+	UserID       string `json:"user_id"`
+	Status       string `json:"status"`
+	CustomStatus string `json:"custom_status,omitempty"`
+}
+
+// IPresenceService defines the interface for presence operations
+type IPresenceService interface {
+	Connected(ctx context.Context, userID string) error
+	Disconnected(ctx context.Context, userID string) error
+	Refresh(ctx context.Context, userIDs []string) error
+	SetStatus(ctx context.Context, userID, status, customStatus string) error
+	GetPresence(ctx context.Context, userID string) (*Presence, error)
+}
+
+// PresenceService implements the IPresenceService interface
+// The chosen status is stored with the user, the visible presence is kept in Redis
+// and every change is pushed to the guilds the user belongs to
+// The Redis presence expires after ttl unless the gateway holding the user refreshes it,
+// so that users of a crashed gateway node do not stay online
+type PresenceService struct {
+	userRepo    repository.IUserRepository
+	guildRepo   repository.IGuildRepository
+	redisClient redis.RedisClient
+	ttl         time.Duration
+}
+
+// NewPresenceService creates a new IPresenceService instance
+// ttl must exceed the interval at which gateways call Refresh
+func NewPresenceService(userRepo repository.IUserRepository, guildRepo repository.IGuildRepository, redisClient redis.RedisClient, ttl time.Duration) IPresenceService {
+	return &PresenceService{
+		userRepo:    userRepo,
+		guildRepo:   guildRepo,
+		redisClient: redisClient,
+		ttl:         ttl,
+	}
+}
+
+// Connected switches the user to their chosen status when a gateway connection is established
+func (s *PresenceService) Connected(ctx context.Context, userID string) error {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.update(ctx, user, visiblePresence(user.ID, user.PreferredStatus, user.CustomStatus, true))
+}
+
+// Disconnected switches the user to offline when their gateway connection is gone
+func (s *PresenceService) Disconnected(ctx context.Context, userID string) error {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.update(ctx, user, visiblePresence(user.ID, user.PreferredStatus, user.CustomStatus, false))
+}
+
+// Refresh keeps the presence of connected users from expiring, gateways call it on every heartbeat check
+func (s *PresenceService) Refresh(ctx context.Context, userIDs []string) error {
+	return s.redisClient.RefreshPresence(ctx, userIDs, s.ttl)
+}
+
+// SetStatus stores the status chosen by the user and their custom status text
+// The change is only visible to others while the user is connected
+func (s *PresenceService) SetStatus(ctx context.Context, userID, status, customStatus string) error {
+	switch status {
+	case StatusOnline, StatusIdle, StatusDND, StatusInvisible:
+	default:
+		return ErrInvalidStatus
+	}
+	if utf8.RuneCountInString(customStatus) > maxCustomStatusLength {
+		return ErrCustomStatusTooLong
+	}
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdatePreferredStatus(ctx, userID, status, customStatus); err != nil {
+		return fmt.Errorf("failed to save status: %w", err)
+	}
+
+	online, err := s.redisClient.IsUserOnline(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check online status: %w", err)
+	}
+	return s.update(ctx, user, visiblePresence(user.ID, status, customStatus, online))
+}
+
+// GetPresence retrieves the presence of a user as seen by other users
+func (s *PresenceService) GetPresence(ctx context.Context, userID string) (*Presence, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	online, err := s.redisClient.IsUserOnline(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check online status: %w", err)
+	}
+	return visiblePresence(user.ID, user.PreferredStatus, user.CustomStatus, online), nil
+}
+
+// update stores the visible presence of a user and notifies the members of their guilds when it changed
+func (s *PresenceService) update(ctx context.Context, user *model.User, presence *Presence) error {
+	previous, err := s.redisClient.GetPresence(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if previous["status"] == presence.Status && previous["custom_status"] == presence.CustomStatus {
+		return nil
+	}
+
+	err = s.redisClient.SetPresence(ctx, user.ID, map[string]string{
+		"status":        presence.Status,
+		"custom_status": presence.CustomStatus,
+		"updated_at":    strconv.FormatInt(time.Now().UnixMilli(), 10),
+	}, s.ttl)
+	if err != nil {
+		return err
+	}
+
+	if user.Status != presence.Status {
+		if err := s.userRepo.UpdateStatus(ctx, user.ID, presence.Status); err != nil {
+			// Log error but continue, Redis holds the live presence
+			fmt.Printf("WARNING: failed to save status of user %s: %v\n", user.ID, err)
+		}
+	}
+
+	if err := s.publishPresence(ctx, user, presence); err != nil {
+		// Log error but don't fail the update
+		fmt.Printf("WARNING: failed to publish presence: %v\n", err)
+	}
+	return nil
+}
+
+// publishPresence pushes a presence change to every guild the user belongs to
+func (s *PresenceService) publishPresence(ctx context.Context, user *model.User, presence *Presence) error {
+	guilds, err := s.guildRepo.GetMemberGuilds(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user guilds: %w", err)
+	}
+
+	timestamp := time.Now().UnixMilli()
+	for _, guild := range guilds {
+		pbMessage := &pb.WSMessage{
+			UserId:       user.ID,
+			GuildId:      guild.ID,
+			Username:     user.UserName,
+			Timestamp:    timestamp,
+			Type:         pb.MessageType_PRESENCE_UPDATE,
+			Status:       presence.Status,
+			CustomStatus: presence.CustomStatus,
+		}
+		if err := publishToGuild(ctx, s.redisClient, pbMessage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findUser loads a user and maps a missing record to ErrUserNotFound
+func (s *PresenceService) findUser(ctx context.Context, userID string) (*model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	return user, nil
+}
+
+// visiblePresence derives what other users see from the chosen status and the connection state
+func visiblePresence(userID, preferredStatus, customStatus string, online bool) *Presence {
+	if !online || preferredStatus == StatusInvisible {
+		return &Presence{UserID: userID, Status: StatusOffline}
+	}
+	if preferredStatus == "" {
+		preferredStatus = StatusOnline
+	}
+	return &Presence{UserID: userID, Status: preferredStatus, CustomStatus: customStatus}
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"github.com/Gopher0727/ChatRoom/internal/model"
+	pb "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
+	"github.com/Gopher0727/ChatRoom/internal/pkg/redis"
+	"github.com/Gopher0727/ChatRoom/internal/repository"
+)
+
+// maxEmojiLength bounds the emoji column, which also holds custom emoji names
+const maxEmojiLength = 64
+
+var (
+	ErrInvalidEmoji = errors.New("invalid emoji")
+)
+
+// IReactionService defines the interface for message reaction operations
+type IReactionService interface {
+	AddReaction(ctx context.Context, userID, messageID, emoji string) error
+	RemoveReaction(ctx context.Context, userID, messageID, emoji string) error
+}
+
+// ReactionService implements the IReactionService interface
+type ReactionService struct {
+	reactionRepo repository.IReactionRepository
+	messageRepo  repository.IMessageRepository
+	dmRepo       repository.IDMRepository
+	guildService IGuildService
+	redisClient  redis.RedisClient
+	router       EventRouter
+}
+
+// NewReactionService creates a new ReactionService instance
+func NewReactionService(
+	reactionRepo repository.IReactionRepository,
+	messageRepo repository.IMessageRepository,
+	dmRepo repository.IDMRepository,
+	guildService IGuildService,
+	redisClient redis.RedisClient,
+	router EventRouter,
+) IReactionService {
+	return &ReactionService{
+		reactionRepo: reactionRepo,
+		messageRepo:  messageRepo,
+		dmRepo:       dmRepo,
+		guildService: guildService,
+		redisClient:  redisClient,
+		router:       router,
+	}
+}
+
+// AddReaction adds the user's reaction to a message
+// Reacting twice with the same emoji is a no-op and is not broadcast again
+func (s *ReactionService) AddReaction(ctx context.Context, userID, messageID, emoji string) error {
+	message, err := s.findReactableMessage(ctx, userID, messageID, emoji)
+	if err != nil {
+		return err
+	}
+
+	added, err := s.reactionRepo.Add(ctx, &model.Reaction{
+		MessageID: message.ID,
+		UserID:    userID,
+		Emoji:     emoji,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add reaction: %w", err)
+	}
+	if !added {
+		return nil
+	}
+
+	if err := s.publishReaction(ctx, message, userID, emoji, pb.MessageType_REACTION_ADD); err != nil {
+		fmt.Printf("WARNING: failed to publish reaction to Redis Pub/Sub: %v\n", err)
+	}
+
+	return nil
+}
+
+// RemoveReaction removes the user's reaction from a message
+// Removing a reaction that does not exist is a no-op and is not broadcast
+func (s *ReactionService) RemoveReaction(ctx context.Context, userID, messageID, emoji string) error {
+	message, err := s.findReactableMessage(ctx, userID, messageID, emoji)
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.reactionRepo.Remove(ctx, message.ID, userID, emoji)
+	if err != nil {
+		return fmt.Errorf("failed to remove reaction: %w", err)
+	}
+	if !removed {
+		return nil
+	}
+
+	if err := s.publishReaction(ctx, message, userID, emoji, pb.MessageType_REACTION_REMOVE); err != nil {
+		fmt.Printf("WARNING: failed to publish reaction removal to Redis Pub/Sub: %v\n", err)
+	}
+
+	return nil
+}
+
+// findReactableMessage validates the emoji and checks that the user can see the message
+func (s *ReactionService) findReactableMessage(ctx context.Context, userID, messageID, emoji string) (*model.Message, error) {
+	if !validEmoji(emoji) {
+		return nil, ErrInvalidEmoji
+	}
+
+	message, err := s.messageRepo.FindByID(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, fmt.Errorf("failed to find message: %w", err)
+	}
+
+	if err := checkMessageAccess(ctx, s.guildService, s.dmRepo, userID, message); err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+// publishReaction pushes a reaction change to the message's guild or conversation
+func (s *ReactionService) publishReaction(ctx context.Context, message *model.Message, userID, emoji string, msgType pb.MessageType) error {
+	return publishEvent(ctx, s.redisClient, s.router, s.dmRepo, &pb.WSMessage{
+		MessageId:      message.ID,
+		UserId:         userID,
+		GuildId:        message.GuildID,
+		ChannelId:      message.ChannelID,
+		ConversationId: message.ConversationID,
+		Emoji:          emoji,
+		Timestamp:      time.Now().UnixMilli(),
+		Type:           msgType,
+	})
+}
+
+// validEmoji reports whether emoji is a non-empty, bounded string without whitespace
+func validEmoji(emoji string) bool {
+	if emoji == "" || len(emoji) > maxEmojiLength {
+		return false
+	}
+	return !strings.ContainsFunc(emoji, unicode.IsSpace)
+}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Gopher0727/ChatRoom/internal/model"
+	pb "github.com/Gopher0727/ChatRoom/internal/pkg/proto"
+	"github.com/Gopher0727/ChatRoom/internal/pkg/redis"
+	"github.com/Gopher0727/ChatRoom/internal/repository"
+)
+
+// readStateFlushBatch caps how many users are flushed to PostgreSQL per round
+const readStateFlushBatch = 100
+
+var ErrInvalidReadPosition = errors.New("read position must be a positive seq id")
+
+// GuildWithReadState is a guild of the user with its unread and unread mention counts
+type GuildWithReadState struct {
+	*model.Guild
+	UnreadCount  int64 `json:"unread_count"`
+	MentionCount int64 `json:"mention_count"`
+}
+
+// IReadStateService defines the interface for read receipt operations
+type IReadStateService interface {
+	Ack(ctx context.Context, userID, guildID, channelID string, seqID int64) error
+	GetUserGuilds(ctx context.Context, userID string) ([]*GuildWithReadState, error)
+	FlushReadStates(ctx context.Context) error
+	StartFlusher(ctx context.Context, interval time.Duration) <-chan struct{}
+}
+
+// ReadStateService implements the IReadStateService interface
+// Live read positions are kept in Redis and flushed to PostgreSQL periodically,
+// reads take the larger of both so that positions survive a Redis restart
+type ReadStateService struct {
+	readStateRepo repository.IReadStateRepository
+	mentionRepo   repository.IMentionRepository
+	channelRepo   repository.IChannelRepository
+	guildService  IGuildService
+	redisClient   redis.RedisClient
+	router        EventRouter
+}
+
+// NewReadStateService creates a new IReadStateService instance
+func NewReadStateService(
+	readStateRepo repository.IReadStateRepository,
+	mentionRepo repository.IMentionRepository,
+	channelRepo repository.IChannelRepository,
+	guildService IGuildService,
+	redisClient redis.RedisClient,
+	router EventRouter,
+) IReadStateService {
+	return &ReadStateService{
+		readStateRepo: readStateRepo,
+		mentionRepo:   mentionRepo,
+		channelRepo:   channelRepo,
+		guildService:  guildService,
+		redisClient:   redisClient,
+		router:        router,
+	}
+}
+
+// Ack moves the user's read position on a guild timeline forward
+// An empty channel ID refers to the guild default timeline, positions past the latest message are clamped.
+// When the position changes, the user's other sessions are notified
+func (s *ReadStateService) Ack(ctx context.Context, userID, guildID, channelID string, seqID int64) error {
+	if seqID <= 0 {
+		return ErrInvalidReadPosition
+	}
+
+	isMember, err := s.guildService.IsMember(ctx, userID, guildID)
+	if err != nil {
+		return fmt.Errorf("failed to check guild membership: %w", err)
+	}
+	if !isMember {
+		return ErrUserNotInGuild
+	}
+
+	var latest map[string]int64
+	latestKey := guildID
+	if channelID == "" {
+		latest, err = s.redisClient.GetGuildSeqIDs(ctx, []string{guildID})
+	} else {
+		if err := s.checkChannel(ctx, guildID, channelID); err != nil {
+			return err
+		}
+		latestKey = channelID
+		latest, err = s.redisClient.GetChannelSeqIDs(ctx, []string{channelID})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get latest seq id: %w", err)
+	}
+
+	seqID = min(seqID, latest[latestKey])
+	if seqID == 0 {
+		// Nothing has been posted yet
+		return nil
+	}
+
+	advanced, err := s.redisClient.AdvanceReadState(ctx, userID, readStateTimeline(guildID, channelID), seqID)
+	if err != nil {
+		return fmt.Errorf("failed to save read state: %w", err)
+	}
+	if !advanced {
+		return nil
+	}
+
+	if err := s.publishReadState(ctx, userID, guildID, channelID, seqID); err != nil {
+		// Log error but don't fail the request
+		fmt.Printf("WARNING: failed to publish read state: %v\n", err)
+	}
+	return nil
+}
+
+// GetUserGuilds retrieves the user's guilds with unread message and mention counts
+// Counting failures are logged and leave the counts at zero, the guild list is still returned
+func (s *ReadStateService) GetUserGuilds(ctx context.Context, userID string) ([]*GuildWithReadState, error) {
+	guilds, err := s.guildService.GetUserGuilds(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*GuildWithReadState, len(guilds))
+	for i, guild := range guilds {
+		result[i] = &GuildWithReadState{Guild: guild}
+	}
+	if len(guilds) == 0 {
+		return result, nil
+	}
+
+	if err := s.countUnread(ctx, userID, result); err != nil {
+		fmt.Printf("WARNING: failed to count unread messages for user %s: %v\n", userID, err)
+	}
+	return result, nil
+}
+
+// countUnread fills in the unread counts of the given guilds
+func (s *ReadStateService) countUnread(ctx context.Context, userID string, guilds []*GuildWithReadState) error {
+	guildIDs := make([]string, len(guilds))
+	for i, guild := range guilds {
+		guildIDs[i] = guild.ID
+	}
+
+	channels, err := s.channelRepo.FindByGuildIDs(ctx, guildIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get channels: %w", err)
+	}
+	channelIDs := make([]string, len(channels))
+	for i, channel := range channels {
+		channelIDs[i] = channel.ID
+	}
+
+	guildSeqs, err := s.redisClient.GetGuildSeqIDs(ctx, guildIDs)
+	if err != nil {
+		return err
+	}
+	channelSeqs, err := s.redisClient.GetChannelSeqIDs(ctx, channelIDs)
+	if err != nil {
+		return err
+	}
+
+	readStates, err := s.loadReadStates(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	unread := func(timeline string, latest int64) int64 {
+		return max(latest-readStates[timeline], 0)
+	}
+
+	byGuild := make(map[string]*GuildWithReadState, len(guilds))
+	for _, guild := range guilds {
+		guild.UnreadCount = unread(readStateTimeline(guild.ID, ""), guildSeqs[guild.ID])
+		byGuild[guild.ID] = guild
+	}
+	for _, channel := range channels {
+		if guild, ok := byGuild[channel.GuildID]; ok {
+			guild.UnreadCount += unread(readStateTimeline(channel.GuildID, channel.ID), channelSeqs[channel.ID])
+		}
+	}
+
+	// The stored positions used by the query can lag behind, filter again with the live ones
+	positions, err := s.mentionRepo.FindUnreadPositions(ctx, userID, guildIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get unread mentions: %w", err)
+	}
+	for _, position := range positions {
+		guild, ok := byGuild[position.GuildID]
+		if !ok || position.SeqID <= readStates[readStateTimeline(position.GuildID, position.ChannelID)] {
+			continue
+		}
+		guild.MentionCount++
+	}
+
+	return nil
+}
+
+// loadReadStates merges the stored and the live read positions of a user, keyed by timeline
+func (s *ReadStateService) loadReadStates(ctx context.Context, userID string) (map[string]int64, error) {
+	stored, err := s.readStateRepo.FindByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get read states: %w", err)
+	}
+
+	readStates, err := s.redisClient.GetReadStates(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, state := range stored {
+		timeline := readStateTimeline(state.GuildID, state.ChannelID)
+		readStates[timeline] = max(readStates[timeline], state.LastReadSeqID)
+	}
+	return readStates, nil
+}
+
+// FlushReadStates writes the read positions changed since the last flush to PostgreSQL
+func (s *ReadStateService) FlushReadStates(ctx context.Context) error {
+	for {
+		userIDs, err := s.redisClient.PopDirtyReadStates(ctx, readStateFlushBatch)
+		if err != nil {
+			return err
+		}
+		if len(userIDs) == 0 {
+			return nil
+		}
+
+		if err := s.flushUsers(ctx, userIDs); err != nil {
+			// Keep the users dirty so that the next round retries them
+			if markErr := s.redisClient.MarkReadStatesDirty(ctx, userIDs...); markErr != nil {
+				fmt.Printf("WARNING: failed to requeue read states: %v\n", markErr)
+			}
+			return err
+		}
+
+		if len(userIDs) < readStateFlushBatch {
+			return nil
+		}
+	}
+}
+
+// flushUsers copies the live read positions of the given users to PostgreSQL
+func (s *ReadStateService) flushUsers(ctx context.Context, userIDs []string) error {
+	now := time.Now()
+	var states []*model.ReadState
+	for _, userID := range userIDs {
+		readStates, err := s.redisClient.GetReadStates(ctx, userID)
+		if err != nil {
+			return err
+		}
+		for timeline, seqID := range readStates {
+			guildID, channelID, _ := strings.Cut(timeline, ":")
+			states = append(states, &model.ReadState{
+				UserID:        userID,
+				GuildID:       guildID,
+				ChannelID:     channelID,
+				LastReadSeqID: seqID,
+				UpdatedAt:     now,
+			})
+		}
+	}
+
+	if err := s.readStateRepo.Upsert(ctx, states); err != nil {
+		return fmt.Errorf("failed to save read states: %w", err)
+	}
+	return nil
+}
+
+// StartFlusher flushes read positions every interval until ctx is done, then flushes one last time
+// The returned channel is closed once the last flush is done, Redis must stay open until then
+func (s *ReadStateService) StartFlusher(ctx context.Context, interval time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				if err := s.FlushReadStates(flushCtx); err != nil {
+					fmt.Printf("WARNING: failed to flush read states: %v\n", err)
+				}
+				cancel()
+				return
+			case <-ticker.C:
+				if err := s.FlushReadStates(ctx); err != nil {
+					fmt.Printf("WARNING: failed to flush read states: %v\n", err)
+				}
+			}
+		}
+	}()
+	return done
+}
+
+// checkChannel verifies that a channel belongs to the guild
+func (s *ReadStateService) checkChannel(ctx context.Context, guildID, channelID string) error {
+	channel, err := s.channelRepo.FindByID(ctx, channelID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrChannelNotFound
+		}
+		return fmt.Errorf("failed to find channel: %w", err)
+	}
+	if channel.GuildID != guildID {
+		return ErrChannelNotFound
+	}
+	return nil
+}
+
+// publishReadState pushes a read position change to every session of the user
+func (s *ReadStateService) publishReadState(ctx context.Context, userID, guildID, channelID string, seqID int64) error {
+	pbMessage := &pb.WSMessage{
+		UserId:    userID,
+		GuildId:   guildID,
+		ChannelId: channelID,
+		SeqId:     seqID,
+		Timestamp: time.Now().UnixMilli(),
+		Type:      pb.MessageType_READ_STATE,
+	}
+	return publishToUser(ctx, s.router, userID, pbMessage)
+}
+
+// readStateTimeline is the key of a read position, an empty channel ID is the guild default timeline
+func readStateTimeline(guildID, channelID string) string {
+	return guildID + ":" + channelID
+}
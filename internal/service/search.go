@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/Gopher0727/ChatRoom/internal/model"
+	"github.com/Gopher0727/ChatRoom/internal/repository"
+)
+
+// SearchMessagesRequest represents a full-text search within a guild
+type SearchMessagesRequest struct {
+	UserID  string `form:"-"`
+	GuildID string `form:"-"`
+
+	Query     string `form:"q" binding:"required,max=200"`
+	ChannelID string `form:"channel_id"`
+	AuthorID  string `form:"author_id"`
+	// After and Before bound the message creation time in Unix milliseconds, 0 means unbounded
+	After         int64 `form:"after"`
+	Before        int64 `form:"before"`
+	HasAttachment bool  `form:"has_attachment"`
+
+	Offset int `form:"offset"`
+	Limit  int `form:"limit"`
+}
+
+// SearchResult is a message matching a search with its relevance and highlighted snippet
+type SearchResult struct {
+	*MessageWithUser
+	Rank float64 `json:"rank"`
+	// Snippet is HTML-escaped, matched terms are wrapped in <mark> tags
+	Snippet string `json:"snippet"`
+}
+
+// SearchPage is a page of search results ordered by relevance
+type SearchPage struct {
+	Results    []*SearchResult `json:"results"`
+	HasMore    bool            `json:"has_more"`
+	NextOffset int             `json:"next_offset"`
+}
+
+// SearchMessages runs a ranked full-text search over the messages of a guild the user belongs to
+func (s *MessageService) SearchMessages(ctx context.Context, req *SearchMessagesRequest) (*SearchPage, error) {
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return nil, ErrInvalidSearchQuery
+	}
+
+	isMember, err := s.guildService.IsMember(ctx, req.UserID, req.GuildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check guild membership: %w", err)
+	}
+	if !isMember {
+		return nil, ErrUserNotInGuild
+	}
+
+	if err := s.checkChannel(ctx, req.GuildID, req.ChannelID); err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 25
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset := max(req.Offset, 0)
+
+	filter := &repository.MessageSearchFilter{
+		GuildID:       req.GuildID,
+		ChannelID:     req.ChannelID,
+		Query:         query,
+		AuthorID:      req.AuthorID,
+		HasAttachment: req.HasAttachment,
+		Offset:        offset,
+		Limit:         limit + 1,
+	}
+	if req.After > 0 {
+		after := time.UnixMilli(req.After)
+		filter.After = &after
+	}
+	if req.Before > 0 {
+		before := time.UnixMilli(req.Before)
+		filter.Before = &before
+	}
+
+	hits, err := s.messageRepo.Search(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search messages: %w", err)
+	}
+
+	hasMore := len(hits) > limit
+	if hasMore {
+		hits = hits[:limit]
+	}
+	if len(hits) == 0 {
+		return &SearchPage{Results: []*SearchResult{}, NextOffset: offset}, nil
+	}
+
+	messages := make([]*model.Message, len(hits))
+	for i, hit := range hits {
+		messages[i] = &hit.Message
+	}
+	withUsers := s.withUsers(ctx, messages)
+
+	results := make([]*SearchResult, len(hits))
+	for i, hit := range hits {
+		results[i] = &SearchResult{
+			MessageWithUser: withUsers[i],
+			Rank:            hit.Rank,
+			Snippet:         highlightSnippet(hit.Snippet),
+		}
+	}
+
+	return &SearchPage{
+		Results:    results,
+		HasMore:    hasMore,
+		NextOffset: offset + len(results),
+	}, nil
+}
+
+// highlightSnippet escapes a search snippet and turns the \x02 / \x03 term markers into <mark> tags
+func highlightSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	return strings.NewReplacer("\x02", "<mark>", "\x03", "</mark>").Replace(escaped)
+}
@@ -0,0 +1,49 @@
+package mention
+
+import (
+	"regexp"
+	"slices"
+)
+
+var (
+	// userPattern 匹配 <@user_id> 形式的用户提及
+	userPattern = regexp.MustCompile(`<@([A-Za-z0-9_-]+)>`)
+
+	// broadcastPattern 匹配独立出现的 @everyone / @here，避免误匹配邮箱等内容
+	broadcastPattern = regexp.MustCompile(`(?:^|[^\w@])@(everyone|here)\b`)
+)
+
+// Result 消息内容中解析出的提及
+type Result struct {
+	UserIDs  []string // 按首次出现顺序去重后的用户 ID
+	Everyone bool     // 是否包含 @everyone
+	Here     bool     // 是否包含 @here
+}
+
+// Empty 判断是否没有任何提及
+func (r Result) Empty() bool {
+	return len(r.UserIDs) == 0 && !r.Everyone && !r.Here
+}
+
+// Parse 解析消息内容中的 <@user_id>、@everyone 和 @here
+// 只做语法解析，用户是否存在、是否可见由调用方决定
+func Parse(content string) Result {
+	var result Result
+
+	for _, match := range userPattern.FindAllStringSubmatch(content, -1) {
+		if !slices.Contains(result.UserIDs, match[1]) {
+			result.UserIDs = append(result.UserIDs, match[1])
+		}
+	}
+
+	for _, match := range broadcastPattern.FindAllStringSubmatch(content, -1) {
+		switch match[1] {
+		case "everyone":
+			result.Everyone = true
+		case "here":
+			result.Here = true
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,83 @@
+package mention
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		userIDs  []string
+		everyone bool
+		here     bool
+	}{
+		{
+			name:    "no mentions",
+			content: "hello world",
+		},
+		{
+			name:    "single user",
+			content: "hi <@user_1>, welcome",
+			userIDs: []string{"user_1"},
+		},
+		{
+			name:    "multiple users are deduplicated in order",
+			content: "<@b> <@a> <@b>",
+			userIDs: []string{"b", "a"},
+		},
+		{
+			name:    "uuid user id",
+			content: "ping <@3f2b8c1e-7d4a-4b9e-9c1a-2e5f6a7b8c9d>",
+			userIDs: []string{"3f2b8c1e-7d4a-4b9e-9c1a-2e5f6a7b8c9d"},
+		},
+		{
+			name:     "everyone",
+			content:  "@everyone meeting at 5",
+			everyone: true,
+		},
+		{
+			name:    "here inside sentence",
+			content: "anyone @here?",
+			here:    true,
+		},
+		{
+			name:     "everyone, here and users",
+			content:  "@here @everyone <@u1>",
+			userIDs:  []string{"u1"},
+			everyone: true,
+			here:     true,
+		},
+		{
+			name:    "email address is not a mention",
+			content: "mail me at admin@everyone.com or ops@here.io",
+		},
+		{
+			name:    "longer words are not mentions",
+			content: "@everyones @hereafter",
+		},
+		{
+			name:    "malformed user mention",
+			content: "<@> <@ user> <@user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Parse(tt.content)
+			if !slices.Equal(result.UserIDs, tt.userIDs) {
+				t.Errorf("UserIDs = %v, want %v", result.UserIDs, tt.userIDs)
+			}
+			if result.Everyone != tt.everyone {
+				t.Errorf("Everyone = %v, want %v", result.Everyone, tt.everyone)
+			}
+			if result.Here != tt.here {
+				t.Errorf("Here = %v, want %v", result.Here, tt.here)
+			}
+			if result.Empty() != (len(tt.userIDs) == 0 && !tt.everyone && !tt.here) {
+				t.Errorf("Empty = %v for %+v", result.Empty(), result)
+			}
+		})
+	}
+}